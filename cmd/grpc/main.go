@@ -1,25 +1,64 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"log"
+	"log/slog"
+	"os"
+	"time"
 
 	"go-gin-project/config"
 	"go-gin-project/grpc/server"
 	"go-gin-project/internal/pkg/cache"
+	"go-gin-project/internal/pkg/model"
 )
 
 func main() {
-	if err := config.InitDB(); err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
+	cfg, err := config.Load(flag.CommandLine, os.Args[1:], "db", "auth")
+	if err != nil {
+		log.Fatal(err)
+	}
+	secretManager, err := cfg.ResolveSecrets(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to read secrets: %v", err)
+	}
+	if cfg.Secrets.RefreshInterval > 0 {
+		go secretManager.Run(context.Background(), cfg.Secrets.RefreshInterval)
 	}
 
-	cacheService, err := cache.New()
+	db, err := cfg.OpenDB()
 	if err != nil {
-		log.Printf("Warning: Failed to initialize Redis: %v", err)
-		log.Println("Application will continue without caching")
+		log.Fatalf("Failed to initialize database: %v", err)
 	}
+	defer config.CloseDB(db) //nolint:errcheck
+
+	// Redis, degrading to an in-process LRU while it is unreachable.
+	redisOpts := cfg.RedisOptions()
+	connect := func() (model.CacheService, error) { return cache.New(redisOpts) }
+	cacheService := cache.NewLoading(
+		cache.NewFallback(connect, cache.NewMemory(10000, 64<<20), 10*time.Second),
+		cache.LoadingOptions{Codec: redisOpts.Codec},
+	)
 
-	if err := server.StartGrpcServer(cacheService); err != nil {
+	// Rate limits shared through Redis, counted per instance while it is unreachable.
+	var limiter model.RateLimiter = cache.NewMemoryRateLimiter()
+	if redisLimiter, err := cache.NewRateLimiter(redisOpts); err != nil {
+		slog.Warn("Redis rate limiter unavailable, limiting per instance", "err", err)
+	} else {
+		limiter = cache.NewFallbackRateLimiter(redisLimiter, limiter, 10*time.Second)
+	}
+
+	// Rate limits and the cache TTL reload on SIGHUP or config file changes.
+	live := config.NewLive(cfg)
+	go live.Watch(context.Background())
+	opts := server.Options{
+		Port:         cfg.GRPC.Port,
+		RateLimit:    live.RateLimit(func(c *config.Config) string { return c.RateLimit.GRPC }),
+		UserCacheTTL: func() time.Duration { return live.Config().Cache.UserTTL },
+		JWTSecret:    cfg.Secret("auth.jwt_secret").Get,
+	}
+	if err := server.StartGrpcServer(db, cacheService, limiter, opts); err != nil {
 		log.Fatalf("Failed to start gRPC server: %v", err)
 	}
 }
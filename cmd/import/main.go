@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"go-gin-project/config"
+	"go-gin-project/internal/app/service"
+	"go-gin-project/internal/pkg/repository"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+// run does the import and returns the exit code, so that its deferred
+// cleanups (closing the database) happen before main exits.
+func run() int {
+	file := flag.String("file", "", "path to a CSV or JSONL file of users")
+	format := flag.String("format", "", "csv or jsonl; defaults to the file extension")
+	dryRun := flag.Bool("dry-run", false, "validate rows without inserting")
+	batchSize := flag.Int("batch", 500, "rows per insert transaction")
+	orgID := flag.Uint("org", 0, "organization the imported users join")
+	cfg, err := config.Load(flag.CommandLine, os.Args[1:], "db")
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+	if _, err := cfg.ResolveSecrets(context.Background()); err != nil {
+		log.Printf("Failed to read secrets: %v", err)
+		return 1
+	}
+
+	if *file == "" {
+		log.Print("-file is required")
+		return 1
+	}
+	if *format == "" {
+		*format = strings.TrimPrefix(filepath.Ext(*file), ".")
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		log.Printf("Failed to open import file: %v", err)
+		return 1
+	}
+	defer f.Close()
+
+	db, err := cfg.OpenDB()
+	if err != nil {
+		log.Printf("Failed to initialize database: %v", err)
+		return 1
+	}
+	defer config.CloseDB(db) //nolint:errcheck
+
+	importService := service.NewImportService(repository.NewUserRepository(db))
+	// Ctrl-C cancels the in-flight batch instead of leaving it to finish.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := importService.Import(ctx, f, service.ImportOptions{
+		Format:    *format,
+		DryRun:    *dryRun,
+		BatchSize: *batchSize,
+		OrgID:     *orgID,
+	})
+	if err != nil {
+		log.Printf("Import failed: %v", err)
+		return 1
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		log.Printf("Failed to write report: %v", err)
+		return 1
+	}
+	log.Printf("Imported %d/%d rows (%d failed, dry run: %t)", report.Succeeded, report.Total, report.Failed, report.DryRun)
+	if report.Failed > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,134 @@
+// Command migrate applies the versioned schema migrations.
+//
+//	migrate [config flags] up [n]     apply all pending migrations, or the next n
+//	migrate [config flags] down [n]   revert the last migration, or the last n
+//	migrate [config flags] status     list migrations and when they were applied
+//	migrate create <name>             add empty up/down files for a new migration,
+//	                                  one pair per database driver
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"go-gin-project/config"
+	"go-gin-project/internal/pkg/migrate"
+	"go-gin-project/internal/pkg/repository/migrations"
+)
+
+// migrationsDir is where create writes, relative to the repository root;
+// each driver has a subdirectory.
+const migrationsDir = "internal/pkg/repository/migrations"
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "create" {
+		if len(os.Args) != 3 {
+			log.Fatal("usage: migrate create <name>")
+		}
+		now := time.Now()
+		for _, dialect := range migrations.Dialects {
+			up, down, err := migrate.Create(filepath.Join(migrationsDir, dialect), os.Args[2], now)
+			if err != nil {
+				log.Fatal(err)
+			}
+			fmt.Println(up)
+			fmt.Println(down)
+		}
+		return
+	}
+
+	cfg, err := config.Load(flag.CommandLine, os.Args[1:], "db")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if _, err := cfg.ResolveSecrets(context.Background()); err != nil {
+		log.Fatalf("Failed to read secrets: %v", err)
+	}
+	command, steps := flag.Arg(0), 0
+	if flag.NArg() > 1 {
+		if steps, err = strconv.Atoi(flag.Arg(1)); err != nil || steps < 1 {
+			log.Fatalf("%s: step count must be a positive integer, got %q", command, flag.Arg(1))
+		}
+	}
+
+	// Opening the database would otherwise apply the migrations itself
+	// (DB_MIGRATE) or AutoMigrate (DB_AUTO_MIGRATE); here it only checks.
+	// Replicas are left out so every statement runs on the primary.
+	cfg.DB.Migrate = false
+	cfg.DB.AutoMigrate = false
+	cfg.DB.Replicas = nil
+	db, err := cfg.OpenDB()
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer config.CloseDB(db) //nolint:errcheck
+
+	source, err := migrations.For(db.Dialector.Name())
+	if err != nil {
+		log.Fatal(err)
+	}
+	migrator, err := migrate.New(db, source)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	switch command {
+	case "up":
+		// A database created by AutoMigrate is marked as being at the
+		// baseline first, and then takes the later migrations like any other.
+		adopted, err := migrator.Adopt(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(adopted) > 0 {
+			report("Adopted", adopted)
+		}
+		done, err := migrator.Up(ctx, steps)
+		report("Applied", done)
+		if err != nil {
+			log.Fatal(err)
+		}
+	case "down":
+		done, err := migrator.Down(ctx, steps)
+		report("Reverted", done)
+		if err != nil {
+			log.Fatal(err)
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Name, applied)
+		}
+		w.Flush()
+	default:
+		log.Fatalf("unknown command %q; want up, down, status or create", command)
+	}
+}
+
+func report(verb string, done []migrate.Migration) {
+	if len(done) == 0 {
+		log.Printf("%s no migrations", verb)
+	}
+	for _, m := range done {
+		log.Printf("%s %d_%s", verb, m.Version, m.Name)
+	}
+}
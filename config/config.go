@@ -2,83 +2,337 @@ package config
 
 import (
 	"fmt"
-	"log"
-	"os"
+	"net"
+	"strings"
+	"time"
 
-	"go-gin-project/internal/pkg/repository"
-
-	"github.com/joho/godotenv"
-	"gorm.io/driver/mysql"
-	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
+	"go-gin-project/internal/pkg/blobstore"
+	"go-gin-project/internal/pkg/cache"
+	"go-gin-project/internal/pkg/model"
+	"go-gin-project/internal/pkg/secrets"
 )
 
-var DB *gorm.DB
+// Config is the complete application configuration; see Load for how it is
+// populated. Sections map to top-level keys of the config file.
+type Config struct {
+	// Env names the deployment, e.g. "production"; it prefixes Redis keys.
+	Env string `key:"env" env:"ENV"`
 
-func InitDB() error {
-	// Load environment variables
-	err := godotenv.Load()
-	if err != nil {
-		log.Printf("Warning: .env file not found: %v", err)
-	}
+	Log struct {
+		// Level is "debug", "info", "warn" or "error"; SQL statements are
+		// logged at debug.
+		Level string `key:"level" env:"LOG_LEVEL" default:"info" reload:"true"`
+	} `key:"log"`
 
-	// Get database configuration from environment
-	dbUser := os.Getenv("DB_USER")
-	dbPass := os.Getenv("DB_PASSWORD")
-	dbHost := os.Getenv("DB_HOST")
-	dbName := os.Getenv("DB_NAME")
-	dbPort := os.Getenv("DB_PORT")
+	HTTP struct {
+		Port int `key:"port" env:"HTTP_PORT" default:"8080"`
+		// CORSOrigins lists browser origins allowed to call the API; "*"
+		// allows any, empty disables CORS.
+		CORSOrigins []string `key:"cors_origins" env:"CORS_ORIGINS" reload:"true"`
+		// TrustedProxies lists the IPs or CIDRs of reverse proxies whose
+		// X-Forwarded-For header gives the client IP; empty trusts none.
+		TrustedProxies []string `key:"trusted_proxies" env:"TRUSTED_PROXIES"`
+	} `key:"http"`
 
-	// Construct database connection string
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		dbUser, dbPass, dbHost, dbPort, dbName)
+	GRPC struct {
+		Port int `key:"port" env:"GRPC_PORT" default:"50051"`
+	} `key:"grpc"`
 
-	// Configure GORM logger
-	gormConfig := &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
-	}
+	DB struct {
+		// Driver is "mysql", "postgres" or "sqlite". For SQLite, Name is the
+		// database file and the connection settings are unused.
+		Driver   string `key:"driver" env:"DB_DRIVER" default:"mysql"`
+		User     string `key:"user" env:"DB_USER" required:"unless db.driver=sqlite"`
+		Password string `key:"password" env:"DB_PASSWORD" secret:"true"`
+		Host     string `key:"host" env:"DB_HOST" required:"unless db.driver=sqlite"`
+		// Port defaults to the driver's standard port.
+		Port int    `key:"port" env:"DB_PORT"`
+		Name string `key:"name" env:"DB_NAME" required:"true"`
+		// SSLMode is the PostgreSQL sslmode, e.g. "disable" or "verify-full".
+		SSLMode string `key:"sslmode" env:"DB_SSLMODE" default:"prefer"`
+		// Migrate makes OpenDB apply pending versioned migrations, holding
+		// the migration lock; when false it only warns about them.
+		Migrate bool `key:"migrate" env:"DB_MIGRATE" default:"true"`
+		// AutoMigrate makes OpenDB run GORM AutoMigrate instead of the
+		// versioned migrations; for development.
+		AutoMigrate bool `key:"auto_migrate" env:"DB_AUTO_MIGRATE"`
+		// Replicas are read replicas as host or host:port, reached with the
+		// primary's user, password and database name.
+		Replicas []string `key:"replicas" env:"DB_REPLICAS"`
+		// Pool settings apply to the primary and to each replica; zero
+		// means unlimited.
+		MaxOpenConns    int           `key:"max_open_conns" env:"DB_MAX_OPEN_CONNS" default:"25"`
+		MaxIdleConns    int           `key:"max_idle_conns" env:"DB_MAX_IDLE_CONNS" default:"10"`
+		ConnMaxLifetime time.Duration `key:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME" default:"30m"`
+		ConnMaxIdleTime time.Duration `key:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME" default:"5m"`
+	} `key:"db"`
 
-	// Open database connection
-	db, err := gorm.Open(mysql.Open(dsn), gormConfig)
-	if err != nil {
-		return fmt.Errorf("failed to connect to database: %w", err)
-	}
+	Cache struct {
+		// Driver is "redis", "memory" or "none".
+		Driver            string `key:"driver" env:"CACHE_DRIVER" default:"redis"`
+		Codec             string `key:"codec" env:"CACHE_CODEC" default:"json"`
+		Compression       string `key:"compression" env:"CACHE_COMPRESSION" default:"none"`
+		CompressThreshold int    `key:"compress_threshold" env:"CACHE_COMPRESS_THRESHOLD" default:"1024"`
+		// UserTTL and PaymentTTL are how long users and payments are cached.
+		UserTTL    time.Duration `key:"user_ttl" env:"CACHE_USER_TTL" default:"5m" reload:"true"`
+		PaymentTTL time.Duration `key:"payment_ttl" env:"CACHE_PAYMENT_TTL" default:"5m" reload:"true"`
+	} `key:"cache"`
+
+	Redis struct {
+		// Mode is "standalone", "sentinel" or "cluster".
+		Mode string `key:"mode" env:"REDIS_MODE" default:"standalone"`
+		// Addrs lists sentinel or cluster seed nodes; empty means Host:Port.
+		Addrs                 []string      `key:"addrs" env:"REDIS_ADDRS"`
+		Host                  string        `key:"host" env:"REDIS_HOST" default:"localhost"`
+		Port                  int           `key:"port" env:"REDIS_PORT" default:"6379"`
+		MasterName            string        `key:"master_name" env:"REDIS_MASTER_NAME"`
+		Username              string        `key:"username" env:"REDIS_USERNAME"`
+		Password              string        `key:"password" env:"REDIS_PASSWORD" secret:"true"`
+		SentinelPassword      string        `key:"sentinel_password" env:"REDIS_SENTINEL_PASSWORD" secret:"true"`
+		DB                    int           `key:"db" env:"REDIS_DB"`
+		TLS                   bool          `key:"tls" env:"REDIS_TLS"`
+		TLSCAFile             string        `key:"tls_ca_file" env:"REDIS_TLS_CA_FILE"`
+		TLSServerName         string        `key:"tls_server_name" env:"REDIS_TLS_SERVER_NAME"`
+		TLSInsecureSkipVerify bool          `key:"tls_insecure_skip_verify" env:"REDIS_TLS_INSECURE_SKIP_VERIFY"`
+		PoolSize              int           `key:"pool_size" env:"REDIS_POOL_SIZE"`
+		MinIdleConns          int           `key:"min_idle_conns" env:"REDIS_MIN_IDLE_CONNS"`
+		DialTimeout           time.Duration `key:"dial_timeout" env:"REDIS_DIAL_TIMEOUT" default:"5s"`
+		ReadTimeout           time.Duration `key:"read_timeout" env:"REDIS_READ_TIMEOUT" default:"3s"`
+		WriteTimeout          time.Duration `key:"write_timeout" env:"REDIS_WRITE_TIMEOUT" default:"3s"`
+		PoolTimeout           time.Duration `key:"pool_timeout" env:"REDIS_POOL_TIMEOUT"`
+		// KeyPrefix defaults to "<env>:" when Env is set.
+		KeyPrefix string `key:"key_prefix" env:"REDIS_KEY_PREFIX"`
+	} `key:"redis"`
+
+	Auth struct {
+		JWTSecret string `key:"jwt_secret" env:"JWT_SECRET" required:"true" secret:"true"`
+		// Operators lists the emails of users who may read the /debug routes.
+		Operators []string `key:"operators" env:"AUTH_OPERATORS" reload:"true"`
+	} `key:"auth"`
+
+	// Stripe is optional; payment routes fail without it.
+	Stripe struct {
+		SecretKey string `key:"secret_key" env:"STRIPE_SECRET_KEY" secret:"true"`
+	} `key:"stripe"`
+
+	Blob struct {
+		// Store is "local" or "s3".
+		Store         string `key:"store" env:"BLOB_STORE" default:"local"`
+		LocalDir      string `key:"local_dir" env:"BLOB_LOCAL_DIR" default:"./data/blobs"`
+		SigningKey    string `key:"signing_key" env:"BLOB_SIGNING_KEY" secret:"true"`
+		PublicBaseURL string `key:"public_base_url" env:"PUBLIC_BASE_URL" default:"http://localhost:8080"`
+		S3            struct {
+			Endpoint  string `key:"endpoint" env:"S3_ENDPOINT"`
+			Bucket    string `key:"bucket" env:"S3_BUCKET"`
+			Region    string `key:"region" env:"S3_REGION"`
+			AccessKey string `key:"access_key" env:"S3_ACCESS_KEY"`
+			SecretKey string `key:"secret_key" env:"S3_SECRET_KEY" secret:"true"`
+		} `key:"s3"`
+	} `key:"blob"`
+
+	// RateLimit holds "<rate>/<period>[/<burst>]" specs, or "off".
+	RateLimit struct {
+		Auth     string `key:"auth" env:"RATE_LIMIT_AUTH" default:"10/1m" reload:"true"`
+		API      string `key:"api" env:"RATE_LIMIT_API" default:"600/1m" reload:"true"`
+		Payments string `key:"payments" env:"RATE_LIMIT_PAYMENTS" default:"30/1m" reload:"true"`
+		GRPC     string `key:"grpc" env:"RATE_LIMIT_GRPC" default:"600/1m" reload:"true"`
+	} `key:"rate_limit"`
 
-	DB = db
+	// Secrets configures where "vault:" secret references are read from and
+	// how often references are re-read; see ResolveSecrets.
+	Secrets struct {
+		RefreshInterval time.Duration `key:"refresh_interval" env:"SECRETS_REFRESH_INTERVAL" default:"5m"`
+		Vault           struct {
+			Addr      string `key:"addr" env:"VAULT_ADDR"`
+			Token     string `key:"token" env:"VAULT_TOKEN" secret:"true"`
+			Namespace string `key:"namespace" env:"VAULT_NAMESPACE"`
+			Mount     string `key:"mount" env:"VAULT_KV_MOUNT" default:"secret"`
+			KVVersion int    `key:"kv_version" env:"VAULT_KV_VERSION" default:"2"`
+		} `key:"vault"`
+	} `key:"secrets"`
 
-	// Auto Migrate the models with error handling
-	if err := migrateModels(DB); err != nil {
-		return fmt.Errorf("failed to migrate database: %w", err)
+	// Events configures publishing of the domain events repositories record
+	// in the outbox.
+	Events struct {
+		// Broker is "redis", for Redis Streams on the redis section's server,
+		// or "none", which leaves events in the outbox until one is set.
+		Broker string `key:"broker" env:"EVENTS_BROKER" default:"redis"`
+		// StreamMaxLen trims each stream to about this many entries; 0 keeps all.
+		StreamMaxLen int           `key:"stream_max_len" env:"EVENTS_STREAM_MAX_LEN" default:"100000"`
+		PollInterval time.Duration `key:"poll_interval" env:"EVENTS_POLL_INTERVAL" default:"1s"`
+		BatchSize    int           `key:"batch_size" env:"EVENTS_BATCH_SIZE" default:"100"`
+		// MaxAttempts failed publishes dead-letter an event. Retries wait
+		// RetryBackoff, doubling per failure up to MaxBackoff.
+		MaxAttempts  int           `key:"max_attempts" env:"EVENTS_MAX_ATTEMPTS" default:"20"`
+		RetryBackoff time.Duration `key:"retry_backoff" env:"EVENTS_RETRY_BACKOFF" default:"1s"`
+		MaxBackoff   time.Duration `key:"max_backoff" env:"EVENTS_MAX_BACKOFF" default:"5m"`
+	} `key:"events"`
+
+	// resolved holds secrets that ResolveSecrets read, by key path.
+	resolved map[string]*secrets.Value
+	// file is the config file Load read, if any; reload re-runs Load with
+	// the same file, flags and sections.
+	file   string
+	reload func() (*Config, error)
+}
+
+// validate checks values that have a fixed set of forms.
+func (c *Config) validate() []string {
+	var problems []string
+	oneOf := func(path, value string, allowed ...string) {
+		for _, a := range allowed {
+			if value == a {
+				return
+			}
+		}
+		problems = append(problems, fmt.Sprintf("%s must be one of %v, got %q", path, allowed, value))
+	}
+	port := func(path string, p int) {
+		if p < 1 || p > 65535 {
+			problems = append(problems, fmt.Sprintf("%s must be between 1 and 65535, got %d", path, p))
+		}
 	}
 
-	log.Println("Database connection and migrations successful")
-	return nil
+	oneOf("log.level", c.Log.Level, "debug", "info", "warn", "error")
+	port("http.port", c.HTTP.Port)
+	for _, p := range c.HTTP.TrustedProxies {
+		if _, _, err := net.ParseCIDR(p); err != nil && net.ParseIP(p) == nil {
+			problems = append(problems, fmt.Sprintf("http.trusted_proxies: %q is not an IP or CIDR", p))
+		}
+	}
+	port("grpc.port", c.GRPC.Port)
+	oneOf("db.driver", c.DB.Driver, "mysql", "postgres", "sqlite")
+	if c.DB.Port != 0 {
+		port("db.port", c.DB.Port)
+	}
+	if c.DB.Driver == "sqlite" && len(c.DB.Replicas) > 0 {
+		problems = append(problems, "db.replicas is not supported with sqlite")
+	}
+	if c.DB.MaxOpenConns < 0 || c.DB.MaxIdleConns < 0 || c.DB.ConnMaxLifetime < 0 || c.DB.ConnMaxIdleTime < 0 {
+		problems = append(problems, "db.max_open_conns, db.max_idle_conns, db.conn_max_lifetime and db.conn_max_idle_time must not be negative")
+	}
+	oneOf("cache.driver", c.Cache.Driver, "redis", "memory", "none")
+	oneOf("cache.codec", c.Cache.Codec, "json", "msgpack", "protobuf")
+	oneOf("cache.compression", c.Cache.Compression, "none", "zstd", "snappy")
+	if c.Cache.UserTTL <= 0 || c.Cache.PaymentTTL <= 0 {
+		problems = append(problems, "cache.user_ttl and cache.payment_ttl must be positive")
+	}
+	oneOf("redis.mode", c.Redis.Mode, cache.RedisStandalone, cache.RedisSentinel, cache.RedisCluster)
+	if c.Redis.Mode == cache.RedisSentinel && c.Redis.MasterName == "" {
+		problems = append(problems, "redis.master_name is required in sentinel mode; set redis.master_name, $REDIS_MASTER_NAME or -redis-master-name")
+	}
+	if c.Redis.Mode == cache.RedisCluster && c.Redis.DB != 0 {
+		problems = append(problems, "redis.db must be 0 in cluster mode")
+	}
+	oneOf("blob.store", c.Blob.Store, "local", "s3")
+	if c.Blob.Store == "s3" {
+		for path, v := range map[string]string{
+			"blob.s3.endpoint": c.Blob.S3.Endpoint, "blob.s3.bucket": c.Blob.S3.Bucket,
+			"blob.s3.access_key": c.Blob.S3.AccessKey, "blob.s3.secret_key": c.Blob.S3.SecretKey,
+		} {
+			if v == "" {
+				problems = append(problems, path+" is required when blob.store is s3")
+			}
+		}
+	}
+	for path, spec := range map[string]string{
+		"rate_limit.auth": c.RateLimit.Auth, "rate_limit.api": c.RateLimit.API,
+		"rate_limit.payments": c.RateLimit.Payments, "rate_limit.grpc": c.RateLimit.GRPC,
+	} {
+		if _, err := ParseRateLimit(spec); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", path, err))
+		}
+	}
+	oneOf("events.broker", c.Events.Broker, "redis", "none")
+	if c.Events.StreamMaxLen < 0 {
+		problems = append(problems, "events.stream_max_len must not be negative")
+	}
+	if c.Events.PollInterval <= 0 || c.Events.BatchSize <= 0 || c.Events.MaxAttempts <= 0 || c.Events.RetryBackoff <= 0 || c.Events.MaxBackoff <= 0 {
+		problems = append(problems, "events.poll_interval, events.batch_size, events.max_attempts, events.retry_backoff and events.max_backoff must be positive")
+	}
+	if c.Secrets.RefreshInterval < 0 {
+		problems = append(problems, "secrets.refresh_interval must not be negative")
+	}
+	oneOf("secrets.vault.kv_version", fmt.Sprint(c.Secrets.Vault.KVVersion), "1", "2")
+	if c.Secrets.Vault.Addr == "" {
+		for _, f := range secretFields(c) {
+			if strings.HasPrefix(f.value.String(), "vault:") {
+				problems = append(problems, f.path+" refers to Vault but secrets.vault.addr is not set")
+			}
+		}
+	}
+	return problems
 }
 
-// migrateModels handles the auto-migration of database models
-func migrateModels(db *gorm.DB) error {
-	if err := repository.Migrate(db); err != nil {
-		return fmt.Errorf("failed to migrate database: %w", err)
+// ParseRateLimit parses a rate_limit spec; "off" yields nil.
+func ParseRateLimit(spec string) (*model.RateLimit, error) {
+	if spec == "off" {
+		return nil, nil
+	}
+	limit, err := model.ParseRateLimit(spec)
+	if err != nil {
+		return nil, err
 	}
-	log.Println("Database migrations successful")
-	return nil
+	return &limit, nil
 }
 
-// GetDB returns the database instance
-func GetDB() *gorm.DB {
-	return DB
+// RedisOptions converts the redis and cache sections for the cache package.
+func (c *Config) RedisOptions() cache.RedisOptions {
+	addrs := c.Redis.Addrs
+	if len(addrs) == 0 {
+		addrs = []string{fmt.Sprintf("%s:%d", c.Redis.Host, c.Redis.Port)}
+	}
+	prefix := c.Redis.KeyPrefix
+	if prefix == "" && c.Env != "" {
+		prefix = c.Env + ":"
+	}
+	// Read on every new connection, so a rotated password is picked up
+	// without a restart.
+	password := c.Secret("redis.password")
+	// Both were checked by validate.
+	codec, _ := cache.CodecByName(c.Cache.Codec)
+	compression, _ := cache.CompressionByName(c.Cache.Compression)
+	return cache.RedisOptions{
+		Mode:                  c.Redis.Mode,
+		Addrs:                 addrs,
+		MasterName:            c.Redis.MasterName,
+		Username:              c.Redis.Username,
+		Password:              password.Get(),
+		SentinelPassword:      c.Redis.SentinelPassword,
+		DB:                    c.Redis.DB,
+		TLS:                   c.Redis.TLS,
+		TLSCAFile:             c.Redis.TLSCAFile,
+		TLSServerName:         c.Redis.TLSServerName,
+		TLSInsecureSkipVerify: c.Redis.TLSInsecureSkipVerify,
+		PoolSize:              c.Redis.PoolSize,
+		MinIdleConns:          c.Redis.MinIdleConns,
+		DialTimeout:           c.Redis.DialTimeout,
+		ReadTimeout:           c.Redis.ReadTimeout,
+		WriteTimeout:          c.Redis.WriteTimeout,
+		PoolTimeout:           c.Redis.PoolTimeout,
+		KeyPrefix:             prefix,
+		Codec:                 codec,
+		Compression:           compression,
+		CompressThreshold:     c.Cache.CompressThreshold,
+		CredentialsProvider: func() (string, string) {
+			return c.Redis.Username, password.Get()
+		},
+	}
 }
 
-// CloseDB closes the database connection
-func CloseDB() error {
-	if DB != nil {
-		sqlDB, err := DB.DB()
-		if err != nil {
-			return fmt.Errorf("failed to get database instance: %w", err)
-		}
-		if err := sqlDB.Close(); err != nil {
-			return fmt.Errorf("failed to close database connection: %w", err)
-		}
+// BlobOptions converts the blob section for the blobstore package.
+func (c *Config) BlobOptions() blobstore.Options {
+	return blobstore.Options{
+		Store:      c.Blob.Store,
+		LocalDir:   c.Blob.LocalDir,
+		BaseURL:    c.Blob.PublicBaseURL + "/blobs",
+		SigningKey: []byte(c.Blob.SigningKey),
+		S3: blobstore.S3Config{
+			Endpoint:  c.Blob.S3.Endpoint,
+			Bucket:    c.Blob.S3.Bucket,
+			Region:    c.Blob.S3.Region,
+			AccessKey: c.Blob.S3.AccessKey,
+			SecretKey: c.Blob.S3.SecretKey,
+		},
 	}
-	return nil
 }
@@ -0,0 +1,301 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+
+	"go-gin-project/internal/pkg/migrate"
+	"go-gin-project/internal/pkg/repository"
+	"go-gin-project/internal/pkg/repository/migrations"
+
+	"github.com/glebarez/sqlite"
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// sqliteBusyTimeout is how long SQLite waits for another connection's write
+// lock before failing with "database is locked".
+const sqliteBusyTimeout = 5 * time.Second
+
+// OpenDB connects to the database described by the db section, applies
+// pending migrations (or only checks for them with db.migrate off), and
+// routes reads to db.replicas if any are set; see repository for which reads
+// stay on the primary.
+func (c *Config) OpenDB() (*gorm.DB, error) {
+	dialector, err := c.dialector(c.DB.Host)
+	if err != nil {
+		return nil, err
+	}
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger:         newSQLLogger(),
+		TranslateError: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	c.configurePool(sqlDB)
+	if c.DB.Driver == "sqlite" {
+		// SQLite allows one writer at a time; a single connection queues
+		// writes instead of failing them with "database is locked".
+		sqlDB.SetMaxOpenConns(1)
+	}
+
+	// Migrations run before replicas are registered, so that all of their
+	// statements use the primary connection holding the migration lock.
+	if err := c.checkMigrations(db); err != nil {
+		CloseDB(db) //nolint:errcheck
+		return nil, err
+	}
+
+	if len(c.DB.Replicas) > 0 {
+		replicas := make([]gorm.Dialector, 0, len(c.DB.Replicas))
+		for _, host := range c.DB.Replicas {
+			d, err := c.dialector(host)
+			if err != nil {
+				CloseDB(db) //nolint:errcheck
+				return nil, err
+			}
+			replicas = append(replicas, d)
+		}
+		resolver := dbresolver.Register(dbresolver.Config{Replicas: replicas, Policy: dbresolver.RandomPolicy{}})
+		if err := db.Use(resolver); err != nil {
+			CloseDB(db) //nolint:errcheck
+			return nil, fmt.Errorf("failed to connect to read replicas: %w", err)
+		}
+		err := resolver.Call(func(pool gorm.ConnPool) error {
+			if p, ok := pool.(*sql.DB); ok {
+				c.configurePool(p)
+			}
+			return nil
+		})
+		if err != nil {
+			CloseDB(db) //nolint:errcheck
+			return nil, fmt.Errorf("failed to configure read replicas: %w", err)
+		}
+		slog.Info("reading from database replicas", "count", len(replicas))
+	}
+	slog.Info("database connection successful")
+	return db, nil
+}
+
+func (c *Config) configurePool(db *sql.DB) {
+	db.SetMaxOpenConns(c.DB.MaxOpenConns)
+	db.SetMaxIdleConns(c.DB.MaxIdleConns)
+	db.SetConnMaxLifetime(c.DB.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(c.DB.ConnMaxIdleTime)
+}
+
+func (c *Config) checkMigrations(db *gorm.DB) error {
+	if c.DB.AutoMigrate {
+		if err := repository.AutoMigrate(db); err != nil {
+			return fmt.Errorf("failed to migrate database: %w", err)
+		}
+		slog.Info("database auto-migration successful")
+		return nil
+	}
+
+	source, err := migrations.For(db.Dialector.Name())
+	if err != nil {
+		return err
+	}
+	migrator, err := migrate.New(db, source)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	if !c.DB.Migrate {
+		pending, err := migrator.Pending(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to check migrations: %w", err)
+		}
+		if len(pending) > 0 {
+			slog.Warn("database migrations are pending; run \"go run ./cmd/migrate up\"", "count", len(pending))
+		}
+		return nil
+	}
+
+	adopted, err := migrator.Adopt(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+	for _, m := range adopted {
+		slog.Info("existing database schema adopted as migrated", "version", m.Version, "name", m.Name)
+	}
+	done, err := migrator.Up(ctx, 0)
+	for _, m := range done {
+		slog.Info("applied database migration", "version", m.Version, "name", m.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+	return nil
+}
+
+// pools calls fn with the primary connection pool and then each replica's.
+func pools(db *gorm.DB, fn func(name string, pool *sql.DB) error) error {
+	primary, err := db.DB()
+	if err != nil {
+		return err
+	}
+	if err := fn("primary", primary); err != nil {
+		return err
+	}
+	resolver, ok := db.Config.Plugins[(&dbresolver.DBResolver{}).Name()].(*dbresolver.DBResolver)
+	if !ok {
+		return nil
+	}
+	n := 0
+	return resolver.Call(func(pool gorm.ConnPool) error {
+		p, ok := pool.(*sql.DB)
+		if !ok || p == primary {
+			return nil
+		}
+		n++
+		return fn(fmt.Sprintf("replica-%d", n), p)
+	})
+}
+
+// DBPoolStats is a snapshot of a database connection pool.
+type DBPoolStats struct {
+	Name              string `json:"name"` // "primary" or "replica-<n>" in db.replicas order
+	MaxOpen           int    `json:"max_open"`
+	Open              int    `json:"open"`
+	InUse             int    `json:"in_use"`
+	Idle              int    `json:"idle"`
+	WaitCount         int64  `json:"wait_count"`           // waits for a free connection
+	WaitDuration      string `json:"wait_duration"`        // total time spent waiting
+	MaxIdleClosed     int64  `json:"max_idle_closed"`      // closed by db.max_idle_conns
+	MaxIdleTimeClosed int64  `json:"max_idle_time_closed"` // closed by db.conn_max_idle_time
+	MaxLifetimeClosed int64  `json:"max_lifetime_closed"`  // closed by db.conn_max_lifetime
+}
+
+// DBStats returns the pool statistics of db's primary and each read replica.
+func DBStats(db *gorm.DB) ([]DBPoolStats, error) {
+	var out []DBPoolStats
+	err := pools(db, func(name string, pool *sql.DB) error {
+		s := pool.Stats()
+		out = append(out, DBPoolStats{
+			Name:              name,
+			MaxOpen:           s.MaxOpenConnections,
+			Open:              s.OpenConnections,
+			InUse:             s.InUse,
+			Idle:              s.Idle,
+			WaitCount:         s.WaitCount,
+			WaitDuration:      s.WaitDuration.String(),
+			MaxIdleClosed:     s.MaxIdleClosed,
+			MaxIdleTimeClosed: s.MaxIdleTimeClosed,
+			MaxLifetimeClosed: s.MaxLifetimeClosed,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("database stats: %w", err)
+	}
+	return out, nil
+}
+
+// CloseDB closes the database connection and those to any replicas.
+func CloseDB(db *gorm.DB) error {
+	var errs []error
+	err := pools(db, func(name string, pool *sql.DB) error {
+		if err := pool.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get database instance: %w", err)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("failed to close database connection: %w", err)
+	}
+	return nil
+}
+
+// dialector returns the GORM dialector for db.driver at host, which is
+// db.host or a replica. New network connections use the current password,
+// so rotating it only needs the old one to stay valid until the next refresh.
+func (c *Config) dialector(host string) (gorm.Dialector, error) {
+	switch c.DB.Driver {
+	case "postgres":
+		return c.postgresDialector(host)
+	case "sqlite":
+		dsn := fmt.Sprintf("%s?_pragma=busy_timeout(%d)&_pragma=foreign_keys(1)", c.DB.Name, sqliteBusyTimeout.Milliseconds())
+		return sqlite.Open(dsn), nil
+	default:
+		return c.mysqlDialector(host)
+	}
+}
+
+// dbAddr adds db.port, or the driver's standard port, to host unless it
+// has one.
+func (c *Config) dbAddr(host string, defaultPort int) string {
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+	port := c.DB.Port
+	if port == 0 {
+		port = defaultPort
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+func (c *Config) mysqlDialector(host string) (gorm.Dialector, error) {
+	password := c.Secret("db.password")
+	dsn := mysqldriver.NewConfig()
+	dsn.User = c.DB.User
+	dsn.Passwd = password.Get()
+	dsn.Net = "tcp"
+	dsn.Addr = c.dbAddr(host, 3306)
+	dsn.DBName = c.DB.Name
+	dsn.ParseTime = true
+	dsn.Loc = time.Local
+	dsn.Params = map[string]string{"charset": "utf8mb4"}
+	if err := dsn.Apply(mysqldriver.BeforeConnect(func(_ context.Context, cfg *mysqldriver.Config) error {
+		cfg.Passwd = password.Get()
+		return nil
+	})); err != nil {
+		return nil, fmt.Errorf("failed to configure database: %w", err)
+	}
+	connector, err := mysqldriver.NewConnector(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure database: %w", err)
+	}
+	return mysql.New(mysql.Config{Conn: sql.OpenDB(connector)}), nil
+}
+
+func (c *Config) postgresDialector(host string) (gorm.Dialector, error) {
+	password := c.Secret("db.password")
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.User(c.DB.User),
+		Host:     c.dbAddr(host, 5432),
+		Path:     "/" + c.DB.Name,
+		RawQuery: url.Values{"sslmode": {c.DB.SSLMode}}.Encode(),
+	}
+	connConfig, err := pgx.ParseConfig(dsn.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure database: %w", err)
+	}
+	connector := stdlib.GetConnector(*connConfig, stdlib.OptionBeforeConnect(func(_ context.Context, cfg *pgx.ConnConfig) error {
+		cfg.Password = password.Get()
+		return nil
+	}))
+	return postgres.New(postgres.Config{Conn: sql.OpenDB(connector)}), nil
+}
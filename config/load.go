@@ -0,0 +1,310 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Fields of Config are described by struct tags:
+//
+//	key      name in the config file; nested structs form sections
+//	env      environment variable overriding the file
+//	default  value used when nothing else sets the field
+//	required the field must end up non-zero when its section is required;
+//	         "unless <path>=<value>" exempts it while another field has value
+//	secret   the value may be a reference resolved by ResolveSecrets, and
+//	         $<env>_FILE names a file holding it (Docker/Kubernetes secrets)
+//	reload   the field can change while the process runs; see Live
+//
+// Every field also gets a flag named after its key path, e.g. -http-port for
+// http.port, which overrides everything else.
+
+// field is a leaf of Config with its dotted key path.
+type field struct {
+	path  string // e.g. "db.user"
+	value reflect.Value
+	tag   reflect.StructTag
+}
+
+func (f field) flagName() string {
+	return strings.ReplaceAll(strings.ReplaceAll(f.path, ".", "-"), "_", "-")
+}
+
+// sources names where a field can be set, for error messages.
+func (f field) sources() string {
+	if env := f.tag.Get("env"); env != "" {
+		return fmt.Sprintf("%s, $%s or -%s", f.path, env, f.flagName())
+	}
+	return fmt.Sprintf("%s or -%s", f.path, f.flagName())
+}
+
+func fields(v reflect.Value, prefix string) []field {
+	var out []field
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		key := sf.Tag.Get("key")
+		if key == "" {
+			continue
+		}
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		if sf.Type.Kind() == reflect.Struct && sf.Type != reflect.TypeOf(time.Duration(0)) {
+			out = append(out, fields(v.Field(i), path)...)
+			continue
+		}
+		out = append(out, field{path: path, value: v.Field(i), tag: sf.Tag})
+	}
+	return out
+}
+
+// Load builds the Config from, in increasing precedence: defaults, the
+// YAML or TOML file named by -config or $CONFIG_FILE, a .env file, the
+// environment, and flags. The flags are registered on fs and args parsed
+// with it, so callers can add their own flags to fs first. Fields tagged
+// required are checked only within the given top-level sections (e.g. "db");
+// every problem found is reported in one error.
+func Load(fs *flag.FlagSet, args []string, sections ...string) (*Config, error) {
+	configFile := fs.String("config", "", "path to a YAML or TOML config file (env CONFIG_FILE)")
+	flagValues := map[string]*flagValue{}
+	for _, f := range fields(reflect.ValueOf(&Config{}).Elem(), "") {
+		usage := "sets " + f.path
+		if env := f.tag.Get("env"); env != "" {
+			usage += " (env " + env + ")"
+		}
+		fv := &flagValue{isBool: f.value.Kind() == reflect.Bool}
+		flagValues[f.flagName()] = fv
+		fs.Var(fv, f.flagName(), usage)
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	path := *configFile
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	var visited []*flag.Flag
+	fs.Visit(func(fl *flag.Flag) { visited = append(visited, fl) })
+
+	var load func() (*Config, error)
+	load = func() (*Config, error) {
+		cfg := &Config{file: path, reload: load}
+		all := fields(reflect.ValueOf(cfg).Elem(), "")
+
+		var problems []string
+		set := func(f field, raw interface{}, source string) {
+			if err := setValue(f.value, raw); err != nil {
+				problems = append(problems, fmt.Sprintf("%s (from %s): %v", f.path, source, err))
+			}
+		}
+
+		for _, f := range all {
+			if def, ok := f.tag.Lookup("default"); ok {
+				set(f, def, "default")
+			}
+		}
+
+		if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+			slog.Warn(".env file not loaded", "err", err)
+		}
+
+		if path != "" {
+			values, err := readFile(path)
+			if err != nil {
+				return nil, err
+			}
+			known := make(map[string]bool, len(all))
+			for _, f := range all {
+				known[f.path] = true
+				if raw, ok := values[f.path]; ok {
+					set(f, raw, path)
+				}
+			}
+			for key := range values {
+				if !known[key] {
+					problems = append(problems, fmt.Sprintf("%s: unknown key in %s", key, path))
+				}
+			}
+		}
+
+		for _, f := range all {
+			env := f.tag.Get("env")
+			if env == "" {
+				continue
+			}
+			raw, ok := os.LookupEnv(env)
+			if ok {
+				set(f, raw, "$"+env)
+			}
+			if _, secret := f.tag.Lookup("secret"); secret {
+				if file, fileSet := os.LookupEnv(env + "_FILE"); fileSet {
+					if ok {
+						problems = append(problems, fmt.Sprintf("%s: set either $%s or $%s_FILE, not both", f.path, env, env))
+					}
+					set(f, "file:"+file, "$"+env+"_FILE")
+				}
+			}
+		}
+
+		for _, fl := range visited {
+			for _, f := range all {
+				if fl.Name == f.flagName() {
+					set(f, flagValues[fl.Name].raw, "-"+fl.Name)
+				}
+			}
+		}
+
+		for _, f := range all {
+			if required(f, all) && inSections(f.path, sections) && f.value.IsZero() {
+				problems = append(problems, fmt.Sprintf("%s is required; set %s", f.path, f.sources()))
+			}
+		}
+		problems = append(problems, cfg.validate()...)
+
+		if len(problems) > 0 {
+			return nil, fmt.Errorf("invalid configuration:\n  %s", strings.Join(problems, "\n  "))
+		}
+		return cfg, nil
+	}
+	return load()
+}
+
+// flagValue holds a flag's raw text until Load knows its precedence; bool
+// fields accept a bare -flag like flag.Bool does.
+type flagValue struct {
+	raw    string
+	isBool bool
+}
+
+func (v *flagValue) String() string     { return v.raw }
+func (v *flagValue) Set(s string) error { v.raw = s; return nil }
+func (v *flagValue) IsBoolFlag() bool   { return v.isBool }
+
+// required reports whether f's required tag applies, given the other fields.
+func required(f field, all []field) bool {
+	tag, ok := f.tag.Lookup("required")
+	if !ok {
+		return false
+	}
+	cond, ok := strings.CutPrefix(tag, "unless ")
+	if !ok {
+		return true
+	}
+	path, value, _ := strings.Cut(cond, "=")
+	for _, other := range all {
+		if other.path == path {
+			return fmt.Sprint(other.value.Interface()) != value
+		}
+	}
+	return true
+}
+
+func inSections(path string, sections []string) bool {
+	section, _, _ := strings.Cut(path, ".")
+	for _, s := range sections {
+		if s == section {
+			return true
+		}
+	}
+	return false
+}
+
+// readFile flattens a YAML or TOML file into dotted keys.
+func readFile(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config file: %w", err)
+	}
+	tree := map[string]interface{}{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &tree)
+	case ".toml":
+		err = toml.Unmarshal(data, &tree)
+	default:
+		return nil, fmt.Errorf("read config file: unsupported extension %q (want .yaml, .yml or .toml)", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	flat := map[string]interface{}{}
+	flatten(tree, "", flat)
+	return flat, nil
+}
+
+func flatten(tree map[string]interface{}, prefix string, out map[string]interface{}) {
+	for k, v := range tree {
+		if prefix != "" {
+			k = prefix + "." + k
+		}
+		if sub, ok := v.(map[string]interface{}); ok {
+			flatten(sub, k, out)
+			continue
+		}
+		out[k] = v
+	}
+}
+
+// setValue assigns raw, a string from env/flags/defaults or a decoded file
+// value, to v.
+func setValue(v reflect.Value, raw interface{}) error {
+	if v.Kind() == reflect.Slice {
+		var items []string
+		switch r := raw.(type) {
+		case []interface{}:
+			for _, item := range r {
+				items = append(items, fmt.Sprint(item))
+			}
+		default:
+			for _, item := range strings.Split(fmt.Sprint(r), ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+		return nil
+	}
+
+	s := fmt.Sprint(raw)
+	switch {
+	case v.Type() == reflect.TypeOf(time.Duration(0)):
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("want a duration such as 500ms, got %q", s)
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(s)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("want true or false, got %q", s)
+		}
+		v.SetBool(b)
+	case v.Kind() == reflect.Int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("want an integer, got %q", s)
+		}
+		v.SetInt(int64(n))
+	default:
+		return fmt.Errorf("unsupported config field type %s", v.Type())
+	}
+	return nil
+}
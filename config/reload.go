@@ -0,0 +1,220 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"go-gin-project/internal/pkg/model"
+
+	"gorm.io/gorm/logger"
+)
+
+// fileWatchInterval is how often Watch checks the config file for changes.
+// Polling also catches Kubernetes ConfigMap updates, which swap a symlink
+// rather than writing the file.
+const fileWatchInterval = 5 * time.Second
+
+// Live is the configuration of a running process. Fields tagged reload
+// (log level, rate limits, cache TTLs, CORS origins) change when Reload
+// succeeds; everything else keeps its startup value until restart. Readers
+// call Config for every use instead of keeping a copy.
+type Live struct {
+	mu      sync.Mutex // serializes reloads
+	current atomic.Pointer[Config]
+}
+
+// NewLive starts from cfg, which must come from Load, and applies its log level.
+func NewLive(cfg *Config) *Live {
+	l := &Live{}
+	l.current.Store(cfg)
+	cfg.applyLogLevel()
+	return l
+}
+
+// Config returns the current configuration. It must not be modified.
+func (l *Live) Config() *Config {
+	return l.current.Load()
+}
+
+// RateLimit returns a func reading the rate_limit spec that spec selects
+// from the current configuration, e.g. for middleware.RateLimit.
+func (l *Live) RateLimit(spec func(*Config) string) func() *model.RateLimit {
+	return func() *model.RateLimit {
+		limit, _ := ParseRateLimit(spec(l.Config())) // checked by Load
+		return limit
+	}
+}
+
+// Reload re-runs Load with the original file, flags and sections. If the
+// result is valid, the reloadable fields that differ are swapped in at once
+// and logged; changes to other fields are logged as needing a restart.
+// Secrets are left to their own refresh. It returns the applied changes as
+// "path: old -> new".
+func (l *Live) Reload() ([]string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cur := l.current.Load()
+	if cur.reload == nil {
+		return nil, errors.New("config reload: configuration was not built by Load")
+	}
+	fresh, err := cur.reload()
+	if err != nil {
+		return nil, err
+	}
+
+	next := *cur
+	freshFields := fields(reflect.ValueOf(fresh).Elem(), "")
+	var applied, restart []string
+	for i, f := range fields(reflect.ValueOf(&next).Elem(), "") {
+		if _, secret := f.tag.Lookup("secret"); secret {
+			continue
+		}
+		value := freshFields[i].value
+		if reflect.DeepEqual(f.value.Interface(), value.Interface()) {
+			continue
+		}
+		if _, ok := f.tag.Lookup("reload"); !ok {
+			restart = append(restart, f.path)
+			continue
+		}
+		applied = append(applied, fmt.Sprintf("%s: %v -> %v", f.path, f.value.Interface(), value.Interface()))
+		f.value.Set(value)
+	}
+
+	if len(restart) > 0 {
+		slog.Warn("config changes need a restart", "settings", strings.Join(restart, ", "))
+	}
+	if len(applied) == 0 {
+		slog.Info("config reloaded: no runtime settings changed")
+		return nil, nil
+	}
+	l.current.Store(&next)
+	next.applyLogLevel()
+	slog.Info("config reloaded", "changes", strings.Join(applied, "; "))
+	return applied, nil
+}
+
+// Watch calls Reload on SIGHUP and whenever the config file changes, until
+// ctx ends. A failed reload keeps the current settings.
+func (l *Live) Watch(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	file := l.Config().file
+	var tick <-chan time.Time
+	last := statFile(file)
+	if file != "" {
+		ticker := time.NewTicker(fileWatchInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		var trigger string
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			trigger = "SIGHUP"
+		case <-tick:
+			state := statFile(file)
+			if state == last {
+				continue
+			}
+			last = state
+			trigger = "change to " + file
+		}
+		if _, err := l.Reload(); err != nil {
+			slog.Warn("config reload failed, keeping current settings", "trigger", trigger, "err", err)
+		}
+	}
+}
+
+// fileState identifies a version of a file well enough to notice edits.
+type fileState struct {
+	modTime int64
+	size    int64
+}
+
+func statFile(path string) fileState {
+	if path == "" {
+		return fileState{}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{modTime: info.ModTime().UnixNano(), size: info.Size()}
+}
+
+// sqlLogLevel is the GORM log level for the current log.level, shared by
+// every database opened with OpenDB. Until a Live applies one, GORM logs
+// warnings and errors.
+var sqlLogLevel atomic.Int32
+
+func (c *Config) applyLogLevel() {
+	level, sql := slog.LevelInfo, logger.Warn
+	switch c.Log.Level {
+	case "debug":
+		level, sql = slog.LevelDebug, logger.Info
+	case "warn":
+		level = slog.LevelWarn
+	case "error":
+		level, sql = slog.LevelError, logger.Error
+	}
+	slog.SetLogLoggerLevel(level)
+	sqlLogLevel.Store(int32(sql))
+}
+
+// sqlLogger is a GORM logger that follows sqlLogLevel.
+type sqlLogger struct {
+	levels map[logger.LogLevel]logger.Interface
+}
+
+func newSQLLogger() logger.Interface {
+	levels := map[logger.LogLevel]logger.Interface{}
+	for _, level := range []logger.LogLevel{logger.Error, logger.Warn, logger.Info} {
+		levels[level] = logger.Default.LogMode(level)
+	}
+	return sqlLogger{levels: levels}
+}
+
+func (l sqlLogger) current() logger.Interface {
+	if lg, ok := l.levels[logger.LogLevel(sqlLogLevel.Load())]; ok {
+		return lg
+	}
+	return l.levels[logger.Warn]
+}
+
+// LogMode pins a level, as db.Debug() does for one session.
+func (l sqlLogger) LogMode(level logger.LogLevel) logger.Interface {
+	return logger.Default.LogMode(level)
+}
+
+func (l sqlLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	l.current().Info(ctx, msg, args...)
+}
+
+func (l sqlLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	l.current().Warn(ctx, msg, args...)
+}
+
+func (l sqlLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	l.current().Error(ctx, msg, args...)
+}
+
+func (l sqlLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	l.current().Trace(ctx, begin, fc, err)
+}
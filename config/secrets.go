@@ -0,0 +1,82 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"go-gin-project/internal/pkg/model"
+	"go-gin-project/internal/pkg/secrets"
+)
+
+// secretFields returns the fields of c tagged secret.
+func secretFields(c *Config) []field {
+	var out []field
+	for _, f := range fields(reflect.ValueOf(c).Elem(), "") {
+		if _, ok := f.tag.Lookup("secret"); ok {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// ResolveSecrets replaces "file:" and "vault:" references in secret fields
+// with the values they point to, and returns a Manager that re-reads them.
+// The Vault token is read once, as it is needed to reach Vault at all.
+// Fields keep the values read now; consumers that can follow a rotation use
+// Secret instead.
+func (c *Config) ResolveSecrets(ctx context.Context) (*secrets.Manager, error) {
+	providers := map[string]model.SecretProvider{}
+	if c.Secrets.Vault.Addr != "" {
+		token, err := secrets.NewManager(nil).Resolve(ctx, "secrets.vault.token", c.Secrets.Vault.Token)
+		if err != nil {
+			return nil, err
+		}
+		c.Secrets.Vault.Token = token.Get()
+		vault, err := secrets.NewVault(secrets.VaultOptions{
+			Addr:      c.Secrets.Vault.Addr,
+			Token:     c.Secrets.Vault.Token,
+			Namespace: c.Secrets.Vault.Namespace,
+			Mount:     c.Secrets.Vault.Mount,
+			KVVersion: c.Secrets.Vault.KVVersion,
+		})
+		if err != nil {
+			return nil, err
+		}
+		providers["vault"] = vault
+	}
+
+	manager := secrets.NewManager(providers)
+	c.resolved = map[string]*secrets.Value{}
+	var errs []error
+	for _, f := range secretFields(c) {
+		if f.path == "secrets.vault.token" || f.value.String() == "" {
+			continue
+		}
+		v, err := manager.Resolve(ctx, f.path, f.value.String())
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		f.value.SetString(v.Get())
+		c.resolved[f.path] = v
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return manager, nil
+}
+
+// Secret returns the secret field at path, e.g. "db.password", following
+// rotations once ResolveSecrets has run.
+func (c *Config) Secret(path string) *secrets.Value {
+	if v, ok := c.resolved[path]; ok {
+		return v
+	}
+	for _, f := range secretFields(c) {
+		if f.path == path {
+			return secrets.Static(f.value.String())
+		}
+	}
+	return secrets.Static("")
+}
@@ -2,12 +2,18 @@ package main
 
 import (
 	"log"
+	"os"
 
 	"go-gin-project/grpc/client"
 )
 
 func main() {
-	userClient, err := client.NewUserClient("localhost:50051")
+	// A token from POST /api/auth/login for an organization member.
+	token := os.Getenv("API_TOKEN")
+	if token == "" {
+		log.Fatal("API_TOKEN is required")
+	}
+	userClient, err := client.NewUserClient("localhost:50051", token)
 	if err != nil {
 		log.Fatalf("Failed to create client: %v", err)
 	}
@@ -16,8 +16,25 @@ type UserClient struct {
 	conn   *grpc.ClientConn
 }
 
-func NewUserClient(address string) (*UserClient, error) {
-	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+// bearerToken sends a JWT from POST /api/auth/login with every call.
+type bearerToken string
+
+func (t bearerToken) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+// RequireTransportSecurity is false because the client connects without TLS.
+func (bearerToken) RequireTransportSecurity() bool {
+	return false
+}
+
+// NewUserClient connects to the gRPC server, authenticating every call with
+// token. Calls act within the organization the token was issued for.
+func NewUserClient(address, token string) (*UserClient, error) {
+	conn, err := grpc.NewClient(address,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithPerRPCCredentials(bearerToken(token)),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect: %v", err)
 	}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"context"
+	"strings"
+
+	"go-gin-project/internal/app/service"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type claimsKey struct{}
+
+// AuthInterceptor requires "authorization: Bearer <token>" metadata on every
+// unary call and checks the token like the HTTP middleware does, so revoked
+// tokens and removed members are refused. Handlers read the claims through
+// callerClaims.
+func AuthInterceptor(authService *service.AuthService) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		values := metadata.ValueFromIncomingContext(ctx, "authorization")
+		if len(values) != 1 {
+			return nil, status.Error(codes.Unauthenticated, "authorization metadata is required")
+		}
+		token, ok := strings.CutPrefix(values[0], "Bearer ")
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "invalid authorization format")
+		}
+		claims, err := authService.ParseToken(ctx, token)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "invalid or expired token")
+		}
+		return handler(context.WithValue(ctx, claimsKey{}, claims), req)
+	}
+}
+
+// callerClaims returns the claims AuthInterceptor validated for this call.
+func callerClaims(ctx context.Context) (*service.Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*service.Claims)
+	return claims, ok
+}
@@ -2,42 +2,61 @@ package server
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"net"
-	"os"
 
 	"go-gin-project/api/proto"
-	"go-gin-project/config"
 	"go-gin-project/internal/app/service"
 	"go-gin-project/internal/pkg/model"
 	"go-gin-project/internal/pkg/repository"
 
 	"google.golang.org/grpc"
+	"gorm.io/gorm"
 )
 
-func StartGrpcServer(cache model.CacheService) error {
-	// Create services using the already initialized DB and cache
-	if config.DB == nil {
+// Options configures the gRPC server.
+type Options struct {
+	Port int
+	// RateLimit returns the current per-caller limit for unary calls; nil, or
+	// a nil result, disables limiting.
+	RateLimit func() *model.RateLimit
+	// UserCacheTTL is how long users are cached.
+	UserCacheTTL service.TTLFunc
+	// JWTSecret returns the key callers' tokens are signed with.
+	JWTSecret func() string
+}
+
+func StartGrpcServer(db *gorm.DB, cache model.LoadingCache, limiter model.RateLimiter, opts Options) error {
+	if db == nil {
 		return fmt.Errorf("database connection is not initialized")
 	}
+	if opts.JWTSecret == nil {
+		return fmt.Errorf("a JWT secret is required to authenticate callers")
+	}
 
 	// Create services
-	userRepo := repository.NewUserRepository(config.DB)
-	userService := service.NewUserService(userRepo, cache)
-	grpcServer := grpc.NewServer()
-	userGrpcService := NewUserGrpcService(userService)
+	userRepo := repository.NewUserRepository(db)
+	orgRepo := repository.NewOrganizationRepository(db)
+	userService := service.NewUserService(userRepo, cache, opts.UserCacheTTL)
+	orgService := service.NewOrganizationService(orgRepo, cache)
+	authService := service.NewAuthService(userRepo, orgRepo, opts.JWTSecret)
+
+	// Rate limiting runs first so unauthenticated floods are limited too.
+	var interceptors []grpc.UnaryServerInterceptor
+	if opts.RateLimit != nil {
+		interceptors = append(interceptors, RateLimitInterceptor(limiter, opts.RateLimit))
+	}
+	interceptors = append(interceptors, AuthInterceptor(authService))
+	grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(interceptors...))
+	userGrpcService := NewUserGrpcService(userService, orgService)
 	proto.RegisterUserServiceServer(grpcServer, userGrpcService)
 
 	// Start listening
-	port := os.Getenv("GRPC_PORT")
-	if port == "" {
-		port = "50051"
-	}
-	lis, err := net.Listen("tcp", ":"+port)
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", opts.Port))
 	if err != nil {
 		return fmt.Errorf("failed to listen: %v", err)
 	}
 
-	log.Printf("gRPC server listening on port %s", port)
+	slog.Info("gRPC server listening", "port", opts.Port)
 	return grpcServer.Serve(lis)
 }
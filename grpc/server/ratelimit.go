@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net"
+	"strconv"
+	"time"
+
+	"go-gin-project/internal/pkg/model"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// RateLimitInterceptor limits unary calls to the limit currently returned by
+// limit per peer IP; a nil limit lets every call through. It mirrors the HTTP
+// middleware: ratelimit-* response headers, ResourceExhausted with a
+// retry-after header once exceeded, and fail-open if the limiter errors.
+func RateLimitInterceptor(limiter model.RateLimiter, limit func() *model.RateLimit) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		current := limit()
+		if current == nil {
+			return handler(ctx, req)
+		}
+		res, err := limiter.Allow(ctx, "grpc:"+rateLimitKey(ctx), *current)
+		if err != nil {
+			slog.Error("rate limit grpc", "err", err)
+			return handler(ctx, req)
+		}
+
+		md := metadata.Pairs(
+			"ratelimit-limit", strconv.Itoa(res.Limit),
+			"ratelimit-remaining", strconv.Itoa(res.Remaining),
+			"ratelimit-reset", ceilSeconds(res.ResetAfter),
+		)
+		if !res.Allowed {
+			md.Set("retry-after", ceilSeconds(res.RetryAfter))
+			grpc.SetHeader(ctx, md) //nolint:errcheck
+			return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+		}
+		grpc.SetHeader(ctx, md) //nolint:errcheck
+		return handler(ctx, req)
+	}
+}
+
+// rateLimitKey identifies the caller by its connection's address; unverified
+// metadata such as an API key would let a caller pick a fresh limit per call.
+func rateLimitKey(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			return "ip:" + host
+		}
+		return "ip:" + p.Addr.String()
+	}
+	return "ip:unknown"
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
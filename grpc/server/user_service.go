@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"errors"
 	"strconv"
 	"time"
 
@@ -13,55 +14,89 @@ import (
 	"google.golang.org/grpc/status"
 )
 
+// UserGrpcService serves the UserService RPCs. Like the HTTP /users routes,
+// every call acts within the organization of the caller's token.
 type UserGrpcService struct {
 	pb.UnimplementedUserServiceServer
 	userService *service.UserService
+	orgService  *service.OrganizationService
 }
 
-func NewUserGrpcService(userService *service.UserService) *UserGrpcService {
+func NewUserGrpcService(userService *service.UserService, orgService *service.OrganizationService) *UserGrpcService {
 	return &UserGrpcService{
 		userService: userService,
+		orgService:  orgService,
 	}
 }
 
+// tenant returns the caller's claims and the user service scoped to their
+// organization. Tokens without an organization are refused.
+func (s *UserGrpcService) tenant(ctx context.Context) (*service.Claims, *service.UserService, error) {
+	claims, ok := callerClaims(ctx)
+	if !ok {
+		return nil, nil, status.Error(codes.Unauthenticated, "authentication is required")
+	}
+	if claims.OrgID == 0 {
+		return nil, nil, status.Error(codes.PermissionDenied, "organization membership is required")
+	}
+	return claims, s.userService.ForTenant(claims.OrgID), nil
+}
+
 func (s *UserGrpcService) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.UserResponse, error) {
+	_, users, err := s.tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
 	user := &model.User{
 		Email:    req.Email,
 		Password: req.Password,
 		Name:     req.Name,
 	}
 
-	createdUser, err := s.userService.Create(user)
+	createdUser, err := users.Create(ctx, user)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to create user: %v", err)
 	}
 
-	return &pb.UserResponse{
-		Id:        strconv.FormatUint(uint64(createdUser.ID), 10),
-		Email:     createdUser.Email,
-		Name:      createdUser.Name,
-		CreatedAt: createdUser.CreatedAt.Format(time.RFC3339),
-		UpdatedAt: createdUser.UpdatedAt.Format(time.RFC3339),
-	}, nil
+	return toUserResponse(createdUser), nil
 }
 
 func (s *UserGrpcService) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.UserResponse, error) {
-	user, err := s.userService.Get(req.Id)
+	_, users, err := s.tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	user, err := users.Get(ctx, req.Id)
 	if err != nil {
 		return nil, status.Errorf(codes.NotFound, "user not found: %v", err)
 	}
 
-	return &pb.UserResponse{
-		Id:        strconv.FormatUint(uint64(user.ID), 10),
-		Email:     user.Email,
-		Name:      user.Name,
-		CreatedAt: user.CreatedAt.Format(time.RFC3339),
-		UpdatedAt: user.UpdatedAt.Format(time.RFC3339),
-	}, nil
+	return toUserResponse(user), nil
 }
 
+// UpdateUser is open to the user themself and to admins of their organization.
 func (s *UserGrpcService) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.UserResponse, error) {
-	updates := &model.User{}
+	claims, users, err := s.tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.Id != strconv.FormatUint(uint64(claims.UserID), 10) && !model.IsAdminRole(claims.Role) {
+		return nil, status.Error(codes.PermissionDenied, "only the user or an organization admin may do this")
+	}
+	current, err := users.Get(ctx, req.Id)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "user not found: %v", err)
+	}
+
+	// Start from the stored profile so fields the request omits are preserved.
+	updates := &model.User{
+		Name:        current.Name,
+		Email:       current.Email,
+		DisplayName: current.DisplayName,
+		Locale:      current.Locale,
+		Timezone:    current.Timezone,
+		Phone:       current.Phone,
+	}
 	if req.Email != nil {
 		updates.Email = *req.Email
 	}
@@ -69,25 +104,50 @@ func (s *UserGrpcService) UpdateUser(ctx context.Context, req *pb.UpdateUserRequ
 		updates.Name = *req.Name
 	}
 
-	updatedUser, err := s.userService.Update(req.Id, updates)
+	updatedUser, err := users.Update(ctx, req.Id, updates)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to update user: %v", err)
 	}
 
-	return &pb.UserResponse{
-		Id:        strconv.FormatUint(uint64(updatedUser.ID), 10),
-		Email:     updatedUser.Email,
-		Name:      updatedUser.Name,
-		CreatedAt: updatedUser.CreatedAt.Format(time.RFC3339),
-		UpdatedAt: updatedUser.UpdatedAt.Format(time.RFC3339),
-	}, nil
+	return toUserResponse(updatedUser), nil
 }
 
+// DeleteUser removes the user from the caller's organization, as DELETE
+// /api/users/:id does; the account itself is kept.
 func (s *UserGrpcService) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*pb.DeleteUserResponse, error) {
-	err := s.userService.Delete(req.Id)
+	claims, _, err := s.tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	userID, err := strconv.ParseUint(req.Id, 10, 64)
 	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user id")
+	}
+	err = s.orgService.RemoveMember(ctx, claims.OrgID, claims.UserID, uint(userID))
+	switch {
+	case errors.Is(err, service.ErrForbidden):
+		return nil, status.Errorf(codes.PermissionDenied, "failed to delete user: %v", err)
+	case errors.Is(err, model.ErrNotFound):
+		return nil, status.Errorf(codes.NotFound, "user not found: %v", err)
+	case errors.Is(err, model.ErrLastOwner):
+		return nil, status.Errorf(codes.FailedPrecondition, "failed to delete user: %v", err)
+	case err != nil:
 		return nil, status.Errorf(codes.Internal, "failed to delete user: %v", err)
 	}
 
 	return &pb.DeleteUserResponse{Success: true}, nil
 }
+
+func toUserResponse(u *model.User) *pb.UserResponse {
+	return &pb.UserResponse{
+		Id:          strconv.FormatUint(uint64(u.ID), 10),
+		Email:       u.Email,
+		Name:        u.Name,
+		CreatedAt:   u.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   u.UpdatedAt.Format(time.RFC3339),
+		DisplayName: u.DisplayName,
+		Locale:      u.Locale,
+		Timezone:    u.Timezone,
+		Phone:       u.Phone,
+	}
+}
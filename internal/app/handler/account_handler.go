@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-gin-project/internal/app/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccountHandler struct {
+	service *service.AccountService
+}
+
+func NewAccountHandler(svc *service.AccountService) *AccountHandler {
+	return &AccountHandler{service: svc}
+}
+
+// RequestEmailChange godoc
+// @Summary Start changing the caller's email address
+// @Description Sends a confirmation token to the new address and a notice to the current one.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param change body service.EmailChangeRequest true "New email and current password"
+// @Success 202 {object} model.EmailChange
+// @Failure 400 {object} map[string]string
+// @Router /users/me/email [post]
+func (h *AccountHandler) RequestEmailChange(c *gin.Context) {
+	var req service.EmailChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	change, err := h.service.RequestEmailChange(c.Request.Context(), c.GetUint("userID"), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"email_change": change})
+}
+
+// ConfirmEmailChange godoc
+// @Summary Confirm an email change with the token sent to the new address
+// @Description Swaps the email and revokes all previously issued tokens.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param confirm body service.ConfirmEmailChangeRequest true "Confirmation token"
+// @Success 200 {object} model.User
+// @Failure 400 {object} map[string]string
+// @Router /auth/email-change/confirm [post]
+func (h *AccountHandler) ConfirmEmailChange(c *gin.Context) {
+	var req service.ConfirmEmailChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	user, err := h.service.ConfirmEmailChange(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"user": user})
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go-gin-project/internal/app/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ImportHandler struct {
+	service *service.ImportService
+}
+
+func NewImportHandler(svc *service.ImportService) *ImportHandler {
+	return &ImportHandler{service: svc}
+}
+
+// ImportUsers godoc
+// @Summary Bulk import users from CSV or JSONL
+// @Tags admin
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV (name,email,password header) or JSONL file"
+// @Param format query string false "csv or jsonl; defaults to the file extension"
+// @Param dry_run query bool false "Validate only, do not insert"
+// @Param batch_size query int false "Rows per insert transaction"
+// @Success 200 {object} service.ImportReport
+// @Failure 400 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Router /admin/users/import [post]
+func (h *ImportHandler) ImportUsers(c *gin.Context) {
+	// Allow some headroom over the file limit for the multipart envelope.
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, service.MaxImportBytes+64<<10)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("import file exceeds %d bytes", service.MaxImportBytes)})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	format := c.Query("format")
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(header.Filename), ".")
+	}
+	dryRun, _ := strconv.ParseBool(c.Query("dry_run"))
+	batchSize, _ := strconv.Atoi(c.Query("batch_size"))
+
+	report, err := h.service.Import(c.Request.Context(), file, service.ImportOptions{
+		Format:    format,
+		DryRun:    dryRun,
+		BatchSize: batchSize,
+		OrgID:     c.GetUint("orgID"),
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"report": report})
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"go-gin-project/internal/app/service"
+	"go-gin-project/internal/pkg/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InvitationHandler struct {
+	service *service.InvitationService
+}
+
+type createInvitationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required"`
+}
+
+func NewInvitationHandler(svc *service.InvitationService) *InvitationHandler {
+	return &InvitationHandler{service: svc}
+}
+
+// Create godoc
+// @Summary Invite someone to an organization by email
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param invitation body createInvitationRequest true "Invitation"
+// @Success 201 {object} model.Invitation
+// @Failure 403 {object} map[string]string
+// @Router /orgs/{id}/invitations [post]
+func (h *InvitationHandler) Create(c *gin.Context) {
+	orgID, ok := uintParam(c, "id")
+	if !ok {
+		return
+	}
+	var req createInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	inv, err := h.service.Create(c.Request.Context(), orgID, c.GetUint("userID"), req.Email, req.Role)
+	if err != nil {
+		respondOrgError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"invitation": inv})
+}
+
+// List godoc
+// @Summary List an organization's invitations
+// @Tags organizations
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Success 200 {array} model.Invitation
+// @Failure 403 {object} map[string]string
+// @Router /orgs/{id}/invitations [get]
+func (h *InvitationHandler) List(c *gin.Context) {
+	orgID, ok := uintParam(c, "id")
+	if !ok {
+		return
+	}
+	invitations, err := h.service.List(c.Request.Context(), orgID, c.GetUint("userID"))
+	if err != nil {
+		respondOrgError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"invitations": invitations})
+}
+
+// Revoke godoc
+// @Summary Revoke a pending invitation
+// @Tags organizations
+// @Param id path int true "Organization ID"
+// @Param invitationID path int true "Invitation ID"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Router /orgs/{id}/invitations/{invitationID} [delete]
+func (h *InvitationHandler) Revoke(c *gin.Context) {
+	orgID, ok := uintParam(c, "id")
+	if !ok {
+		return
+	}
+	invitationID, ok := uintParam(c, "invitationID")
+	if !ok {
+		return
+	}
+	if err := h.service.Revoke(c.Request.Context(), orgID, c.GetUint("userID"), invitationID); err != nil {
+		respondOrgError(c, err)
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// Accept godoc
+// @Summary Accept an invitation and create the invitee's account
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param invitation body service.AcceptInvitationRequest true "Invitation token and account details"
+// @Success 201 {object} model.User
+// @Failure 400 {object} map[string]string
+// @Router /auth/invitations/accept [post]
+func (h *InvitationHandler) Accept(c *gin.Context) {
+	var req service.AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	user, err := h.service.Accept(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"user": user})
+}
+
+type joinRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// Join godoc
+// @Summary Accept an invitation with the signed-in account
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param invitation body joinRequest true "Invitation token"
+// @Success 201 {object} model.Membership
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /invitations/accept [post]
+func (h *InvitationHandler) Join(c *gin.Context) {
+	var req joinRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	membership, err := h.service.Join(c.Request.Context(), c.GetUint("userID"), req.Token)
+	if errors.Is(err, model.ErrDuplicate) {
+		c.JSON(http.StatusConflict, gin.H{"error": "already a member of this organization"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"membership": membership})
+}
@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go-gin-project/internal/app/service"
+	"go-gin-project/internal/pkg/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OrganizationHandler struct {
+	service *service.OrganizationService
+}
+
+type createOrganizationRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type memberRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+func NewOrganizationHandler(svc *service.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{service: svc}
+}
+
+// Create godoc
+// @Summary Create an organization owned by the caller
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param organization body createOrganizationRequest true "Organization"
+// @Success 201 {object} model.Organization
+// @Router /orgs [post]
+func (h *OrganizationHandler) Create(c *gin.Context) {
+	var req createOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	org, err := h.service.Create(c.Request.Context(), req.Name, c.GetUint("userID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"organization": org})
+}
+
+// List godoc
+// @Summary List the caller's organizations
+// @Tags organizations
+// @Produce json
+// @Success 200 {array} model.Organization
+// @Router /orgs [get]
+func (h *OrganizationHandler) List(c *gin.Context) {
+	orgs, err := h.service.ListForUser(c.Request.Context(), c.GetUint("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"organizations": orgs})
+}
+
+// ListMembers godoc
+// @Summary List organization members
+// @Tags organizations
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Success 200 {array} model.Membership
+// @Failure 403 {object} map[string]string
+// @Router /orgs/{id}/members [get]
+func (h *OrganizationHandler) ListMembers(c *gin.Context) {
+	orgID, ok := uintParam(c, "id")
+	if !ok {
+		return
+	}
+	members, err := h.service.ListMembers(c.Request.Context(), orgID, c.GetUint("userID"))
+	if err != nil {
+		respondOrgError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"members": members})
+}
+
+// UpdateMember godoc
+// @Summary Change a member's role
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param userID path int true "User ID"
+// @Param role body memberRoleRequest true "Role"
+// @Success 200 {object} model.Membership
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /orgs/{id}/members/{userID} [put]
+func (h *OrganizationHandler) UpdateMember(c *gin.Context) {
+	orgID, ok := uintParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := uintParam(c, "userID")
+	if !ok {
+		return
+	}
+	var req memberRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	member, err := h.service.UpdateMemberRole(c.Request.Context(), orgID, c.GetUint("userID"), userID, req.Role)
+	if err != nil {
+		respondOrgError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"member": member})
+}
+
+// RemoveMember godoc
+// @Summary Remove a member from an organization
+// @Tags organizations
+// @Param id path int true "Organization ID"
+// @Param userID path int true "User ID"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /orgs/{id}/members/{userID} [delete]
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	orgID, ok := uintParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := uintParam(c, "userID")
+	if !ok {
+		return
+	}
+	if err := h.service.RemoveMember(c.Request.Context(), orgID, c.GetUint("userID"), userID); err != nil {
+		respondOrgError(c, err)
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func uintParam(c *gin.Context, name string) (uint, bool) {
+	v, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name})
+		return 0, false
+	}
+	return uint(v), true
+}
+
+func respondOrgError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, model.ErrLastOwner) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
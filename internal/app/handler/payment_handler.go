@@ -42,7 +42,7 @@ func (h *PaymentHandler) CreatePaymentIntent(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	payment, clientSecret, err := h.service.CreatePaymentIntent(req.Amount, req.Currency, req.UserID)
+	payment, clientSecret, err := h.service.ForTenant(c.GetUint("orgID")).CreatePaymentIntent(c.Request.Context(), req.Amount, req.Currency, req.UserID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -64,7 +64,7 @@ func (h *PaymentHandler) RetrievePaymentIntent(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	payment, pi, err := h.service.RetrievePaymentIntent(req.PaymentIntentID)
+	payment, pi, err := h.service.ForTenant(c.GetUint("orgID")).RetrievePaymentIntent(c.Request.Context(), req.PaymentIntentID)
 	if err != nil {
 		var stripeErr *stripe.Error
 		if errors.As(err, &stripeErr) {
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"go-gin-project/internal/app/service"
+	"go-gin-project/internal/pkg/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PrivacyHandler struct {
+	service *service.PrivacyService
+}
+
+func NewPrivacyHandler(svc *service.PrivacyService) *PrivacyHandler {
+	return &PrivacyHandler{service: svc}
+}
+
+// subject returns the service that answers a request about the :id user.
+// Users asking about themselves get their data from every organization;
+// admins only reach their own organization's.
+func (h *PrivacyHandler) subject(c *gin.Context) *service.PrivacyService {
+	if c.Param("id") == meID(c) {
+		return h.service
+	}
+	return h.service.ForTenant(c.GetUint("orgID"))
+}
+
+// Export godoc
+// @Summary Export all data held about a user; the caller must be the user or an org admin
+// @Description Users get their data from all their organizations; admins get what belongs to theirs.
+// @Tags users
+// @Produce json,application/zip
+// @Param id path int true "User ID"
+// @Param format query string false "json (default) or zip"
+// @Success 200 {object} model.UserExport
+// @Failure 404 {object} map[string]string
+// @Router /users/{id}/export [get]
+func (h *PrivacyHandler) Export(c *gin.Context) {
+	id := c.Param("id")
+	svc := h.subject(c)
+	if c.Query("format") == "zip" {
+		archive, err := svc.ExportZip(c.Request.Context(), id)
+		if err != nil {
+			respondPrivacyError(c, err)
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="user-%s-export.zip"`, id))
+		c.Data(http.StatusOK, "application/zip", archive)
+		return
+	}
+
+	export, err := svc.Export(c.Request.Context(), id)
+	if err != nil {
+		respondPrivacyError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"export": export})
+}
+
+// Erase godoc
+// @Summary Erase a user's personal data; the caller must be the user or an org admin
+// @Description Admins may only erase users who belong to no other organization.
+// @Tags users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} model.ErasureReceipt
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /users/{id}/erase [post]
+func (h *PrivacyHandler) Erase(c *gin.Context) {
+	receipt, err := h.subject(c).Erase(c.Request.Context(), c.Param("id"), c.GetUint("userID"))
+	if err != nil {
+		respondPrivacyError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"receipt": receipt})
+}
+
+func respondPrivacyError(c *gin.Context, err error) {
+	if errors.Is(err, model.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if errors.Is(err, service.ErrForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"go-gin-project/internal/app/service"
+	"go-gin-project/internal/pkg/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProfileHandler serves the caller's own profile and preferences.
+type ProfileHandler struct {
+	users       *service.UserService
+	preferences *service.PreferenceService
+	avatars     *service.AvatarService
+}
+
+type profileRequest struct {
+	Name        string `json:"name" binding:"required"`
+	DisplayName string `json:"display_name"`
+	Locale      string `json:"locale"`
+	Timezone    string `json:"timezone"`
+	Phone       string `json:"phone"`
+}
+
+func NewProfileHandler(
+	users *service.UserService,
+	preferences *service.PreferenceService,
+	avatars *service.AvatarService,
+) *ProfileHandler {
+	return &ProfileHandler{users: users, preferences: preferences, avatars: avatars}
+}
+
+// GetMe godoc
+// @Summary Get the caller's profile
+// @Tags users
+// @Produce json
+// @Success 200 {object} model.User
+// @Router /users/me [get]
+func (h *ProfileHandler) GetMe(c *gin.Context) {
+	user, err := h.users.Get(c.Request.Context(), meID(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"user": h.avatars.Decorate(withoutPassword(user))})
+}
+
+// UpdateMe godoc
+// @Summary Replace the caller's profile fields
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param profile body profileRequest true "Profile"
+// @Success 200 {object} model.User
+// @Failure 400 {object} map[string]string
+// @Router /users/me [put]
+func (h *ProfileHandler) UpdateMe(c *gin.Context) {
+	var req profileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	updated, err := h.users.Update(c.Request.Context(), meID(c), &model.User{
+		Name:        req.Name,
+		DisplayName: req.DisplayName,
+		Locale:      req.Locale,
+		Timezone:    req.Timezone,
+		Phone:       req.Phone,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"user": h.avatars.Decorate(updated)})
+}
+
+// PatchMe godoc
+// @Summary Update some of the caller's profile fields
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param profile body service.UserPatch true "Fields to change"
+// @Success 200 {object} model.User
+// @Failure 400 {object} map[string]string
+// @Router /users/me [patch]
+func (h *ProfileHandler) PatchMe(c *gin.Context) {
+	var patch service.UserPatch
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	updated, err := h.users.Patch(c.Request.Context(), meID(c), &patch)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"user": h.avatars.Decorate(updated)})
+}
+
+// DeleteMe godoc
+// @Summary Delete the caller's account
+// @Tags users
+// @Success 204
+// @Router /users/me [delete]
+func (h *ProfileHandler) DeleteMe(c *gin.Context) {
+	if err := h.users.Delete(c.Request.Context(), meID(c)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// ChangePassword godoc
+// @Summary Change the caller's password
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body service.ChangePasswordRequest true "Current and new password"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Router /users/me/password [post]
+func (h *ProfileHandler) ChangePassword(c *gin.Context) {
+	var req service.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.users.ChangePassword(c.Request.Context(), meID(c), &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// GetPreferences godoc
+// @Summary Get the caller's preferences, with defaults for unset keys
+// @Tags users
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Router /users/me/preferences [get]
+func (h *ProfileHandler) GetPreferences(c *gin.Context) {
+	prefs, err := h.preferences.Get(c.Request.Context(), c.GetUint("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"preferences": prefs})
+}
+
+// UpdatePreferences godoc
+// @Summary Set some of the caller's preferences
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param preferences body map[string]string true "Preference keys and values"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Router /users/me/preferences [put]
+func (h *ProfileHandler) UpdatePreferences(c *gin.Context) {
+	var req map[string]string
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	prefs, err := h.preferences.Update(c.Request.Context(), c.GetUint("userID"), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"preferences": prefs})
+}
+
+// meID returns the authenticated user's ID in the string form services expect.
+func meID(c *gin.Context) string {
+	return strconv.FormatUint(uint64(c.GetUint("userID")), 10)
+}
+
+func withoutPassword(u *model.User) *model.User {
+	copied := *u
+	copied.Password = ""
+	return &copied
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"go-gin-project/internal/app/service"
@@ -11,10 +12,22 @@ import (
 
 type UserHandler struct {
 	service *service.UserService
+	avatars *service.AvatarService
+	orgs    *service.OrganizationService
 }
 
-func NewUserHandler(svc *service.UserService) *UserHandler {
-	return &UserHandler{service: svc}
+type createUserRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Email       string `json:"email" binding:"required"`
+	Password    string `json:"password" binding:"required"`
+	DisplayName string `json:"display_name"`
+	Locale      string `json:"locale"`
+	Timezone    string `json:"timezone"`
+	Phone       string `json:"phone"`
+}
+
+func NewUserHandler(svc *service.UserService, avatars *service.AvatarService, orgs *service.OrganizationService) *UserHandler {
+	return &UserHandler{service: svc, avatars: avatars, orgs: orgs}
 }
 
 // Create godoc
@@ -22,22 +35,30 @@ func NewUserHandler(svc *service.UserService) *UserHandler {
 // @Tags users
 // @Accept json
 // @Produce json
-// @Param user body model.User true "User object"
+// @Param user body createUserRequest true "User object"
 // @Success 201 {object} model.User
 // @Failure 400 {object} map[string]string
 // @Router /users [post]
 func (h *UserHandler) Create(c *gin.Context) {
-	var user model.User
-	if err := c.ShouldBindJSON(&user); err != nil {
+	var req createUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	created, err := h.service.Create(&user)
+	created, err := h.service.ForTenant(c.GetUint("orgID")).Create(c.Request.Context(), &model.User{
+		Name:        req.Name,
+		Email:       req.Email,
+		Password:    req.Password,
+		DisplayName: req.DisplayName,
+		Locale:      req.Locale,
+		Timezone:    req.Timezone,
+		Phone:       req.Phone,
+	})
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusCreated, gin.H{"user": created})
+	c.JSON(http.StatusCreated, gin.H{"user": h.avatars.Decorate(created)})
 }
 
 // Get godoc
@@ -49,47 +70,103 @@ func (h *UserHandler) Create(c *gin.Context) {
 // @Failure 404 {object} map[string]string
 // @Router /users/{id} [get]
 func (h *UserHandler) Get(c *gin.Context) {
-	user, err := h.service.Get(c.Param("id"))
+	user, err := h.service.ForTenant(c.GetUint("orgID")).Get(c.Request.Context(), c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"user": user})
+	c.JSON(http.StatusOK, gin.H{"user": h.avatars.Decorate(user)})
 }
 
 // Update godoc
-// @Summary Update user
+// @Summary Update a user's profile; the caller must be the user or an org admin
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param id path int true "User ID"
-// @Param user body model.User true "User object"
+// @Param user body profileRequest true "Profile"
 // @Success 200 {object} model.User
 // @Router /users/{id} [put]
 func (h *UserHandler) Update(c *gin.Context) {
-	var data model.User
-	if err := c.ShouldBindJSON(&data); err != nil {
+	var req profileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	updated, err := h.service.Update(c.Param("id"), &data)
+	updated, err := h.service.ForTenant(c.GetUint("orgID")).Update(c.Request.Context(), c.Param("id"), &model.User{
+		Name:        req.Name,
+		DisplayName: req.DisplayName,
+		Locale:      req.Locale,
+		Timezone:    req.Timezone,
+		Phone:       req.Phone,
+	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"user": updated})
+	c.JSON(http.StatusOK, gin.H{"user": h.avatars.Decorate(updated)})
 }
 
 // Delete godoc
-// @Summary Delete user
+// @Summary Remove a user from the token's organization
+// @Description The account itself is kept; users delete it through DELETE /users/me.
 // @Tags users
 // @Param id path int true "User ID"
 // @Success 204
+// @Failure 403 {object} map[string]string
 // @Router /users/{id} [delete]
 func (h *UserHandler) Delete(c *gin.Context) {
-	if err := h.service.Delete(c.Param("id")); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	userID, ok := uintParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.orgs.RemoveMember(c.Request.Context(), c.GetUint("orgID"), c.GetUint("userID"), userID); err != nil {
+		respondOrgError(c, err)
 		return
 	}
 	c.JSON(http.StatusNoContent, nil)
 }
+
+// UploadAvatar godoc
+// @Summary Upload a user's avatar; the caller must be the user or an org admin
+// @Description Accepts a JPEG, PNG, GIF or WebP image up to 5 MB and stores square PNG thumbnails.
+// @Tags users
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "User ID"
+// @Param avatar formData file true "Avatar image"
+// @Success 200 {object} model.User
+// @Failure 400 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Router /users/{id}/avatar [put]
+func (h *UserHandler) UploadAvatar(c *gin.Context) {
+	// Allow some headroom over the image limit for the multipart envelope.
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, service.MaxAvatarBytes+64<<10)
+	fh, err := c.FormFile("avatar")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": service.ErrAvatarTooLarge.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "avatar file is required"})
+		return
+	}
+	f, err := fh.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer f.Close()
+
+	user, err := h.avatars.ForTenant(c.GetUint("orgID")).Upload(c.Request.Context(), c.Param("id"), f)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, service.ErrAvatarTooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"user": user})
+}
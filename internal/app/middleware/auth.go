@@ -2,16 +2,14 @@ package middleware
 
 import (
 	"net/http"
-	"os"
 	"strings"
 
 	"go-gin-project/internal/app/service"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+func AuthMiddleware(authService *service.AuthService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -27,11 +25,8 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		claims := &service.Claims{}
-		token, err := jwt.ParseWithClaims(parts[1], claims, func(token *jwt.Token) (interface{}, error) {
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
-		if err != nil || !token.Valid {
+		claims, err := authService.ParseToken(c.Request.Context(), parts[1])
+		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
 			c.Abort()
 			return
@@ -39,6 +34,8 @@ func AuthMiddleware() gin.HandlerFunc {
 
 		c.Set("userID", claims.UserID)
 		c.Set("email", claims.Email)
+		c.Set("orgID", claims.OrgID)
+		c.Set("orgRole", claims.Role)
 		c.Next()
 	}
 }
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-gin-project/internal/pkg/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitKeyFunc picks the identity a request is counted against.
+type RateLimitKeyFunc func(c *gin.Context) string
+
+// RateLimitByClient counts authenticated requests per user and anything
+// else per client IP. Only identities the server has verified are used, so
+// a client cannot get a fresh limit by sending new credentials.
+func RateLimitByClient(c *gin.Context) string {
+	if id := c.GetUint("userID"); id != 0 {
+		return fmt.Sprintf("user:%d", id)
+	}
+	return RateLimitByIP(c)
+}
+
+// RateLimitByIP counts requests per client IP, e.g. for login. The IP comes
+// from X-Forwarded-For only when the engine trusts the sending proxy.
+func RateLimitByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// RateLimit limits requests of the named route group to the limit currently
+// returned by limit per key, answering 429 once it is exceeded; a nil limit
+// lets every request through. Every limited response carries
+// RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers (IETF
+// draft), and rejected ones Retry-After. If the limiter fails the request is
+// let through.
+func RateLimit(limiter model.RateLimiter, name string, limit func() *model.RateLimit, key RateLimitKeyFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		current := limit()
+		if current == nil {
+			c.Next()
+			return
+		}
+		res, err := limiter.Allow(c.Request.Context(), name+":"+key(c), *current)
+		if err != nil {
+			slog.Error("rate limit", "name", name, "err", err)
+			c.Next()
+			return
+		}
+
+		h := c.Writer.Header()
+		h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d;burst=%d",
+			current.Rate, int(math.Ceil(current.Period.Seconds())), current.Burst))
+		h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+		h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		h.Set("RateLimit-Reset", ceilSeconds(res.ResetAfter))
+		if !res.Allowed {
+			h.Set("Retry-After", ceilSeconds(res.RetryAfter))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Rate limit exceeded"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
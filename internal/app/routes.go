@@ -7,33 +7,98 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// RouteLimits holds the rate limiting middleware of each route group; nil
+// entries leave that group unlimited.
+type RouteLimits struct {
+	Auth     gin.HandlerFunc // unauthenticated /api/auth routes, per client IP
+	API      gin.HandlerFunc // every authenticated route, per user
+	Payments gin.HandlerFunc // payment routes, in addition to API
+}
+
+func use(g *gin.RouterGroup, h gin.HandlerFunc) {
+	if h != nil {
+		g.Use(h)
+	}
+}
+
 func SetupRoutes(
 	r *gin.Engine,
+	authMiddleware gin.HandlerFunc,
+	limits RouteLimits,
 	userHandler *handler.UserHandler,
 	authHandler *handler.AuthHandler,
 	paymentHandler *handler.PaymentHandler,
+	privacyHandler *handler.PrivacyHandler,
+	importHandler *handler.ImportHandler,
+	orgHandler *handler.OrganizationHandler,
+	invitationHandler *handler.InvitationHandler,
+	accountHandler *handler.AccountHandler,
+	profileHandler *handler.ProfileHandler,
 ) {
 	auth := r.Group("/api/auth")
+	use(auth, limits.Auth)
 	{
 		auth.POST("/login", authHandler.Login)
 		auth.POST("/admin-user", userHandler.Create)
+		auth.POST("/invitations/accept", invitationHandler.Accept)
+		auth.POST("/email-change/confirm", accountHandler.ConfirmEmailChange)
 	}
 
 	api := r.Group("/api")
-	api.Use(middleware.AuthMiddleware())
+	api.Use(authMiddleware)
+	use(api, limits.API)
 	{
+		// Self-service routes act on the token's user and need no organization.
+		me := api.Group("/users/me")
+		{
+			me.GET("", profileHandler.GetMe)
+			me.PUT("", profileHandler.UpdateMe)
+			me.PATCH("", profileHandler.PatchMe)
+			me.DELETE("", profileHandler.DeleteMe)
+			me.POST("/password", profileHandler.ChangePassword)
+			me.GET("/preferences", profileHandler.GetPreferences)
+			me.PUT("/preferences", profileHandler.UpdatePreferences)
+			me.POST("/email", accountHandler.RequestEmailChange)
+		}
+
+		orgs := api.Group("/orgs")
+		{
+			orgs.POST("", orgHandler.Create)
+			orgs.GET("", orgHandler.List)
+			orgs.GET("/:id/members", orgHandler.ListMembers)
+			orgs.PUT("/:id/members/:userID", orgHandler.UpdateMember)
+			orgs.DELETE("/:id/members/:userID", orgHandler.RemoveMember)
+			orgs.POST("/:id/invitations", invitationHandler.Create)
+			orgs.GET("/:id/invitations", invitationHandler.List)
+			orgs.DELETE("/:id/invitations/:invitationID", invitationHandler.Revoke)
+		}
+		// Existing users join an organization by accepting its invitation.
+		api.POST("/invitations/accept", invitationHandler.Join)
+
 		users := api.Group("/users")
+		users.Use(middleware.RequireTenant())
 		{
 			users.POST("/", userHandler.Create)
 			users.GET("/:id", userHandler.Get)
-			users.PUT("/:id", userHandler.Update)
-			users.DELETE("/:id", userHandler.Delete)
+			users.PUT("/:id", middleware.RequireSelfOrOrgAdmin(), userHandler.Update)
+			users.DELETE("/:id", middleware.RequireSelfOrOrgAdmin(), userHandler.Delete)
+			users.PUT("/:id/avatar", middleware.RequireSelfOrOrgAdmin(), userHandler.UploadAvatar)
+			users.GET("/:id/export", middleware.RequireSelfOrOrgAdmin(), privacyHandler.Export)
+			users.POST("/:id/erase", middleware.RequireSelfOrOrgAdmin(), privacyHandler.Erase)
 		}
 
 		payments := api.Group("/payments")
+		payments.Use(middleware.RequireTenant())
+		use(payments, limits.Payments)
 		{
 			payments.POST("/payment-intent", paymentHandler.CreatePaymentIntent)
 			payments.POST("/retrieve", paymentHandler.RetrievePaymentIntent)
 		}
+
+		admin := api.Group("/admin")
+		admin.Use(middleware.RequireOrgAdmin())
+		{
+			admin.POST("/users/import", importHandler.ImportUsers)
+		}
 	}
 }
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-gin-project/internal/pkg/model"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const emailChangeTTL = 24 * time.Hour
+
+// EmailChangeRequest asks to move the caller's account to a new address.
+type EmailChangeRequest struct {
+	NewEmail string `json:"new_email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+// ConfirmEmailChangeRequest carries the token sent to the new address.
+type ConfirmEmailChangeRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// AccountService handles self-service account changes that need extra verification.
+type AccountService struct {
+	userRepo        model.UserRepository
+	emailChangeRepo model.EmailChangeRepository
+	cache           model.CacheService
+	mailer          model.Mailer
+}
+
+func NewAccountService(
+	userRepo model.UserRepository,
+	emailChangeRepo model.EmailChangeRepository,
+	cache model.CacheService,
+	mailer model.Mailer,
+) *AccountService {
+	return &AccountService{
+		userRepo:        userRepo,
+		emailChangeRepo: emailChangeRepo,
+		cache:           cache,
+		mailer:          mailer,
+	}
+}
+
+// RequestEmailChange records a pending change, emails a confirmation token to
+// the new address and warns the current address. The email is not changed yet.
+func (s *AccountService) RequestEmailChange(ctx context.Context, userID uint, req *EmailChangeRequest) (*model.EmailChange, error) {
+	user, err := s.userRepo.FindByID(model.ReadPrimary(ctx), strconv.FormatUint(uint64(userID), 10))
+	if err != nil {
+		return nil, fmt.Errorf("request email change: %w", err)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		return nil, errors.New("request email change: invalid password")
+	}
+
+	newEmail := strings.TrimSpace(req.NewEmail)
+	if addr, err := mail.ParseAddress(newEmail); err != nil || addr.Address != newEmail {
+		return nil, errors.New("request email change: email is invalid")
+	}
+	if strings.EqualFold(newEmail, user.Email) {
+		return nil, errors.New("request email change: new email matches the current one")
+	}
+	if _, err := s.userRepo.FindByEmail(model.ReadPrimary(ctx), newEmail); err == nil {
+		return nil, errors.New("request email change: email address is already in use")
+	}
+
+	token, hash, err := newToken()
+	if err != nil {
+		return nil, fmt.Errorf("request email change: %w", err)
+	}
+	change, err := s.emailChangeRepo.Create(ctx, &model.EmailChange{
+		UserID:    user.ID,
+		NewEmail:  newEmail,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(emailChangeTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	confirm := fmt.Sprintf(
+		"Confirm your new email address by POSTing this token to /api/auth/email-change/confirm:\n\n%s\n\nThe link expires on %s.",
+		token, change.ExpiresAt.UTC().Format(time.RFC1123),
+	)
+	if err := s.mailer.Send(newEmail, "Confirm your new email address", confirm); err != nil {
+		return nil, fmt.Errorf("request email change: send confirmation: %w", err)
+	}
+	notice := fmt.Sprintf(
+		"A request was made to change the email on your account to %s. If this was not you, change your password now.",
+		newEmail,
+	)
+	s.mailer.Send(user.Email, "Your email address is being changed", notice) //nolint:errcheck
+	return change, nil
+}
+
+// ConfirmEmailChange swaps the email and revokes every token issued before.
+func (s *AccountService) ConfirmEmailChange(ctx context.Context, req *ConfirmEmailChangeRequest) (*model.User, error) {
+	// The link is often followed seconds after it was sent: a replica may not have the request yet.
+	change, err := s.emailChangeRepo.FindByTokenHash(model.ReadPrimary(ctx), hashToken(req.Token))
+	if err != nil || change.ConfirmedAt != nil || !time.Now().Before(change.ExpiresAt) {
+		return nil, errors.New("confirm email change: invalid or expired token")
+	}
+	user, err := s.emailChangeRepo.Confirm(ctx, change)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache.InvalidateTag(ctx, userTag(user.ID)) //nolint:errcheck
+	return user, nil
+}
@@ -1,8 +1,9 @@
 package service
 
 import (
+	"context"
 	"errors"
-	"os"
+	"strconv"
 	"time"
 
 	"go-gin-project/internal/pkg/model"
@@ -16,30 +17,46 @@ import (
 type Claims struct {
 	UserID uint   `json:"user_id"`
 	Email  string `json:"email"`
+	OrgID  uint   `json:"org_id,omitempty"`
+	Role   string `json:"role,omitempty"`
+	// Version must match the user's TokenVersion; bumping it revokes the token.
+	Version uint `json:"ver"`
 	jwt.RegisteredClaims
 }
 
 type LoginRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required"`
+	// OrgID selects the tenant for the session; defaults to the user's first organization.
+	OrgID uint `json:"org_id"`
 }
 
 type LoginResponse struct {
 	Token     string `json:"token"`
 	ExpiresIn int64  `json:"expires_in"`
 	UserID    uint   `json:"user_id"`
+	OrgID     uint   `json:"org_id,omitempty"`
+	Role      string `json:"role,omitempty"`
 }
 
 type AuthService struct {
-	userRepo model.UserRepository
+	userRepo  model.UserRepository
+	orgRepo   model.OrganizationRepository
+	jwtSecret func() string
 }
 
-func NewAuthService(userRepo model.UserRepository) *AuthService {
-	return &AuthService{userRepo: userRepo}
+// NewAuthService creates an AuthService that signs and verifies tokens with
+// the key jwtSecret returns at the time. Rotating it invalidates every token
+// signed with the previous key.
+func NewAuthService(userRepo model.UserRepository, orgRepo model.OrganizationRepository, jwtSecret func() string) *AuthService {
+	return &AuthService{userRepo: userRepo, orgRepo: orgRepo, jwtSecret: jwtSecret}
 }
 
-func (s *AuthService) Login(req *LoginRequest) (*LoginResponse, error) {
-	user, err := s.userRepo.FindByEmail(req.Email)
+func (s *AuthService) Login(ctx context.Context, req *LoginRequest) (*LoginResponse, error) {
+	// Read the primary so a user can log in right after registering, and so
+	// the token carries the current TokenVersion.
+	ctx = model.ReadPrimary(ctx)
+	user, err := s.userRepo.FindByEmail(ctx, req.Email)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("invalid email or password")
@@ -51,10 +68,18 @@ func (s *AuthService) Login(req *LoginRequest) (*LoginResponse, error) {
 		return nil, errors.New("invalid email or password")
 	}
 
+	membership, err := s.selectMembership(ctx, user.ID, req.OrgID)
+	if err != nil {
+		return nil, err
+	}
+
 	expiry := time.Now().Add(24 * time.Hour)
 	claims := &Claims{
-		UserID: user.ID,
-		Email:  user.Email,
+		UserID:  user.ID,
+		Email:   user.Email,
+		OrgID:   membership.OrgID,
+		Role:    membership.Role,
+		Version: user.TokenVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expiry),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -62,7 +87,7 @@ func (s *AuthService) Login(req *LoginRequest) (*LoginResponse, error) {
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenStr, err := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	tokenStr, err := token.SignedString([]byte(s.jwtSecret()))
 	if err != nil {
 		return nil, err
 	}
@@ -71,5 +96,68 @@ func (s *AuthService) Login(req *LoginRequest) (*LoginResponse, error) {
 		Token:     tokenStr,
 		ExpiresIn: expiry.Unix(),
 		UserID:    user.ID,
+		OrgID:     membership.OrgID,
+		Role:      membership.Role,
 	}, nil
 }
+
+// selectMembership resolves the tenant for a new session. Users without any
+// organization get an empty membership and can only reach unscoped routes.
+func (s *AuthService) selectMembership(ctx context.Context, userID, orgID uint) (*model.Membership, error) {
+	if orgID != 0 {
+		membership, err := s.orgRepo.FindMembership(ctx, orgID, userID)
+		if err != nil {
+			return nil, errors.New("not a member of the requested organization")
+		}
+		return membership, nil
+	}
+
+	orgs, err := s.orgRepo.ListForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(orgs) == 0 {
+		return &model.Membership{UserID: userID}, nil
+	}
+	return s.orgRepo.FindMembership(ctx, orgs[0].ID, userID)
+}
+
+// ParseToken verifies a signed token and returns its claims once ValidateClaims accepts them.
+func (s *AuthService) ParseToken(ctx context.Context, tokenStr string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenStr, claims, func(*jwt.Token) (interface{}, error) {
+		return []byte(s.jwtSecret()), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil || !token.Valid {
+		return nil, errors.New("invalid or expired token")
+	}
+	if err := s.ValidateClaims(ctx, claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// ValidateClaims rejects tokens of deleted users, tokens revoked by a
+// TokenVersion bump and tokens for an organization the user has left. It
+// replaces the role in claims with the current one, so a demotion applies to
+// tokens already issued.
+func (s *AuthService) ValidateClaims(ctx context.Context, claims *Claims) error {
+	// A replica that has not seen a TokenVersion bump or a removal yet
+	// would let a revoked token through.
+	ctx = model.ReadPrimary(ctx)
+	user, err := s.userRepo.FindByID(ctx, strconv.FormatUint(uint64(claims.UserID), 10))
+	if err != nil {
+		return errors.New("user no longer exists")
+	}
+	if user.TokenVersion != claims.Version {
+		return errors.New("token has been revoked")
+	}
+	if claims.OrgID != 0 {
+		membership, err := s.orgRepo.FindMembership(ctx, claims.OrgID, claims.UserID)
+		if err != nil {
+			return errors.New("no longer a member of the organization")
+		}
+		claims.Role = membership.Role
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"image"
+	_ "image/gif"  // register GIF decoder
+	_ "image/jpeg" // register JPEG decoder
+	"image/png"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go-gin-project/internal/pkg/model"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // register WebP decoder
+)
+
+const (
+	// MaxAvatarBytes is the largest avatar upload accepted.
+	MaxAvatarBytes = 5 << 20
+	// maxAvatarPixels bounds decoded dimensions so small files cannot expand into huge images.
+	maxAvatarPixels = 4096 * 4096
+	avatarURLTTL    = time.Hour
+)
+
+// AvatarSizes are the square thumbnail edge lengths generated for every upload.
+var AvatarSizes = map[string]int{"small": 64, "medium": 128, "large": 256}
+
+var avatarContentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// ErrAvatarTooLarge is returned when an upload exceeds MaxAvatarBytes.
+var ErrAvatarTooLarge = fmt.Errorf("avatar exceeds %d bytes", MaxAvatarBytes)
+
+// AvatarService stores user avatars as resized thumbnails in a blob store.
+type AvatarService struct {
+	repo  model.UserRepository
+	store model.BlobStore
+	cache model.CacheService
+}
+
+func NewAvatarService(repo model.UserRepository, store model.BlobStore, cache model.CacheService) *AvatarService {
+	return &AvatarService{repo: repo, store: store, cache: cache}
+}
+
+// ForTenant returns an AvatarService restricted to members of orgID.
+func (s *AvatarService) ForTenant(orgID uint) *AvatarService {
+	return &AvatarService{repo: s.repo.WithTenant(orgID), store: s.store, cache: s.cache}
+}
+
+// Upload validates the image in r, stores a thumbnail per AvatarSizes and
+// replaces the user's previous avatar.
+func (s *AvatarService) Upload(ctx context.Context, id string, r io.Reader) (*model.User, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxAvatarBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("upload avatar: %w", err)
+	}
+	if len(data) > MaxAvatarBytes {
+		return nil, fmt.Errorf("upload avatar: %w", ErrAvatarTooLarge)
+	}
+	if ct := http.DetectContentType(data); !avatarContentTypes[ct] {
+		return nil, fmt.Errorf("upload avatar: unsupported content type %q", ct)
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("upload avatar: %w", err)
+	}
+	if cfg.Width*cfg.Height > maxAvatarPixels {
+		return nil, fmt.Errorf("upload avatar: image is %dx%d, too many pixels", cfg.Width, cfg.Height)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("upload avatar: %w", err)
+	}
+
+	if _, err := s.repo.FindByID(ctx, id); err != nil {
+		return nil, fmt.Errorf("upload avatar: %w", err)
+	}
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, fmt.Errorf("upload avatar: %w", err)
+	}
+	key := fmt.Sprintf("avatars/%s/%s", id, hex.EncodeToString(suffix))
+
+	for size, edge := range AvatarSizes {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, thumbnail(img, edge)); err != nil {
+			return nil, fmt.Errorf("upload avatar: encode %s: %w", size, err)
+		}
+		if err := s.store.Put(avatarBlobKey(key, size), &buf, int64(buf.Len()), "image/png"); err != nil {
+			deleteAvatar(s.store, key)
+			return nil, fmt.Errorf("upload avatar: %w", err)
+		}
+	}
+
+	previous, err := s.repo.UpdateAvatar(ctx, id, key)
+	if err != nil {
+		deleteAvatar(s.store, key)
+		return nil, fmt.Errorf("upload avatar: %w", err)
+	}
+	deleteAvatar(s.store, previous)
+	s.cache.InvalidateTag(ctx, userTag(id)) //nolint:errcheck
+
+	user, err := s.repo.FindByID(model.ReadPrimary(ctx), id)
+	if err != nil {
+		return nil, fmt.Errorf("upload avatar: %w", err)
+	}
+	user.Password = ""
+	return s.Decorate(user), nil
+}
+
+// Decorate fills in signed thumbnail URLs for the user's avatar, if any.
+func (s *AvatarService) Decorate(user *model.User) *model.User {
+	if user == nil || user.AvatarKey == "" {
+		return user
+	}
+	urls := make(map[string]string, len(AvatarSizes))
+	for size := range AvatarSizes {
+		u, err := s.store.SignedURL(avatarBlobKey(user.AvatarKey, size), avatarURLTTL)
+		if err != nil {
+			slog.Warn("avatar url", "user", user.ID, "err", err)
+			return user
+		}
+		urls[size] = u
+	}
+	user.AvatarURLs = urls
+	return user
+}
+
+// thumbnail center-crops img to a square and scales it to edge x edge.
+func thumbnail(img image.Image, edge int) image.Image {
+	b := img.Bounds()
+	side := b.Dx()
+	if b.Dy() < side {
+		side = b.Dy()
+	}
+	x0 := b.Min.X + (b.Dx()-side)/2
+	y0 := b.Min.Y + (b.Dy()-side)/2
+	dst := image.NewRGBA(image.Rect(0, 0, edge, edge))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, image.Rect(x0, y0, x0+side, y0+side), draw.Over, nil)
+	return dst
+}
+
+func avatarBlobKey(key, size string) string {
+	return key + "/" + size + ".png"
+}
+
+// deleteAvatar removes every thumbnail under key; failures are logged, not returned,
+// since a leftover blob is harmless once nothing references it.
+func deleteAvatar(store model.BlobStore, key string) {
+	if key == "" || store == nil {
+		return
+	}
+	for size := range AvatarSizes {
+		if err := store.Delete(avatarBlobKey(key, size)); err != nil {
+			slog.Warn("delete avatar blob", "key", avatarBlobKey(key, size), "err", err)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+package service
+
+import "time"
+
+// TTLFunc returns how long a cache entry lives. It is called for every entry,
+// so the setting can change while the service runs.
+type TTLFunc func() time.Duration
+
+// FixedTTL returns a TTLFunc that always returns d.
+func FixedTTL(d time.Duration) TTLFunc {
+	return func() time.Duration { return d }
+}
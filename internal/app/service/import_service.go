@@ -0,0 +1,314 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"sort"
+	"strings"
+
+	"go-gin-project/internal/pkg/model"
+)
+
+const defaultImportBatchSize = 500
+
+// MaxImportBytes is the largest import file accepted over HTTP.
+const MaxImportBytes = 10 << 20
+
+// Supported bulk import formats.
+const (
+	ImportFormatCSV   = "csv"
+	ImportFormatJSONL = "jsonl"
+)
+
+// Per-row import outcomes.
+const (
+	ImportStatusCreated = "created"
+	ImportStatusValid   = "valid"
+	ImportStatusFailed  = "failed"
+)
+
+// ImportRow is one user record read from an import file.
+type ImportRow struct {
+	Line     int    `json:"-"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// ImportResult reports what happened to a single input row.
+type ImportResult struct {
+	Line   int    `json:"line"`
+	Email  string `json:"email"`
+	Status string `json:"status"`
+	UserID uint   `json:"user_id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ImportReport summarizes a bulk import run.
+type ImportReport struct {
+	DryRun    bool           `json:"dry_run"`
+	Total     int            `json:"total"`
+	Succeeded int            `json:"succeeded"`
+	Failed    int            `json:"failed"`
+	Results   []ImportResult `json:"results"`
+}
+
+// ImportOptions controls a bulk import run.
+type ImportOptions struct {
+	Format    string
+	DryRun    bool
+	BatchSize int
+	// OrgID, when set, makes every imported user a member of that organization.
+	OrgID uint
+}
+
+// ImportService creates users in bulk from CSV or JSONL files.
+type ImportService struct {
+	repo model.UserRepository
+}
+
+func NewImportService(repo model.UserRepository) *ImportService {
+	return &ImportService{repo: repo}
+}
+
+// Import parses, validates and inserts the users in r. Invalid rows are
+// reported and skipped; valid rows are inserted in transactional batches.
+func (s *ImportService) Import(ctx context.Context, r io.Reader, opts ImportOptions) (*ImportReport, error) {
+	rows, parseResults, err := ParseImport(r, opts.Format)
+	if err != nil {
+		return nil, fmt.Errorf("import users: %w", err)
+	}
+
+	repo := s.repo.WithTenant(opts.OrgID)
+	report := &ImportReport{DryRun: opts.DryRun, Results: parseResults}
+	valid := validateImportRows(rows, report)
+
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultImportBatchSize
+	}
+	for start := 0; start < len(valid); start += batchSize {
+		end := min(start+batchSize, len(valid))
+		batch := skipExistingEmails(ctx, repo, valid[start:end], report)
+		if opts.DryRun {
+			for _, row := range batch {
+				report.add(ImportResult{Line: row.Line, Email: row.Email, Status: ImportStatusValid})
+			}
+			continue
+		}
+		if len(batch) > 0 {
+			insertImportBatch(ctx, repo, batch, report)
+		}
+	}
+	return report.finish(), nil
+}
+
+func validateImportRows(rows []ImportRow, report *ImportReport) []ImportRow {
+	seen := make(map[string]int, len(rows))
+	valid := make([]ImportRow, 0, len(rows))
+	for _, row := range rows {
+		if err := validateImportRow(row); err != nil {
+			report.fail(row, err)
+			continue
+		}
+		email := strings.ToLower(row.Email)
+		if line, dup := seen[email]; dup {
+			report.fail(row, fmt.Errorf("duplicate email, first seen on line %d", line))
+			continue
+		}
+		seen[email] = row.Line
+		valid = append(valid, row)
+	}
+	return valid
+}
+
+// skipExistingEmails fails the rows whose email already belongs to a user,
+// looking the whole batch up in one query, and returns the rest.
+func skipExistingEmails(ctx context.Context, repo model.UserRepository, batch []ImportRow, report *ImportReport) []ImportRow {
+	emails := make([]string, 0, len(batch))
+	for _, row := range batch {
+		emails = append(emails, row.Email)
+	}
+	existing, err := repo.ExistingEmails(model.ReadPrimary(ctx), emails)
+	if err != nil {
+		for _, row := range batch {
+			report.fail(row, err)
+		}
+		return nil
+	}
+	taken := make(map[string]bool, len(existing))
+	for _, email := range existing {
+		taken[email] = true
+	}
+	rest := make([]ImportRow, 0, len(batch))
+	for _, row := range batch {
+		if taken[row.Email] {
+			report.fail(row, errors.New("user already exists"))
+			continue
+		}
+		rest = append(rest, row)
+	}
+	return rest
+}
+
+func insertImportBatch(ctx context.Context, repo model.UserRepository, batch []ImportRow, report *ImportReport) {
+	users := make([]*model.User, 0, len(batch))
+	for _, row := range batch {
+		users = append(users, &model.User{Name: row.Name, Email: row.Email, Password: row.Password})
+	}
+
+	created, err := repo.CreateBatch(ctx, users)
+	if errors.Is(err, model.ErrDuplicate) {
+		// An email taken since validation (a concurrent signup, or a replica
+		// that had not seen it) fails only its own row: retry one by one.
+		if len(batch) == 1 {
+			report.fail(batch[0], errors.New("user already exists"))
+			return
+		}
+		for i := range batch {
+			insertImportBatch(ctx, repo, batch[i:i+1], report)
+		}
+		return
+	}
+	if err != nil {
+		for _, row := range batch {
+			report.fail(row, fmt.Errorf("batch rolled back: %w", err))
+		}
+		return
+	}
+	for i, row := range batch {
+		report.add(ImportResult{Line: row.Line, Email: row.Email, Status: ImportStatusCreated, UserID: created[i].ID})
+	}
+}
+
+func validateImportRow(row ImportRow) error {
+	var problems []string
+	if strings.TrimSpace(row.Name) == "" {
+		problems = append(problems, "name is required")
+	}
+	if row.Email == "" {
+		problems = append(problems, "email is required")
+	} else if addr, err := mail.ParseAddress(row.Email); err != nil || addr.Address != row.Email {
+		problems = append(problems, "email is invalid")
+	}
+	if len(row.Password) < minPasswordLength {
+		problems = append(problems, fmt.Sprintf("password must be at least %d characters", minPasswordLength))
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// ParseImport reads rows in the given format. Rows that cannot be decoded are
+// returned as failed results rather than aborting the whole file.
+func ParseImport(r io.Reader, format string) ([]ImportRow, []ImportResult, error) {
+	switch strings.ToLower(format) {
+	case ImportFormatCSV:
+		return parseCSV(r)
+	case ImportFormatJSONL, "ndjson":
+		return parseJSONL(r)
+	default:
+		return nil, nil, fmt.Errorf("unsupported import format %q", format)
+	}
+}
+
+func parseCSV(r io.Reader) ([]ImportRow, []ImportResult, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("read csv header: %w", err)
+	}
+	cols := make(map[string]int, len(header))
+	for i, h := range header {
+		cols[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, required := range []string{"name", "email", "password"} {
+		if _, ok := cols[required]; !ok {
+			return nil, nil, fmt.Errorf("csv header is missing column %q", required)
+		}
+	}
+
+	var rows []ImportRow
+	var failures []ImportResult
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			failures = append(failures, ImportResult{Line: line, Status: ImportStatusFailed, Error: err.Error()})
+			continue
+		}
+		field := func(name string) string {
+			if i := cols[name]; i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		rows = append(rows, ImportRow{
+			Line:     line,
+			Name:     field("name"),
+			Email:    field("email"),
+			Password: field("password"),
+		})
+	}
+	return rows, failures, nil
+}
+
+func parseJSONL(r io.Reader) ([]ImportRow, []ImportResult, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var rows []ImportRow
+	var failures []ImportResult
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var row ImportRow
+		if err := json.Unmarshal([]byte(text), &row); err != nil {
+			failures = append(failures, ImportResult{Line: line, Status: ImportStatusFailed, Error: err.Error()})
+			continue
+		}
+		row.Line = line
+		row.Email = strings.TrimSpace(row.Email)
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("read jsonl: %w", err)
+	}
+	return rows, failures, nil
+}
+
+func (r *ImportReport) add(result ImportResult) {
+	r.Results = append(r.Results, result)
+}
+
+func (r *ImportReport) fail(row ImportRow, err error) {
+	r.add(ImportResult{Line: row.Line, Email: row.Email, Status: ImportStatusFailed, Error: err.Error()})
+}
+
+func (r *ImportReport) finish() *ImportReport {
+	sort.SliceStable(r.Results, func(i, j int) bool { return r.Results[i].Line < r.Results[j].Line })
+	r.Total = len(r.Results)
+	r.Succeeded, r.Failed = 0, 0
+	for _, res := range r.Results {
+		if res.Status == ImportStatusFailed {
+			r.Failed++
+		} else {
+			r.Succeeded++
+		}
+	}
+	return r
+}
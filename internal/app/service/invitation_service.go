@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-gin-project/internal/pkg/model"
+)
+
+const invitationTTL = 7 * 24 * time.Hour
+
+// AcceptInvitationRequest is the payload an invitee submits to join.
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Name     string `json:"name" binding:"required"`
+	Password string `json:"password" binding:"required,min=8"`
+}
+
+type InvitationService struct {
+	invitationRepo model.InvitationRepository
+	orgRepo        model.OrganizationRepository
+	userRepo       model.UserRepository
+	mailer         model.Mailer
+}
+
+func NewInvitationService(
+	invitationRepo model.InvitationRepository,
+	orgRepo model.OrganizationRepository,
+	userRepo model.UserRepository,
+	mailer model.Mailer,
+) *InvitationService {
+	return &InvitationService{
+		invitationRepo: invitationRepo,
+		orgRepo:        orgRepo,
+		userRepo:       userRepo,
+		mailer:         mailer,
+	}
+}
+
+// Create invites email to orgID with role and emails them a single-use token.
+func (s *InvitationService) Create(ctx context.Context, orgID, inviterID uint, email, role string) (*model.Invitation, error) {
+	caller, err := requireOrgRole(ctx, s.orgRepo, orgID, inviterID, true)
+	if err != nil {
+		return nil, fmt.Errorf("create invitation: %w", err)
+	}
+	if err := checkAssignableRole(caller.Role, role); err != nil {
+		return nil, fmt.Errorf("create invitation: %w", err)
+	}
+	email = strings.TrimSpace(email)
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+		return nil, errors.New("create invitation: email is invalid")
+	}
+	org, err := s.orgRepo.FindByID(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("create invitation: %w", err)
+	}
+
+	token, hash, err := newToken()
+	if err != nil {
+		return nil, fmt.Errorf("create invitation: %w", err)
+	}
+	inv, err := s.invitationRepo.Create(ctx, &model.Invitation{
+		OrgID:     orgID,
+		Email:     email,
+		Role:      role,
+		TokenHash: hash,
+		InvitedBy: inviterID,
+		ExpiresAt: time.Now().Add(invitationTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	body := fmt.Sprintf(
+		"You have been invited to join %s as %s.\n\nIf you have no account yet, accept by POSTing this token with your name and password to /api/auth/invitations/accept. If you already have one, sign in and POST the token to /api/invitations/accept:\n\n%s\n\nThe invitation expires on %s.",
+		org.Name, role, token, inv.ExpiresAt.UTC().Format(time.RFC1123),
+	)
+	if err := s.mailer.Send(email, "You're invited to "+org.Name, body); err != nil {
+		// Nobody can redeem the token, so don't leave a pending invitation behind.
+		if delErr := s.invitationRepo.Delete(context.WithoutCancel(ctx), inv.ID); delErr != nil {
+			err = errors.Join(err, delErr)
+		}
+		return nil, fmt.Errorf("create invitation: send email: %w", err)
+	}
+	return inv, nil
+}
+
+// List returns the invitations of orgID; the caller must be an admin.
+func (s *InvitationService) List(ctx context.Context, orgID, callerID uint) ([]*model.Invitation, error) {
+	if _, err := requireOrgRole(ctx, s.orgRepo, orgID, callerID, true); err != nil {
+		return nil, fmt.Errorf("list invitations: %w", err)
+	}
+	return s.invitationRepo.ListByOrg(ctx, orgID)
+}
+
+// Revoke cancels a pending invitation; the caller must be an admin.
+func (s *InvitationService) Revoke(ctx context.Context, orgID, callerID, id uint) error {
+	if _, err := requireOrgRole(ctx, s.orgRepo, orgID, callerID, true); err != nil {
+		return fmt.Errorf("revoke invitation: %w", err)
+	}
+	return s.invitationRepo.Revoke(ctx, orgID, id)
+}
+
+// Accept redeems the token, creating the invitee's account with their chosen
+// password and a membership carrying the invited role. Invitees who already
+// have an account use Join instead.
+func (s *InvitationService) Accept(ctx context.Context, req *AcceptInvitationRequest) (*model.User, error) {
+	ctx = model.ReadPrimary(ctx)
+	inv, err := s.pending(ctx, req.Token)
+	if err != nil {
+		return nil, fmt.Errorf("accept invitation: %w", err)
+	}
+	if _, err := s.userRepo.FindByEmail(ctx, inv.Email); err == nil {
+		return nil, errors.New("accept invitation: an account with this email already exists, sign in and accept the invitation from it")
+	}
+	return s.invitationRepo.Accept(ctx, inv, &model.User{Name: req.Name, Password: req.Password})
+}
+
+// Join redeems the token for the signed-in user userID, adding them to the
+// organization with the invited role. The invitation must have been sent to
+// the user's email address, so that accepting it is their own choice.
+func (s *InvitationService) Join(ctx context.Context, userID uint, token string) (*model.Membership, error) {
+	ctx = model.ReadPrimary(ctx)
+	inv, err := s.pending(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("join organization: %w", err)
+	}
+	user, err := s.userRepo.FindByID(ctx, strconv.FormatUint(uint64(userID), 10))
+	if err != nil {
+		return nil, fmt.Errorf("join organization: %w", err)
+	}
+	if !strings.EqualFold(user.Email, inv.Email) {
+		return nil, errors.New("join organization: the invitation was sent to another email address")
+	}
+	return s.invitationRepo.Join(ctx, inv, userID)
+}
+
+// pending returns the invitation for token if it can still be accepted.
+func (s *InvitationService) pending(ctx context.Context, token string) (*model.Invitation, error) {
+	inv, err := s.invitationRepo.FindByTokenHash(ctx, hashToken(token))
+	if err != nil || !inv.IsPending(time.Now()) {
+		return nil, errors.New("invalid or expired invitation")
+	}
+	return inv, nil
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go-gin-project/internal/pkg/model"
+)
+
+// ErrForbidden is returned when the caller lacks the organization role an operation needs.
+var ErrForbidden = errors.New("forbidden")
+
+type OrganizationService struct {
+	orgRepo model.OrganizationRepository
+	cache   model.CacheService
+}
+
+func NewOrganizationService(orgRepo model.OrganizationRepository, cache model.CacheService) *OrganizationService {
+	return &OrganizationService{orgRepo: orgRepo, cache: cache}
+}
+
+// Create makes a new organization owned by ownerID.
+func (s *OrganizationService) Create(ctx context.Context, name string, ownerID uint) (*model.Organization, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("create organization: name is required")
+	}
+	return s.orgRepo.Create(ctx, &model.Organization{Name: name}, ownerID)
+}
+
+// ListForUser returns the organizations userID belongs to.
+func (s *OrganizationService) ListForUser(ctx context.Context, userID uint) ([]*model.Organization, error) {
+	return s.orgRepo.ListForUser(ctx, userID)
+}
+
+// ListMembers returns the members of orgID; the caller must be a member.
+func (s *OrganizationService) ListMembers(ctx context.Context, orgID, callerID uint) ([]*model.Membership, error) {
+	if _, err := requireOrgRole(ctx, s.orgRepo, orgID, callerID, false); err != nil {
+		return nil, fmt.Errorf("list members: %w", err)
+	}
+	return s.orgRepo.ListMembers(ctx, orgID)
+}
+
+// UpdateMemberRole changes a member's role; the caller must be an admin, and
+// only owners may grant or revoke the owner role.
+func (s *OrganizationService) UpdateMemberRole(ctx context.Context, orgID, callerID, userID uint, role string) (*model.Membership, error) {
+	caller, err := requireOrgRole(ctx, s.orgRepo, orgID, callerID, true)
+	if err != nil {
+		return nil, fmt.Errorf("update member role: %w", err)
+	}
+	if err := checkAssignableRole(caller.Role, role); err != nil {
+		return nil, fmt.Errorf("update member role: %w", err)
+	}
+	target, err := s.orgRepo.FindMembership(ctx, orgID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("update member role: %w", err)
+	}
+	if target.Role == model.RoleOwner && caller.Role != model.RoleOwner {
+		return nil, fmt.Errorf("update member role: only owners can change an owner: %w", ErrForbidden)
+	}
+	return s.orgRepo.UpdateMemberRole(ctx, orgID, userID, role)
+}
+
+// RemoveMember removes userID from orgID. Admins may remove others; any member may leave.
+func (s *OrganizationService) RemoveMember(ctx context.Context, orgID, callerID, userID uint) error {
+	caller, err := requireOrgRole(ctx, s.orgRepo, orgID, callerID, callerID != userID)
+	if err != nil {
+		return fmt.Errorf("remove member: %w", err)
+	}
+	target, err := s.orgRepo.FindMembership(ctx, orgID, userID)
+	if err != nil {
+		return fmt.Errorf("remove member: %w", err)
+	}
+	if target.Role == model.RoleOwner && caller.Role != model.RoleOwner {
+		return fmt.Errorf("remove member: only owners can remove an owner: %w", ErrForbidden)
+	}
+	if err := s.orgRepo.RemoveMember(ctx, orgID, userID); err != nil {
+		return err
+	}
+	s.cache.Delete(ctx, tenantKey(orgID, fmt.Sprintf("user:%d", userID))) //nolint:errcheck
+	return nil
+}
+
+// requireOrgRole loads the caller's membership and, if admin is set, checks it can manage the org.
+func requireOrgRole(ctx context.Context, orgRepo model.OrganizationRepository, orgID, callerID uint, admin bool) (*model.Membership, error) {
+	membership, err := orgRepo.FindMembership(ctx, orgID, callerID)
+	if err != nil {
+		return nil, fmt.Errorf("not a member: %w", ErrForbidden)
+	}
+	if admin && !model.IsAdminRole(membership.Role) {
+		return nil, fmt.Errorf("admin role required: %w", ErrForbidden)
+	}
+	return membership, nil
+}
+
+func checkAssignableRole(callerRole, role string) error {
+	if !model.IsValidRole(role) {
+		return fmt.Errorf("invalid role %q", role)
+	}
+	if role == model.RoleOwner && callerRole != model.RoleOwner {
+		return fmt.Errorf("only owners can grant the owner role: %w", ErrForbidden)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go-gin-project/internal/pkg/model"
+)
+
+const (
+	// relayLockKey is the lock instances take turns on to relay the outbox.
+	relayLockKey = "outbox-relay"
+	// relayLockTTL bounds how long a crashed instance can stop others from relaying.
+	relayLockTTL = 30 * time.Second
+)
+
+// OutboxRelayOptions tunes NewOutboxRelay; zero fields take the defaults noted.
+type OutboxRelayOptions struct {
+	// PollInterval is the wait between polls once no more events are due. Default 1 second.
+	PollInterval time.Duration
+	// BatchSize is how many events one poll publishes at most. Default 100.
+	BatchSize int
+	// MaxAttempts is how many failed publishes dead-letter an event. Default 20.
+	MaxAttempts int
+	// RetryBackoff is the wait after an event's first failed publish. It
+	// doubles with each further failure, up to MaxBackoff. Defaults 1 second
+	// and 5 minutes.
+	RetryBackoff time.Duration
+	MaxBackoff   time.Duration
+}
+
+// OutboxRelay publishes the events repositories record in the outbox.
+// Delivery is at least once: an event is published again if the relay stops
+// between publishing it and removing it from the outbox.
+type OutboxRelay struct {
+	outbox model.OutboxRepository
+	broker model.EventBroker
+	locker model.Locker
+	opts   OutboxRelayOptions
+}
+
+// NewOutboxRelay creates a relay from outbox to broker. Relays of several
+// instances take turns through locker, so it must be shared by them to keep
+// each aggregate's events in order.
+func NewOutboxRelay(outbox model.OutboxRepository, broker model.EventBroker, locker model.Locker, opts OutboxRelayOptions) *OutboxRelay {
+	if opts.PollInterval == 0 {
+		opts.PollInterval = time.Second
+	}
+	if opts.BatchSize == 0 {
+		opts.BatchSize = 100
+	}
+	if opts.MaxAttempts == 0 {
+		opts.MaxAttempts = 20
+	}
+	if opts.RetryBackoff == 0 {
+		opts.RetryBackoff = time.Second
+	}
+	if opts.MaxBackoff == 0 {
+		opts.MaxBackoff = 5 * time.Minute
+	}
+	return &OutboxRelay{outbox: outbox, broker: broker, locker: locker, opts: opts}
+}
+
+// Run relays events until ctx ends.
+func (r *OutboxRelay) Run(ctx context.Context) {
+	for {
+		n, err := r.RelayBatch(ctx)
+		if err != nil && ctx.Err() == nil {
+			slog.Warn("outbox relay", "err", err)
+		}
+		if err == nil && n == r.opts.BatchSize {
+			// A full batch suggests more events are due.
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.opts.PollInterval):
+		}
+	}
+}
+
+// RelayBatch publishes up to BatchSize due events and returns how many it
+// handled. An event that fails to publish is retried later, or dead-lettered
+// after MaxAttempts; the events of its aggregate wait for it until then.
+func (r *OutboxRelay) RelayBatch(ctx context.Context) (int, error) {
+	lock, err := r.locker.Obtain(ctx, relayLockKey, relayLockTTL)
+	if err != nil {
+		return 0, fmt.Errorf("lock: %w", err)
+	}
+	defer lock.Release(context.WithoutCancel(ctx)) //nolint:errcheck
+
+	// Publishing stops well before the lock expires, so that another
+	// instance cannot start on the same aggregates meanwhile.
+	publishCtx, cancel := context.WithTimeout(ctx, relayLockTTL/2)
+	defer cancel()
+
+	events, err := r.outbox.Pending(ctx, r.opts.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	for i, event := range events {
+		if publishCtx.Err() != nil {
+			return i, nil
+		}
+		if err := r.relay(ctx, publishCtx, event); err != nil {
+			return i, err
+		}
+	}
+	return len(events), nil
+}
+
+// relay publishes event and settles it in the outbox.
+func (r *OutboxRelay) relay(ctx, publishCtx context.Context, event *model.Event) error {
+	err := r.broker.Publish(publishCtx, event)
+	if err == nil {
+		return r.outbox.MarkPublished(ctx, event.ID)
+	}
+
+	attempts := event.Attempts + 1
+	var retryAt time.Time
+	if attempts < r.opts.MaxAttempts {
+		retryAt = time.Now().Add(r.backoff(attempts))
+	} else {
+		slog.Warn("outbox event dead-lettered", "id", event.ID, "type", event.Type,
+			"aggregate", event.AggregateType, "aggregate_id", event.AggregateID, "attempts", attempts, "err", err)
+	}
+	return r.outbox.MarkFailed(ctx, event.ID, err, retryAt)
+}
+
+// backoff is the wait before retrying an event that failed attempts times.
+func (r *OutboxRelay) backoff(attempts int) time.Duration {
+	d := r.opts.RetryBackoff
+	for i := 1; i < attempts && d < r.opts.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, r.opts.MaxBackoff)
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -12,26 +13,53 @@ import (
 type PaymentService struct {
 	paymentRepo model.PaymentRepository
 	userRepo    model.UserRepository
-	cache       model.CacheService
+	tx          model.TxManager
+	cache       model.LoadingCache
+	cacheTTL    TTLFunc
 	stripe      model.StripeService
+	locker      model.Locker
+	orgID       uint
 }
 
+// paymentLockTTL bounds how long a crashed instance can block status updates of a payment.
+const paymentLockTTL = 30 * time.Second
+
 func NewPaymentService(
 	paymentRepo model.PaymentRepository,
 	userRepo model.UserRepository,
-	cache model.CacheService,
+	tx model.TxManager,
+	cache model.LoadingCache,
+	cacheTTL TTLFunc,
 	stripe model.StripeService,
+	locker model.Locker,
 ) *PaymentService {
 	return &PaymentService{
 		paymentRepo: paymentRepo,
 		userRepo:    userRepo,
+		tx:          tx,
 		cache:       cache,
+		cacheTTL:    cacheTTL,
 		stripe:      stripe,
+		locker:      locker,
+	}
+}
+
+// ForTenant returns a PaymentService restricted to payments and users of orgID.
+func (s *PaymentService) ForTenant(orgID uint) *PaymentService {
+	return &PaymentService{
+		paymentRepo: s.paymentRepo.WithTenant(orgID),
+		userRepo:    s.userRepo.WithTenant(orgID),
+		tx:          s.tx,
+		cache:       s.cache,
+		cacheTTL:    s.cacheTTL,
+		stripe:      s.stripe,
+		locker:      s.locker,
+		orgID:       orgID,
 	}
 }
 
-func (s *PaymentService) CreatePaymentIntent(amount float64, currency string, userID uint) (*model.Payment, string, error) {
-	if _, err := s.userRepo.FindByID(fmt.Sprintf("%d", userID)); err != nil {
+func (s *PaymentService) CreatePaymentIntent(ctx context.Context, amount float64, currency string, userID uint) (*model.Payment, string, error) {
+	if _, err := s.userRepo.FindByID(ctx, fmt.Sprintf("%d", userID)); err != nil {
 		return nil, "", fmt.Errorf("create payment intent: invalid user: %w", err)
 	}
 
@@ -42,6 +70,7 @@ func (s *PaymentService) CreatePaymentIntent(amount float64, currency string, us
 			Enabled: stripe.Bool(true),
 		},
 	}
+	params.Context = ctx
 	pi, err := s.stripe.New(params)
 	if err != nil {
 		return nil, "", fmt.Errorf("create payment intent: stripe: %w", err)
@@ -54,38 +83,80 @@ func (s *PaymentService) CreatePaymentIntent(amount float64, currency string, us
 		StripeID:      pi.ID,
 		PaymentStatus: string(pi.Status),
 	}
-	saved, err := s.paymentRepo.Create(payment)
+	// The user may have been deleted during the Stripe call. Locking it in
+	// the transaction that inserts the payment keeps it from going away
+	// until the payment is committed.
+	var saved *model.Payment
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.userRepo.LockByID(ctx, fmt.Sprintf("%d", userID)); err != nil {
+			return fmt.Errorf("invalid user: %w", err)
+		}
+		saved, err = s.paymentRepo.Create(ctx, payment)
+		if err != nil {
+			return fmt.Errorf("save: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, "", fmt.Errorf("create payment intent: save: %w", err)
+		return nil, "", fmt.Errorf("create payment intent: %w", err)
 	}
 
 	return saved, pi.ClientSecret, nil
 }
 
-func (s *PaymentService) RetrievePaymentIntent(paymentIntentID string) (*model.Payment, *stripe.PaymentIntent, error) {
-	cacheKey := fmt.Sprintf("payment:%s", paymentIntentID)
+// RetrievePaymentIntent returns the payment with its status refreshed from
+// Stripe. The PaymentIntent is nil when the payment was served from cache.
+func (s *PaymentService) RetrievePaymentIntent(ctx context.Context, paymentIntentID string) (*model.Payment, *stripe.PaymentIntent, error) {
+	key := fmt.Sprintf("payment:%s", paymentIntentID)
+	cacheKey := tenantKey(s.orgID, key)
 
-	var cached model.Payment
-	if err := s.cache.Get(cacheKey, &cached); err == nil {
-		return &cached, nil, nil
-	}
+	// The loader may run in the background to refresh a stale entry, so it
+	// hands the PaymentIntent over a channel rather than a shared variable.
+	fetched := make(chan *stripe.PaymentIntent, 1)
+	var payment model.Payment
+	err := s.cache.GetOrLoad(ctx, cacheKey, &payment, s.cacheTTL(), func(ctx context.Context) (interface{}, error) {
+		// Concurrent refreshes across instances would otherwise race to write
+		// whichever Stripe status they fetched last; the fencing token stops a
+		// holder whose lock expired mid-call from overwriting a newer status.
+		lock, err := s.locker.Obtain(ctx, key, paymentLockTTL)
+		if err != nil {
+			return nil, err
+		}
+		defer lock.Release(context.WithoutCancel(ctx)) //nolint:errcheck
 
-	pi, err := s.stripe.Get(paymentIntentID, nil)
-	if err != nil {
-		return nil, nil, fmt.Errorf("retrieve payment: stripe: %w", err)
-	}
+		pi, err := s.stripe.Get(paymentIntentID, &stripe.PaymentIntentParams{Params: stripe.Params{Context: ctx}})
+		if err != nil {
+			return nil, fmt.Errorf("stripe: %w", err)
+		}
+		found, err := s.paymentRepo.FindByStripeID(model.ReadPrimary(ctx), pi.ID)
+		if err != nil {
+			return nil, fmt.Errorf("not found: %w", err)
+		}
+		found.PaymentStatus = string(pi.Status)
+		found.FenceToken = lock.Token()
+		updated, err := s.paymentRepo.UpdateStatus(ctx, found)
+		if err != nil {
+			return nil, fmt.Errorf("update status: %w", err)
+		}
 
-	payment, err := s.paymentRepo.FindByStripeID(pi.ID)
+		// Drop copies cached under other scopes so they do not serve the old status.
+		if other := tenantKey(updated.OrgID, key); other != cacheKey {
+			s.cache.Delete(ctx, other) //nolint:errcheck
+		}
+		if key != cacheKey {
+			s.cache.Delete(ctx, key) //nolint:errcheck
+		}
+		fetched <- pi
+		return model.Tagged{Value: updated, Tags: []string{userTag(updated.UserID)}}, nil
+	})
 	if err != nil {
-		return nil, nil, fmt.Errorf("retrieve payment: not found: %w", err)
+		return nil, nil, fmt.Errorf("retrieve payment: %w", err)
 	}
 
-	payment.PaymentStatus = string(pi.Status)
-	updated, err := s.paymentRepo.UpdateStatus(payment)
-	if err != nil {
-		return nil, nil, fmt.Errorf("retrieve payment: update status: %w", err)
+	var pi *stripe.PaymentIntent
+	select {
+	case pi = <-fetched:
+	default:
 	}
-
-	s.cache.Set(cacheKey, updated, 5*time.Minute) //nolint:errcheck
-	return updated, pi, nil
+	return &payment, pi, nil
 }
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"go-gin-project/internal/pkg/model"
+)
+
+// preferenceDefinition describes one allowed preference key.
+type preferenceDefinition struct {
+	Default string
+	Allowed []string
+}
+
+// preferenceSchema lists every preference key users may set, with its default
+// and permitted values. Unknown keys are rejected.
+var preferenceSchema = map[string]preferenceDefinition{
+	"theme":               {Default: "system", Allowed: []string{"light", "dark", "system"}},
+	"email_notifications": {Default: "true", Allowed: []string{"true", "false"}},
+	"marketing_emails":    {Default: "false", Allowed: []string{"true", "false"}},
+	"date_format":         {Default: "YYYY-MM-DD", Allowed: []string{"YYYY-MM-DD", "DD/MM/YYYY", "MM/DD/YYYY"}},
+	"currency":            {Default: "usd", Allowed: []string{"usd", "eur", "gbp", "jpy"}},
+}
+
+type PreferenceService struct {
+	repo model.PreferenceRepository
+}
+
+func NewPreferenceService(repo model.PreferenceRepository) *PreferenceService {
+	return &PreferenceService{repo: repo}
+}
+
+// Get returns every known preference for the user, filling unset keys with defaults.
+func (s *PreferenceService) Get(ctx context.Context, userID uint) (map[string]string, error) {
+	stored, err := s.repo.List(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get preferences: %w", err)
+	}
+	prefs := make(map[string]string, len(preferenceSchema))
+	for key, def := range preferenceSchema {
+		prefs[key] = def.Default
+		if v, ok := stored[key]; ok {
+			prefs[key] = v
+		}
+	}
+	return prefs, nil
+}
+
+// Update validates and stores the given preferences, then returns the full set.
+func (s *PreferenceService) Update(ctx context.Context, userID uint, prefs map[string]string) (map[string]string, error) {
+	if err := validatePreferences(prefs); err != nil {
+		return nil, fmt.Errorf("update preferences: %w", err)
+	}
+	if err := s.repo.Set(ctx, userID, prefs); err != nil {
+		return nil, fmt.Errorf("update preferences: %w", err)
+	}
+	// A replica may not have the rows just written yet.
+	return s.Get(model.ReadPrimary(ctx), userID)
+}
+
+func validatePreferences(prefs map[string]string) error {
+	var problems []string
+	for key, value := range prefs {
+		def, ok := preferenceSchema[key]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("unknown preference %q", key))
+			continue
+		}
+		if !slices.Contains(def.Allowed, value) {
+			problems = append(problems, fmt.Sprintf("%s must be one of %s", key, strings.Join(def.Allowed, ", ")))
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
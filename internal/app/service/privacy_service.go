@@ -0,0 +1,158 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go-gin-project/internal/pkg/model"
+)
+
+// PrivacyService answers data-subject requests: export and right-to-erasure.
+type PrivacyService struct {
+	userRepo    model.UserRepository
+	paymentRepo model.PaymentRepository
+	privacyRepo model.PrivacyRepository
+	prefRepo    model.PreferenceRepository
+	blobs       model.BlobStore
+	cache       model.CacheService
+	orgID       uint
+}
+
+func NewPrivacyService(
+	userRepo model.UserRepository,
+	paymentRepo model.PaymentRepository,
+	privacyRepo model.PrivacyRepository,
+	prefRepo model.PreferenceRepository,
+	blobs model.BlobStore,
+	cache model.CacheService,
+) *PrivacyService {
+	return &PrivacyService{
+		userRepo:    userRepo,
+		paymentRepo: paymentRepo,
+		privacyRepo: privacyRepo,
+		prefRepo:    prefRepo,
+		blobs:       blobs,
+		cache:       cache,
+	}
+}
+
+// ForTenant returns a PrivacyService restricted to members of orgID.
+func (s *PrivacyService) ForTenant(orgID uint) *PrivacyService {
+	return &PrivacyService{
+		userRepo:    s.userRepo.WithTenant(orgID),
+		paymentRepo: s.paymentRepo.WithTenant(orgID),
+		privacyRepo: s.privacyRepo,
+		prefRepo:    s.prefRepo,
+		blobs:       s.blobs,
+		cache:       s.cache,
+		orgID:       orgID,
+	}
+}
+
+// Export collects everything stored about the user. A tenant-scoped service
+// leaves out payments made in other organizations.
+func (s *PrivacyService) Export(ctx context.Context, id string) (*model.UserExport, error) {
+	user, err := s.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("export user: %w", err)
+	}
+	user.Password = ""
+
+	prefs, err := s.prefRepo.List(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("export user: %w", err)
+	}
+	payments, err := s.paymentRepo.FindByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("export user: %w", err)
+	}
+	memberships, err := s.privacyRepo.Memberships(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("export user: %w", err)
+	}
+	audit, err := s.privacyRepo.AuditLog(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("export user: %w", err)
+	}
+
+	return &model.UserExport{
+		User:        user,
+		Preferences: prefs,
+		Payments:    payments,
+		Sessions:    &model.SessionSummary{TokenVersion: user.TokenVersion, Memberships: memberships},
+		AuditLog:    audit,
+		ExportedAt:  time.Now().UTC(),
+	}, nil
+}
+
+// ExportZip packages the export as a ZIP archive with one JSON file per section.
+func (s *PrivacyService) ExportZip(ctx context.Context, id string) ([]byte, error) {
+	export, err := s.Export(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := []struct {
+		name    string
+		section interface{}
+	}{
+		{"user.json", export.User},
+		{"preferences.json", export.Preferences},
+		{"payments.json", export.Payments},
+		{"sessions.json", export.Sessions},
+		{"audit_log.json", export.AuditLog},
+	}
+	for _, f := range files {
+		w, err := zw.Create(f.name)
+		if err != nil {
+			return nil, fmt.Errorf("export user: zip %s: %w", f.name, err)
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(f.section); err != nil {
+			return nil, fmt.Errorf("export user: encode %s: %w", f.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("export user: zip close: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Erase anonymizes the user's PII, deletes their avatar, preferences, email
+// changes and invitations, keeps their payments and evicts cached copies.
+// Erasure reaches every organization, so a tenant-scoped service refuses
+// users who are also members of another one with ErrForbidden.
+func (s *PrivacyService) Erase(ctx context.Context, id string, requestedBy uint) (*model.ErasureReceipt, error) {
+	ctx = model.ReadPrimary(ctx)
+	user, err := s.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("erase user: %w", err)
+	}
+	if s.orgID != 0 {
+		memberships, err := s.privacyRepo.Memberships(ctx, user.ID)
+		if err != nil {
+			return nil, fmt.Errorf("erase user: %w", err)
+		}
+		for _, m := range memberships {
+			if m.OrgID != s.orgID {
+				return nil, fmt.Errorf("erase user: also a member of other organizations, remove them from this one instead: %w", ErrForbidden)
+			}
+		}
+	}
+
+	receipt, err := s.privacyRepo.Erase(ctx, id, requestedBy)
+	if err != nil {
+		return nil, fmt.Errorf("erase user: %w", err)
+	}
+
+	deleteAvatar(s.blobs, user.AvatarKey)
+	s.cache.InvalidateTag(ctx, userTag(id)) //nolint:errcheck
+	return receipt, nil
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"go-gin-project/internal/pkg/model"
+)
+
+var (
+	localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[A-Z][a-z]{3})?(-([A-Z]{2}|[0-9]{3}))?$`)
+	phonePattern  = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+)
+
+// validateProfile checks the optional profile fields; empty values are allowed.
+func validateProfile(u *model.User) error {
+	var problems []string
+	if u.Locale != "" && !localePattern.MatchString(u.Locale) {
+		problems = append(problems, "locale must be a BCP 47 tag such as en-US")
+	}
+	if u.Timezone != "" {
+		if _, err := time.LoadLocation(u.Timezone); err != nil || u.Timezone == "Local" {
+			problems = append(problems, "timezone must be an IANA zone such as Europe/Berlin")
+		}
+	}
+	if u.Phone != "" && !phonePattern.MatchString(u.Phone) {
+		problems = append(problems, "phone must be in E.164 format such as +14155550123")
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+package service
+
+import "fmt"
+
+// tenantKey namespaces a cache key by organization so an entry cached for one
+// tenant is never served to another. Unscoped (orgID 0) keys are unchanged.
+func tenantKey(orgID uint, key string) string {
+	if orgID == 0 {
+		return key
+	}
+	return fmt.Sprintf("org:%d:%s", orgID, key)
+}
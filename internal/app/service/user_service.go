@@ -1,55 +1,176 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"time"
+	"strconv"
 
 	"go-gin-project/internal/pkg/model"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
-type UserService struct {
-	repo  model.UserRepository
-	cache model.CacheService
+const minPasswordLength = 8
+
+// UserPatch carries a partial profile update; nil fields are left unchanged.
+type UserPatch struct {
+	Name        *string `json:"name"`
+	DisplayName *string `json:"display_name"`
+	Locale      *string `json:"locale"`
+	Timezone    *string `json:"timezone"`
+	Phone       *string `json:"phone"`
+}
+
+// ChangePasswordRequest replaces the caller's password after re-checking the current one.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
 }
 
-func NewUserService(repo model.UserRepository, cache model.CacheService) *UserService {
-	return &UserService{repo: repo, cache: cache}
+// cachedUser is how UserService caches a user. AvatarKey is hidden from JSON
+// on model.User but Decorate needs it; the password hash is never cached.
+type cachedUser struct {
+	User      *model.User `json:"user"`
+	AvatarKey string      `json:"avatar_key"`
 }
 
-func (s *UserService) Create(user *model.User) (*model.User, error) {
-	return s.repo.Create(user)
+type UserService struct {
+	repo     model.UserRepository
+	cache    model.LoadingCache
+	cacheTTL TTLFunc
+	orgID    uint
 }
 
-func (s *UserService) Get(id string) (*model.User, error) {
-	cacheKey := fmt.Sprintf("user:%s", id)
+// NewUserService creates a UserService caching users for cacheTTL.
+func NewUserService(repo model.UserRepository, cache model.LoadingCache, cacheTTL TTLFunc) *UserService {
+	return &UserService{repo: repo, cache: cache, cacheTTL: cacheTTL}
+}
 
-	var user model.User
-	if err := s.cache.Get(cacheKey, &user); err == nil {
-		return &user, nil
+// ForTenant returns a UserService restricted to members of orgID.
+func (s *UserService) ForTenant(orgID uint) *UserService {
+	return &UserService{repo: s.repo.WithTenant(orgID), cache: s.cache, cacheTTL: s.cacheTTL, orgID: orgID}
+}
+
+func (s *UserService) Create(ctx context.Context, user *model.User) (*model.User, error) {
+	if err := validateProfile(user); err != nil {
+		return nil, fmt.Errorf("create user: %w", err)
+	}
+	created, err := s.repo.Create(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	// The ID may have been looked up before it existed; drop any cached miss.
+	var orgIDs []uint
+	if s.orgID != 0 {
+		orgIDs = []uint{s.orgID}
 	}
+	evictUser(ctx, s.cache, strconv.FormatUint(uint64(created.ID), 10), orgIDs)
+	return created, nil
+}
 
-	found, err := s.repo.FindByID(id)
+func (s *UserService) Get(ctx context.Context, id string) (*model.User, error) {
+	cacheKey := tenantKey(s.orgID, fmt.Sprintf("user:%s", id))
+
+	var cached cachedUser
+	err := s.cache.GetOrLoad(ctx, cacheKey, &cached, s.cacheTTL(), func(ctx context.Context) (interface{}, error) {
+		// A lagging replica could refill the cache with a row an update
+		// just invalidated, and it would be served for the whole TTL.
+		user, err := s.repo.FindByID(model.ReadPrimary(ctx), id)
+		if err != nil {
+			return nil, err
+		}
+		user.Password = ""
+		return model.Tagged{Value: &cachedUser{User: user, AvatarKey: user.AvatarKey}, Tags: []string{userTag(id)}}, nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("get user: %w", err)
 	}
-
-	s.cache.Set(cacheKey, found, 5*time.Minute) //nolint:errcheck
-	return found, nil
+	cached.User.AvatarKey = cached.AvatarKey
+	return cached.User, nil
 }
 
-func (s *UserService) Update(id string, data *model.User) (*model.User, error) {
-	updated, err := s.repo.Update(id, data)
+func (s *UserService) Update(ctx context.Context, id string, data *model.User) (*model.User, error) {
+	if err := validateProfile(data); err != nil {
+		return nil, fmt.Errorf("update user: %w", err)
+	}
+	updated, err := s.repo.Update(ctx, id, data)
 	if err != nil {
 		return nil, fmt.Errorf("update user: %w", err)
 	}
-	s.cache.Delete(fmt.Sprintf("user:%s", id)) //nolint:errcheck
+	s.cache.InvalidateTag(ctx, userTag(id)) //nolint:errcheck
 	return updated, nil
 }
 
-func (s *UserService) Delete(id string) error {
-	if err := s.repo.Delete(id); err != nil {
+// Patch applies the non-nil fields of patch to the stored user.
+func (s *UserService) Patch(ctx context.Context, id string, patch *UserPatch) (*model.User, error) {
+	current, err := s.repo.FindByID(model.ReadPrimary(ctx), id)
+	if err != nil {
+		return nil, fmt.Errorf("patch user: %w", err)
+	}
+	data := *current
+	data.Password = ""
+	if patch.Name != nil {
+		data.Name = *patch.Name
+	}
+	if patch.DisplayName != nil {
+		data.DisplayName = *patch.DisplayName
+	}
+	if patch.Locale != nil {
+		data.Locale = *patch.Locale
+	}
+	if patch.Timezone != nil {
+		data.Timezone = *patch.Timezone
+	}
+	if patch.Phone != nil {
+		data.Phone = *patch.Phone
+	}
+	if data.Name == "" {
+		return nil, errors.New("patch user: name cannot be empty")
+	}
+	return s.Update(ctx, id, &data)
+}
+
+// ChangePassword sets a new password once the current one has been verified,
+// signing the user out of the sessions started with the old one.
+func (s *UserService) ChangePassword(ctx context.Context, id string, req *ChangePasswordRequest) error {
+	user, err := s.repo.FindByID(model.ReadPrimary(ctx), id)
+	if err != nil {
+		return fmt.Errorf("change password: %w", err)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+		return errors.New("change password: current password is incorrect")
+	}
+	if len(req.NewPassword) < minPasswordLength {
+		return fmt.Errorf("change password: password must be at least %d characters", minPasswordLength)
+	}
+	if err := s.repo.UpdatePassword(ctx, id, req.NewPassword); err != nil {
+		return fmt.Errorf("change password: %w", err)
+	}
+	s.cache.InvalidateTag(ctx, userTag(id)) //nolint:errcheck
+	return nil
+}
+
+func (s *UserService) Delete(ctx context.Context, id string) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
 		return fmt.Errorf("delete user: %w", err)
 	}
-	s.cache.Delete(fmt.Sprintf("user:%s", id)) //nolint:errcheck
+	s.cache.InvalidateTag(ctx, userTag(id)) //nolint:errcheck
 	return nil
 }
+
+// userTag labels every cache entry derived from a user, across tenants, so
+// one InvalidateTag call evicts them all.
+func userTag(id interface{}) string {
+	return fmt.Sprintf("user:%v", id)
+}
+
+// evictUser deletes the unscoped and per-tenant cache entries for a user. It
+// is needed for cached "not found" results, which carry no tags.
+func evictUser(ctx context.Context, cache model.CacheService, id string, orgIDs []uint) {
+	key := fmt.Sprintf("user:%s", id)
+	cache.Delete(ctx, key) //nolint:errcheck
+	for _, orgID := range orgIDs {
+		cache.Delete(ctx, tenantKey(orgID, key)) //nolint:errcheck
+	}
+}
@@ -0,0 +1,38 @@
+package blobstore
+
+import (
+	"crypto/rand"
+	"fmt"
+	"log/slog"
+
+	"go-gin-project/internal/pkg/model"
+)
+
+// Options selects and configures a blob store.
+type Options struct {
+	Store      string // "local" (default) or "s3"
+	LocalDir   string
+	BaseURL    string // where the local store's handler is mounted, e.g. http://host/blobs
+	SigningKey []byte // local store; a random key is generated if empty
+	S3         S3Config
+}
+
+// New creates the model.BlobStore selected by opts.Store.
+func New(opts Options) (model.BlobStore, error) {
+	switch opts.Store {
+	case "", "local":
+		key := opts.SigningKey
+		if len(key) == 0 {
+			key = make([]byte, 32)
+			if _, err := rand.Read(key); err != nil {
+				return nil, fmt.Errorf("blob signing key: %w", err)
+			}
+			slog.Warn("no blob signing key is set, signed blob URLs will not survive a restart")
+		}
+		return NewLocal(opts.LocalDir, opts.BaseURL, key)
+	case "s3":
+		return NewS3(opts.S3)
+	default:
+		return nil, fmt.Errorf("unknown blob store %q", opts.Store)
+	}
+}
@@ -0,0 +1,36 @@
+package broker
+
+import (
+	"context"
+	"sync"
+
+	"go-gin-project/internal/pkg/model"
+)
+
+// Memory is a model.EventBroker that keeps published events in memory; for
+// tests.
+type Memory struct {
+	mu     sync.Mutex
+	events []model.Event
+}
+
+var _ model.EventBroker = (*Memory)(nil)
+
+// NewMemory creates an empty Memory broker.
+func NewMemory() *Memory {
+	return &Memory{}
+}
+
+func (m *Memory) Publish(_ context.Context, event *model.Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, *event)
+	return nil
+}
+
+// Events returns the events published so far, in order.
+func (m *Memory) Events() []model.Event {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]model.Event(nil), m.events...)
+}
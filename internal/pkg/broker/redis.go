@@ -0,0 +1,51 @@
+// Package broker implements model.EventBroker on Redis Streams and in memory.
+package broker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-gin-project/internal/pkg/cache"
+	"go-gin-project/internal/pkg/model"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type redisStreams struct {
+	client redis.UniversalClient
+	prefix string
+	maxLen int64
+}
+
+// NewRedisStreams creates a model.EventBroker that appends each event to the
+// Redis stream "<key prefix>events:<aggregate type>", trimmed to roughly
+// maxLen entries; zero keeps them all. Entries have the fields id, type,
+// aggregate_type, aggregate_id, payload (JSON) and created_at (RFC 3339).
+func NewRedisStreams(opts cache.RedisOptions, maxLen int) (model.EventBroker, error) {
+	client, err := cache.NewRedisClient(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &redisStreams{client: client, prefix: opts.KeyPrefix, maxLen: int64(maxLen)}, nil
+}
+
+func (b *redisStreams) Publish(ctx context.Context, event *model.Event) error {
+	err := b.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: b.prefix + "events:" + event.AggregateType,
+		MaxLen: b.maxLen,
+		Approx: true,
+		Values: []interface{}{
+			"id", event.ID,
+			"type", event.Type,
+			"aggregate_type", event.AggregateType,
+			"aggregate_id", event.AggregateID,
+			"payload", string(event.Payload),
+			"created_at", event.CreatedAt.UTC().Format(time.RFC3339Nano),
+		},
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("publish event %d: %w", event.ID, err)
+	}
+	return nil
+}
@@ -2,9 +2,8 @@ package cache
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
 	"time"
 
 	"go-gin-project/internal/pkg/model"
@@ -12,45 +11,202 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+// ErrMiss is returned by Get when the key is absent or expired.
+var ErrMiss = errors.New("cache: miss")
+
+// entryKeyPrefix namespaces cache entries and tag sets, so Purge can clear
+// them without touching anything else stored in the same Redis.
+const entryKeyPrefix = "cache:"
+
+// tagKeyPrefix namespaces, within the cache, the Redis sets that list the keys
+// stored under a tag.
+const tagKeyPrefix = "tag:"
+
+// lockKeyPrefix and rateLimitKeyPrefix namespace the keys of the Redis locker
+// and rate limiter, which share the cache's Redis but are not cache entries.
+const (
+	lockKeyPrefix      = "lock:"
+	rateLimitKeyPrefix = "ratelimit:"
+)
+
+// purgeBatch is how many keys Purge scans per round trip.
+const purgeBatch = 1000
+
+// tagBatch is how many keys InvalidateTag pops from a tag set per round trip.
+const tagBatch = 500
+
+// addTagScript adds ARGV[1] to the tag set KEYS[1] and keeps the set alive as long
+// as its longest-lived member (ARGV[2] is that member's TTL in ms, 0 for none).
+// It touches a single key so it is safe under Redis Cluster.
+const addTagScript = `
+redis.call('SADD', KEYS[1], ARGV[1])
+local ttl = tonumber(ARGV[2])
+if ttl == 0 then
+	redis.call('PERSIST', KEYS[1])
+	return 1
+end
+local current = redis.call('PTTL', KEYS[1])
+if (current == -1 and redis.call('SCARD', KEYS[1]) == 1) or (current >= 0 and current < ttl) then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+end
+return 1
+`
+
 type redisCache struct {
-	client *redis.Client
-	ctx    context.Context
+	client redis.UniversalClient
+	prefix string
+	codec  *Serializer
 }
 
 // New creates a Redis-backed model.CacheService.
-func New() (model.CacheService, error) {
-	client := redis.NewClient(&redis.Options{
-		Addr:     os.Getenv("REDIS_HOST") + ":" + os.Getenv("REDIS_PORT"),
-		Password: os.Getenv("REDIS_PASSWORD"),
-		DB:       0,
-	})
+func New(opts RedisOptions) (model.CacheService, error) {
+	return newRedis(opts)
+}
 
-	ctx := context.Background()
-	if err := client.Ping(ctx).Err(); err != nil {
+func newRedis(opts RedisOptions) (*redisCache, error) {
+	client, err := opts.client()
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		client.Close() //nolint:errcheck
 		return nil, fmt.Errorf("redis connect: %w", err)
 	}
-
-	return &redisCache{client: client, ctx: ctx}, nil
+	return &redisCache{
+		client: client,
+		prefix: opts.KeyPrefix,
+		codec:  NewSerializer(opts.Codec, opts.Compression, opts.CompressThreshold),
+	}, nil
 }
 
-func (c *redisCache) Get(key string, dest interface{}) error {
-	val, err := c.client.Get(c.ctx, key).Result()
+func (c *redisCache) Get(ctx context.Context, key string, dest interface{}) error {
+	val, err := c.client.Get(ctx, c.entryKey(key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return ErrMiss
+	}
 	if err != nil {
 		return err
 	}
-	return json.Unmarshal([]byte(val), dest)
+	return c.codec.Decode(val, dest)
 }
 
-func (c *redisCache) Set(key string, value interface{}, expiration time.Duration) error {
-	data, err := json.Marshal(value)
+func (c *redisCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration, tags ...string) error {
+	data, err := c.codec.Encode(value)
 	if err != nil {
-		return fmt.Errorf("cache set marshal: %w", err)
+		return fmt.Errorf("cache set: %w", err)
+	}
+	if len(tags) == 0 {
+		return c.client.Set(ctx, c.entryKey(key), data, expiration).Err()
 	}
-	return c.client.Set(c.ctx, key, data, expiration).Err()
+	_, err = c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, c.entryKey(key), data, expiration)
+		for _, tag := range tags {
+			pipe.Eval(ctx, addTagScript, []string{c.tagKey(tag)}, key, expiration.Milliseconds())
+		}
+		return nil
+	})
+	return err
+}
+
+func (c *redisCache) Delete(ctx context.Context, key string) error {
+	return c.client.Del(ctx, c.entryKey(key)).Err()
+}
+
+func (c *redisCache) InvalidateTag(ctx context.Context, tag string) error {
+	_, err := c.invalidateTag(ctx, tag)
+	return err
+}
+
+// invalidateTag pops the tag's keys in batches and deletes them, returning
+// the keys removed. Popping rather than reading the whole set means keys
+// tagged concurrently are either deleted here or left for the next call.
+// Keys are deleted one command each as they may live on different cluster slots.
+func (c *redisCache) invalidateTag(ctx context.Context, tag string) ([]string, error) {
+	var removed []string
+	for {
+		keys, err := c.client.SPopN(ctx, c.tagKey(tag), tagBatch).Result()
+		if err != nil {
+			return removed, fmt.Errorf("cache invalidate tag %q: %w", tag, err)
+		}
+		if len(keys) == 0 {
+			return removed, nil
+		}
+		_, err = c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, key := range keys {
+				pipe.Del(ctx, c.entryKey(key))
+			}
+			return nil
+		})
+		if err != nil {
+			return removed, fmt.Errorf("cache invalidate tag %q: %w", tag, err)
+		}
+		removed = append(removed, keys...)
+	}
+}
+
+func (c *redisCache) entryKey(key string) string {
+	return c.prefix + entryKeyPrefix + key
+}
+
+func (c *redisCache) tagKey(tag string) string {
+	return c.prefix + entryKeyPrefix + tagKeyPrefix + tag
+}
+
+// Purge deletes every cache entry and tag set, on every master of a cluster.
+// Only the cache namespace is scanned; locks, rate-limit counters, event
+// streams and unrelated keys in the same database are left alone.
+func (c *redisCache) Purge(ctx context.Context) error {
+	if cluster, ok := c.client.(*redis.ClusterClient); ok {
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			return c.purgeNode(ctx, node)
+		})
+	}
+	return c.purgeNode(ctx, c.client)
+}
+
+func (c *redisCache) purgeNode(ctx context.Context, node redis.Cmdable) error {
+	var cursor uint64
+	for {
+		keys, next, err := node.Scan(ctx, cursor, c.prefix+entryKeyPrefix+"*", purgeBatch).Result()
+		if err != nil {
+			return fmt.Errorf("cache purge: %w", err)
+		}
+		if len(keys) > 0 {
+			_, err = node.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+				for _, key := range keys {
+					pipe.Del(ctx, key)
+				}
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("cache purge: %w", err)
+			}
+		}
+		if cursor = next; cursor == 0 {
+			return nil
+		}
+	}
+}
+
+// Ping reports whether Redis is reachable.
+func (c *redisCache) Ping() error {
+	return c.client.Ping(context.Background()).Err()
+}
+
+func (c *redisCache) Close() error {
+	return c.client.Close()
 }
 
-func (c *redisCache) Delete(key string) error {
-	return c.client.Del(c.ctx, key).Err()
+func (c *redisCache) PoolStats() (PoolStats, bool) {
+	s := c.client.PoolStats()
+	return PoolStats{
+		Hits:       s.Hits,
+		Misses:     s.Misses,
+		Timeouts:   s.Timeouts,
+		TotalConns: s.TotalConns,
+		IdleConns:  s.IdleConns,
+		StaleConns: s.StaleConns,
+	}, true
 }
 
 var _ model.CacheService = (*redisCache)(nil) // compile-time interface check
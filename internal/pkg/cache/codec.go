@@ -0,0 +1,214 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+// formatVersion is the first byte of every value written by a Serializer.
+// Values without it are pre-versioning JSON, which never starts with 0x01.
+const formatVersion byte = 1
+
+// headerLen is the version, codec and compression bytes.
+const headerLen = 3
+
+// DefaultCompressThreshold is the payload size above which values are compressed.
+const DefaultCompressThreshold = 1024
+
+// Codec converts cached values to and from bytes.
+type Codec interface {
+	// ID identifies the codec in the value header; it must never be reused.
+	ID() byte
+	Name() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// Compression identifies how a value's payload is compressed.
+type Compression byte
+
+const (
+	CompressionNone Compression = iota
+	CompressionZstd
+	CompressionSnappy
+)
+
+var (
+	// JSON is the default codec and the format of unversioned values.
+	JSON Codec = jsonCodec{}
+	// MessagePack is more compact than JSON and keeps time.Time at nanosecond precision.
+	MessagePack Codec = msgpackCodec{}
+	// Protobuf encodes proto.Message values; anything else is written with JSON.
+	Protobuf Codec = protoCodec{}
+)
+
+var codecs = map[byte]Codec{JSON.ID(): JSON, MessagePack.ID(): MessagePack, Protobuf.ID(): Protobuf}
+
+// CodecByName returns the built-in codec called name ("json", "msgpack" or "protobuf").
+func CodecByName(name string) (Codec, error) {
+	for _, c := range codecs {
+		if c.Name() == name {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("cache: unknown codec %q", name)
+}
+
+// CompressionByName parses "none", "zstd" or "snappy"; "" means none.
+func CompressionByName(name string) (Compression, error) {
+	switch name {
+	case "", "none":
+		return CompressionNone, nil
+	case "zstd":
+		return CompressionZstd, nil
+	case "snappy":
+		return CompressionSnappy, nil
+	}
+	return CompressionNone, fmt.Errorf("cache: unknown compression %q", name)
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) ID() byte                                   { return 1 }
+func (jsonCodec) Name() string                               { return "json" }
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) ID() byte                                   { return 2 }
+func (msgpackCodec) Name() string                               { return "msgpack" }
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error)      { return msgpack.Marshal(v) }
+func (msgpackCodec) Unmarshal(data []byte, v interface{}) error { return msgpack.Unmarshal(data, v) }
+
+// errNotProto makes the Serializer fall back to JSON for non-protobuf values.
+var errNotProto = errors.New("cache: value is not a proto.Message")
+
+type protoCodec struct{}
+
+func (protoCodec) ID() byte     { return 3 }
+func (protoCodec) Name() string { return "protobuf" }
+
+func (protoCodec) Marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, errNotProto
+	}
+	return proto.Marshal(m)
+}
+
+func (protoCodec) Unmarshal(data []byte, v interface{}) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return errNotProto
+	}
+	return proto.Unmarshal(data, m)
+}
+
+// Serializer frames values as [version, codec, compression, payload...].
+// Decoding follows the header rather than the configured codec, so the codec
+// or compression can change between deploys without flushing Redis.
+type Serializer struct {
+	codec       Codec
+	compression Compression
+	threshold   int
+}
+
+// NewSerializer encodes with codec and compresses payloads longer than
+// threshold bytes (DefaultCompressThreshold if zero). A nil codec means JSON.
+func NewSerializer(codec Codec, compression Compression, threshold int) *Serializer {
+	if codec == nil {
+		codec = JSON
+	}
+	if threshold == 0 {
+		threshold = DefaultCompressThreshold
+	}
+	return &Serializer{codec: codec, compression: compression, threshold: threshold}
+}
+
+func (s *Serializer) Encode(v interface{}) ([]byte, error) {
+	codec := s.codec
+	payload, err := codec.Marshal(v)
+	if errors.Is(err, errNotProto) {
+		codec = JSON
+		payload, err = codec.Marshal(v)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cache encode %s: %w", codec.Name(), err)
+	}
+
+	compression := CompressionNone
+	if s.compression != CompressionNone && len(payload) > s.threshold {
+		compression = s.compression
+		if payload, err = compress(compression, payload); err != nil {
+			return nil, err
+		}
+	}
+
+	out := make([]byte, 0, headerLen+len(payload))
+	out = append(out, formatVersion, codec.ID(), byte(compression))
+	return append(out, payload...), nil
+}
+
+func (s *Serializer) Decode(data []byte, v interface{}) error {
+	if len(data) == 0 || data[0] != formatVersion {
+		return json.Unmarshal(data, v)
+	}
+	if len(data) < headerLen {
+		return errors.New("cache decode: truncated header")
+	}
+	codec, ok := codecs[data[1]]
+	if !ok {
+		return fmt.Errorf("cache decode: unknown codec %d", data[1])
+	}
+	payload, err := decompress(Compression(data[2]), data[headerLen:])
+	if err != nil {
+		return err
+	}
+	if err := codec.Unmarshal(payload, v); err != nil {
+		return fmt.Errorf("cache decode %s: %w", codec.Name(), err)
+	}
+	return nil
+}
+
+// zstd encoders and decoders are safe for concurrent EncodeAll/DecodeAll calls.
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+func compress(c Compression, data []byte) ([]byte, error) {
+	switch c {
+	case CompressionZstd:
+		return zstdEncoder.EncodeAll(data, nil), nil
+	case CompressionSnappy:
+		return snappy.Encode(nil, data), nil
+	}
+	return nil, fmt.Errorf("cache encode: unknown compression %d", c)
+}
+
+func decompress(c Compression, data []byte) ([]byte, error) {
+	switch c {
+	case CompressionNone:
+		return data, nil
+	case CompressionZstd:
+		out, err := zstdDecoder.DecodeAll(data, nil)
+		if err != nil {
+			return nil, fmt.Errorf("cache decode zstd: %w", err)
+		}
+		return out, nil
+	case CompressionSnappy:
+		out, err := snappy.Decode(nil, data)
+		if err != nil {
+			return nil, fmt.Errorf("cache decode snappy: %w", err)
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("cache decode: unknown compression %d", c)
+}
@@ -0,0 +1,250 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go-gin-project/internal/pkg/model"
+)
+
+// maxPendingEvictions bounds how many evictions are remembered while the primary is down.
+const maxPendingEvictions = 10000
+
+// eviction is a delete of a key, or of every key under a tag, owed to the primary.
+type eviction struct {
+	tag  bool
+	name string
+}
+
+func (e eviction) apply(ctx context.Context, c model.CacheService) error {
+	if e.tag {
+		return c.InvalidateTag(ctx, e.name)
+	}
+	return c.Delete(ctx, e.name)
+}
+
+// pinger is implemented by caches that can report their own health.
+type pinger interface {
+	Ping() error
+}
+
+// purger is implemented by caches that can drop all of their entries.
+type purger interface {
+	Purge(ctx context.Context) error
+}
+
+type fallbackCache struct {
+	connect func() (model.CacheService, error)
+	local   model.CacheService
+	retry   time.Duration
+
+	mu           sync.RWMutex
+	primary      model.CacheService // nil while unavailable
+	pending      map[eviction]struct{}
+	purge        bool // more evictions were missed than pending can hold
+	reconnecting bool
+}
+
+// NewFallback creates a model.CacheService that uses the cache returned by
+// connect and switches to local whenever it is unreachable. While degraded it
+// retries connect every retry interval; deletes and tag invalidations made in
+// the meantime are replayed against the primary once it is back so it does
+// not serve stale data. If there were too many to remember, the primary is
+// purged instead.
+func NewFallback(connect func() (model.CacheService, error), local model.CacheService, retry time.Duration) model.CacheService {
+	c := &fallbackCache{connect: connect, local: local, retry: retry, pending: map[eviction]struct{}{}}
+	primary, err := connect()
+	if err != nil {
+		slog.Warn("cache unavailable, using in-memory fallback", "err", err)
+		c.degrade(nil)
+		return c
+	}
+	c.primary = primary
+	return c
+}
+
+func (c *fallbackCache) Get(ctx context.Context, key string, dest interface{}) error {
+	primary := c.current()
+	if primary == nil {
+		return c.local.Get(ctx, key, dest)
+	}
+	err := primary.Get(ctx, key, dest)
+	if err != nil && !errors.Is(err, ErrMiss) && isDown(ctx, primary) {
+		c.degrade(primary)
+		return c.local.Get(ctx, key, dest)
+	}
+	return err
+}
+
+func (c *fallbackCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration, tags ...string) error {
+	primary := c.current()
+	if primary == nil {
+		return c.local.Set(ctx, key, value, expiration, tags...)
+	}
+	err := primary.Set(ctx, key, value, expiration, tags...)
+	if err != nil && isDown(ctx, primary) {
+		c.degrade(primary)
+		return c.local.Set(ctx, key, value, expiration, tags...)
+	}
+	return err
+}
+
+// Delete always clears the local copy too, so entries written during an
+// outage cannot resurface after a later one.
+func (c *fallbackCache) Delete(ctx context.Context, key string) error {
+	c.local.Delete(ctx, key) //nolint:errcheck
+	return c.evict(ctx, eviction{name: key})
+}
+
+func (c *fallbackCache) InvalidateTag(ctx context.Context, tag string) error {
+	c.local.InvalidateTag(ctx, tag) //nolint:errcheck
+	return c.evict(ctx, eviction{tag: true, name: tag})
+}
+
+func (c *fallbackCache) evict(ctx context.Context, e eviction) error {
+	primary := c.current()
+	if primary == nil {
+		c.remember(e)
+		return nil
+	}
+	err := e.apply(ctx, primary)
+	if err != nil && isDown(ctx, primary) {
+		c.degrade(primary)
+		c.remember(e)
+		return nil
+	}
+	return err
+}
+
+// PoolStats reports the primary's pool; ok is false while degraded.
+func (c *fallbackCache) PoolStats() (PoolStats, bool) {
+	return Stats(c.current())
+}
+
+func (c *fallbackCache) current() model.CacheService {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.primary
+}
+
+func (c *fallbackCache) remember(e eviction) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rememberLocked(e)
+}
+
+// rememberLocked records e, or once too many are pending, that the primary
+// must be purged, which covers every eviction.
+func (c *fallbackCache) rememberLocked(e eviction) {
+	if c.purge {
+		return
+	}
+	if len(c.pending) >= maxPendingEvictions {
+		c.purge = true
+		c.pending = map[eviction]struct{}{}
+		return
+	}
+	c.pending[e] = struct{}{}
+}
+
+// degrade drops the failed primary and starts the reconnect loop if it is not running.
+func (c *fallbackCache) degrade(failed model.CacheService) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if failed != nil && c.primary == failed {
+		slog.Warn("cache unreachable, switching to in-memory fallback")
+		c.primary = nil
+		if closer, ok := failed.(io.Closer); ok {
+			closer.Close() //nolint:errcheck
+		}
+	}
+	if !c.reconnecting {
+		c.reconnecting = true
+		go c.reconnect()
+	}
+}
+
+func (c *fallbackCache) reconnect() {
+	ticker := time.NewTicker(c.retry)
+	defer ticker.Stop()
+	for range ticker.C {
+		primary, err := c.connect()
+		if err != nil {
+			continue
+		}
+		if err := c.catchUp(context.Background(), primary); err != nil {
+			slog.Warn("cache reconnected but catching up failed, staying on in-memory fallback", "err", err)
+			if closer, ok := primary.(io.Closer); ok {
+				closer.Close() //nolint:errcheck
+			}
+			continue
+		}
+		slog.Info("cache reconnected, leaving in-memory fallback")
+		return
+	}
+}
+
+// catchUp applies the evictions owed to primary, without holding c.mu during
+// the calls, and switches to primary once nothing more is owed. Evictions
+// made meanwhile are owed too, so it repeats until none are left; on failure
+// whatever was not applied stays owed.
+func (c *fallbackCache) catchUp(ctx context.Context, primary model.CacheService) error {
+	for {
+		c.mu.Lock()
+		pending, purge := c.pending, c.purge
+		if len(pending) == 0 && !purge {
+			c.primary = primary
+			c.reconnecting = false
+			c.mu.Unlock()
+			return nil
+		}
+		c.pending, c.purge = map[eviction]struct{}{}, false
+		c.mu.Unlock()
+
+		if purge {
+			if err := purgeAll(ctx, primary); err != nil {
+				c.mu.Lock()
+				c.purge = true
+				c.pending = map[eviction]struct{}{}
+				c.mu.Unlock()
+				return err
+			}
+			continue
+		}
+		for e := range pending {
+			if err := e.apply(ctx, primary); err != nil {
+				c.mu.Lock()
+				for e := range pending {
+					c.rememberLocked(e)
+				}
+				c.mu.Unlock()
+				return err
+			}
+		}
+	}
+}
+
+func purgeAll(ctx context.Context, c model.CacheService) error {
+	p, ok := c.(purger)
+	if !ok {
+		slog.Warn("cache cannot be purged and may serve entries evicted while it was unreachable")
+		return nil
+	}
+	return p.Purge(ctx)
+}
+
+// isDown reports whether a failed call was the cache's fault rather than the
+// caller's cancelled or expired context.
+func isDown(ctx context.Context, c model.CacheService) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	p, ok := c.(pinger)
+	return !ok || p.Ping() != nil
+}
+
+var _ model.CacheService = (*fallbackCache)(nil) // compile-time interface check
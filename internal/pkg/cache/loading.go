@@ -0,0 +1,249 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"go-gin-project/internal/pkg/model"
+)
+
+// LoadingOptions tunes NewLoading; zero fields take the defaults noted.
+type LoadingOptions struct {
+	// StaleFor is how long past its TTL a value is still served while one
+	// caller refreshes it in the background. Default 1 minute.
+	StaleFor time.Duration
+	// NegativeTTL is how long a not-found result is remembered. Default 30 seconds.
+	NegativeTTL time.Duration
+	// Jitter spreads TTLs by up to this fraction either way so keys written
+	// together do not expire together. Default 0.1.
+	Jitter float64
+	// Codec encodes loaded values inside the envelope; pass the inner cache's
+	// codec so cached models get its encoding too. Default JSON.
+	Codec Codec
+	// LoadTimeout bounds a load, which runs detached from the caller that
+	// started it so that other callers waiting on it are not cancelled with
+	// it. Default 30 seconds.
+	LoadTimeout time.Duration
+}
+
+// envelope is what GetOrLoad stores under a key. Value holds the loaded value
+// framed by the Serializer, so it decodes whichever codec wrote it.
+type envelope struct {
+	Value      []byte `json:"v,omitempty"`
+	Missing    bool   `json:"m,omitempty"`
+	FreshUntil int64  `json:"f"` // unix nanoseconds
+}
+
+type flight struct {
+	done chan struct{}
+	data []byte
+	err  error
+	// invalidated is set, under loadingCache.mu, when the key is written,
+	// deleted or possibly tag-invalidated while the load runs; its result
+	// then goes to the callers already waiting but is not cached.
+	invalidated bool
+}
+
+type loadingCache struct {
+	model.CacheService
+	opts       LoadingOptions
+	serializer *Serializer
+
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+// NewLoading wraps inner with GetOrLoad. Keys read through GetOrLoad hold an
+// envelope rather than the bare value, so they should not also be read with Get.
+func NewLoading(inner model.CacheService, opts LoadingOptions) model.LoadingCache {
+	if opts.StaleFor == 0 {
+		opts.StaleFor = time.Minute
+	}
+	if opts.NegativeTTL == 0 {
+		opts.NegativeTTL = 30 * time.Second
+	}
+	if opts.Jitter == 0 {
+		opts.Jitter = 0.1
+	}
+	if opts.LoadTimeout == 0 {
+		opts.LoadTimeout = 30 * time.Second
+	}
+	return &loadingCache{
+		CacheService: inner,
+		opts:         opts,
+		// The inner cache compresses the whole envelope; compressing here too
+		// would only cost CPU.
+		serializer: NewSerializer(opts.Codec, CompressionNone, 0),
+		flights:    map[string]*flight{},
+	}
+}
+
+func (c *loadingCache) GetOrLoad(ctx context.Context, key string, dest interface{}, ttl time.Duration, load func(ctx context.Context) (interface{}, error)) error {
+	var env envelope
+	// An envelope with neither a value nor Missing was not written by
+	// GetOrLoad (e.g. a bare value under the same key): reload it.
+	if err := c.CacheService.Get(ctx, key, &env); err == nil && (env.Missing || env.Value != nil) {
+		if env.Missing {
+			return model.ErrNotFound
+		}
+		if time.Now().UnixNano() >= env.FreshUntil {
+			// Serve the stale value; only the first caller to notice refreshes it.
+			if f, leader := c.begin(key); leader {
+				go func() {
+					c.run(ctx, f, key, ttl, load)
+					if f.err != nil && !errors.Is(f.err, model.ErrNotFound) {
+						slog.Warn("cache refresh", "key", key, "err", f.err)
+					}
+				}()
+			}
+		}
+		return c.serializer.Decode(env.Value, dest)
+	}
+
+	// The leader waits like everyone else, so that it can give up when its
+	// ctx is done while the load carries on for the others.
+	f, leader := c.begin(key)
+	if leader {
+		go c.run(ctx, f, key, ttl, load)
+	}
+	select {
+	case <-f.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if f.err != nil {
+		return f.err
+	}
+	return c.serializer.Decode(f.data, dest)
+}
+
+// begin returns the in-flight load for key, registering a new one if there is
+// none; leader reports whether the caller must run it.
+func (c *loadingCache) begin(key string) (f *flight, leader bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if f, ok := c.flights[key]; ok {
+		return f, false
+	}
+	f = &flight{done: make(chan struct{})}
+	c.flights[key] = f
+	return f, true
+}
+
+// run calls load, caches its outcome and releases everyone waiting on f. The
+// load is detached from ctx's cancellation, since other callers may be
+// waiting on it, and bounded by LoadTimeout instead.
+func (c *loadingCache) run(ctx context.Context, f *flight, key string, ttl time.Duration, load func(ctx context.Context) (interface{}, error)) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.opts.LoadTimeout)
+	defer cancel()
+	defer func() {
+		c.mu.Lock()
+		if c.flights[key] == f {
+			delete(c.flights, key)
+		}
+		c.mu.Unlock()
+		close(f.done)
+	}()
+
+	value, err := load(ctx)
+	var tags []string
+	if t, ok := value.(model.Tagged); ok {
+		value, tags = t.Value, t.Tags
+	}
+	if errors.Is(err, model.ErrNotFound) {
+		c.store(ctx, f, key, envelope{Missing: true}, c.opts.NegativeTTL)
+	}
+	if err != nil {
+		f.err = err
+		return
+	}
+	data, err := c.serializer.Encode(value)
+	if err != nil {
+		f.err = fmt.Errorf("cache load: %w", err)
+		return
+	}
+	f.data = data
+	c.store(ctx, f, key, envelope{Value: data}, c.jitter(ttl), tags...)
+}
+
+// store writes env with ttl of freshness plus the stale window for values,
+// unless f was invalidated while loading it. An invalidation that lands
+// while the write is in progress removes the key again afterwards.
+func (c *loadingCache) store(ctx context.Context, f *flight, key string, env envelope, ttl time.Duration, tags ...string) {
+	if c.invalidated(f) {
+		return
+	}
+	env.FreshUntil = time.Now().Add(ttl).UnixNano()
+	if !env.Missing {
+		ttl += c.opts.StaleFor
+	}
+	if err := c.CacheService.Set(ctx, key, env, ttl, tags...); err != nil {
+		slog.Warn("cache store", "key", key, "err", err)
+		return
+	}
+	if c.invalidated(f) {
+		if err := c.CacheService.Delete(ctx, key); err != nil {
+			slog.Warn("cache store", "key", key, "err", err)
+		}
+	}
+}
+
+func (c *loadingCache) invalidated(f *flight) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return f.invalidated
+}
+
+// invalidate marks the in-flight loads of keys, or of every key if keys is
+// empty, so that their results are not cached, and lets the next caller
+// start a fresh load.
+func (c *loadingCache) invalidate(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(keys) == 0 {
+		for key, f := range c.flights {
+			f.invalidated = true
+			delete(c.flights, key)
+		}
+		return
+	}
+	for _, key := range keys {
+		if f, ok := c.flights[key]; ok {
+			f.invalidated = true
+			delete(c.flights, key)
+		}
+	}
+}
+
+func (c *loadingCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration, tags ...string) error {
+	c.invalidate(key)
+	return c.CacheService.Set(ctx, key, value, expiration, tags...)
+}
+
+func (c *loadingCache) Delete(ctx context.Context, key string) error {
+	c.invalidate(key)
+	return c.CacheService.Delete(ctx, key)
+}
+
+// InvalidateTag cannot tell which in-flight loads the tag will cover, since
+// loaders choose their tags, so it invalidates all of them.
+func (c *loadingCache) InvalidateTag(ctx context.Context, tag string) error {
+	c.invalidate()
+	return c.CacheService.InvalidateTag(ctx, tag)
+}
+
+func (c *loadingCache) jitter(ttl time.Duration) time.Duration {
+	spread := (rand.Float64()*2 - 1) * c.opts.Jitter
+	return ttl + time.Duration(float64(ttl)*spread)
+}
+
+func (c *loadingCache) PoolStats() (PoolStats, bool) {
+	return Stats(c.CacheService)
+}
+
+var _ model.LoadingCache = (*loadingCache)(nil) // compile-time interface check
@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"strconv"
+	"sync"
+	"time"
+
+	"go-gin-project/internal/pkg/model"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// lockRetry is the mean wait between attempts to take a busy lock.
+const lockRetry = 25 * time.Millisecond
+
+// obtainScript takes KEYS[1] for ARGV[1] ms if it is free, storing a fencing
+// token drawn from the counter KEYS[2]; it returns the token, or 0 if busy.
+// A missing counter is seeded from the server clock in microseconds, so tokens
+// keep growing if Redis loses the counter or the app fell back to the memory
+// locker meanwhile. Both keys share a hash tag so the script runs under Cluster.
+const obtainScript = `
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return 0
+end
+if redis.call('EXISTS', KEYS[2]) == 0 then
+	local now = redis.call('TIME')
+	redis.call('SET', KEYS[2], now[1] .. string.format('%06d', tonumber(now[2])))
+end
+local token = redis.call('INCR', KEYS[2])
+redis.call('SET', KEYS[1], token, 'PX', ARGV[1])
+return token
+`
+
+// releaseScript deletes KEYS[1] only if it still holds token ARGV[1].
+const releaseScript = `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`
+
+type redisLocker struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewLocker creates a Redis-backed model.Locker.
+func NewLocker(opts RedisOptions) (model.Locker, error) {
+	c, err := newRedis(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &redisLocker{client: c.client, prefix: c.prefix}, nil
+}
+
+func (l *redisLocker) Obtain(ctx context.Context, key string, ttl time.Duration) (model.Lock, error) {
+	lockKey := l.prefix + lockKeyPrefix + "{" + key + "}"
+	fenceKey := lockKey + ":fence"
+	return obtain(ctx, key, func() (model.Lock, error) {
+		token, err := l.client.Eval(ctx, obtainScript, []string{lockKey, fenceKey}, ttl.Milliseconds()).Int64()
+		if err != nil || token == 0 {
+			return nil, err
+		}
+		return &redisLock{client: l.client, key: lockKey, token: token}, nil
+	})
+}
+
+type redisLock struct {
+	client redis.UniversalClient
+	key    string
+	token  int64
+}
+
+func (l *redisLock) Token() int64 { return l.token }
+
+func (l *redisLock) Release(ctx context.Context) error {
+	n, err := l.client.Eval(ctx, releaseScript, []string{l.key}, strconv.FormatInt(l.token, 10)).Int64()
+	if err != nil {
+		return fmt.Errorf("release lock: %w", err)
+	}
+	if n == 0 {
+		return model.ErrLockNotHeld
+	}
+	return nil
+}
+
+type fallbackLocker struct {
+	primary model.Locker
+	local   model.Locker
+	retry   time.Duration
+
+	mu        sync.Mutex
+	downUntil time.Time
+}
+
+// NewFallbackLocker creates a model.Locker that uses primary and switches to
+// local for retry whenever primary fails, so an unreachable Redis does not
+// fail every locked operation. Meanwhile locks only exclude callers within
+// this instance; the fencing tokens still reject writes from holders that
+// lost their lock.
+func NewFallbackLocker(primary, local model.Locker, retry time.Duration) model.Locker {
+	return &fallbackLocker{primary: primary, local: local, retry: retry}
+}
+
+func (l *fallbackLocker) Obtain(ctx context.Context, key string, ttl time.Duration) (model.Lock, error) {
+	l.mu.Lock()
+	down := time.Now().Before(l.downUntil)
+	l.mu.Unlock()
+	if !down {
+		lock, err := l.primary.Obtain(ctx, key, ttl)
+		if err == nil || ctx.Err() != nil {
+			return lock, err
+		}
+		slog.Warn("locker unavailable, locking within this instance only", "retry", l.retry, "err", err)
+		l.mu.Lock()
+		l.downUntil = time.Now().Add(l.retry)
+		l.mu.Unlock()
+	}
+	return l.local.Obtain(ctx, key, ttl)
+}
+
+// obtain calls try until it returns a lock or an error, waiting a jittered
+// lockRetry between attempts. A nil lock and nil error means "busy".
+func obtain(ctx context.Context, key string, try func() (model.Lock, error)) (model.Lock, error) {
+	for {
+		lock, err := try()
+		if err != nil {
+			return nil, fmt.Errorf("obtain lock %q: %w", key, err)
+		}
+		if lock != nil {
+			return lock, nil
+		}
+		wait := lockRetry/2 + rand.N(lockRetry)
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("obtain lock %q: %w", key, ctx.Err())
+		case <-time.After(wait):
+		}
+	}
+}
+
+var (
+	_ model.Locker = (*redisLocker)(nil) // compile-time interface check
+	_ model.Lock   = (*redisLock)(nil)
+	_ model.Locker = (*fallbackLocker)(nil)
+)
@@ -0,0 +1,146 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"go-gin-project/internal/pkg/model"
+)
+
+type memoryEntry struct {
+	key       string
+	data      []byte
+	expiresAt time.Time // zero means no expiry
+	tags      []string
+}
+
+type memoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	maxBytes   int64
+	bytes      int64
+	order      *list.List // front is most recently used
+	items      map[string]*list.Element
+	tags       map[string]map[string]struct{} // tag -> keys
+}
+
+// NewMemory creates an in-process LRU model.CacheService. Values are stored
+// JSON-encoded, like in Redis, so callers get copies rather than shared pointers.
+// The least recently used entries are evicted once either bound is exceeded;
+// zero disables that bound.
+func NewMemory(maxEntries int, maxBytes int64) model.CacheService {
+	return &memoryCache{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		order:      list.New(),
+		items:      make(map[string]*list.Element),
+		tags:       make(map[string]map[string]struct{}),
+	}
+}
+
+func (c *memoryCache) Get(_ context.Context, key string, dest interface{}) error {
+	c.mu.Lock()
+	el, ok := c.items[key]
+	if !ok {
+		c.mu.Unlock()
+		return ErrMiss
+	}
+	entry := el.Value.(*memoryEntry)
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		c.remove(el)
+		c.mu.Unlock()
+		return ErrMiss
+	}
+	c.order.MoveToFront(el)
+	data := entry.data
+	c.mu.Unlock()
+
+	return json.Unmarshal(data, dest)
+}
+
+func (c *memoryCache) Set(_ context.Context, key string, value interface{}, expiration time.Duration, tags ...string) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("cache set marshal: %w", err)
+	}
+	entry := &memoryEntry{key: key, data: data, tags: tags}
+	if expiration > 0 {
+		entry.expiresAt = time.Now().Add(expiration)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.remove(el)
+	}
+	c.items[key] = c.order.PushFront(entry)
+	c.bytes += int64(len(data))
+	for _, tag := range tags {
+		if c.tags[tag] == nil {
+			c.tags[tag] = make(map[string]struct{})
+		}
+		c.tags[tag][key] = struct{}{}
+	}
+	for c.overLimit() {
+		c.remove(c.order.Back())
+	}
+	return nil
+}
+
+func (c *memoryCache) Delete(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.remove(el)
+	}
+	return nil
+}
+
+func (c *memoryCache) InvalidateTag(_ context.Context, tag string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.tags[tag] {
+		if el, ok := c.items[key]; ok {
+			c.remove(el)
+		}
+	}
+	delete(c.tags, tag)
+	return nil
+}
+
+// purge drops every entry.
+func (c *memoryCache) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	c.items = make(map[string]*list.Element)
+	c.tags = make(map[string]map[string]struct{})
+	c.bytes = 0
+}
+
+func (c *memoryCache) overLimit() bool {
+	if c.order.Len() == 0 {
+		return false
+	}
+	return (c.maxEntries > 0 && c.order.Len() > c.maxEntries) ||
+		(c.maxBytes > 0 && c.bytes > c.maxBytes)
+}
+
+// remove must be called with mu held.
+func (c *memoryCache) remove(el *list.Element) {
+	entry := c.order.Remove(el).(*memoryEntry)
+	delete(c.items, entry.key)
+	c.bytes -= int64(len(entry.data))
+	for _, tag := range entry.tags {
+		delete(c.tags[tag], entry.key)
+		if len(c.tags[tag]) == 0 {
+			delete(c.tags, tag)
+		}
+	}
+}
+
+var _ model.CacheService = (*memoryCache)(nil) // compile-time interface check
@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go-gin-project/internal/pkg/model"
+)
+
+// memoryLockSweep is how often Obtain drops the expired locks that were
+// never released.
+const memoryLockSweep = time.Minute
+
+type memoryLockState struct {
+	holder    int64 // token of the current holder
+	expiresAt time.Time
+}
+
+// memoryLocker keeps only the locks currently held, so the map does not grow
+// with every key ever locked; waiters poll rather than register, so an entry
+// can go as soon as its lock is released. Tokens come from one counter shared
+// by all keys, which keeps them increasing for a key after its entry is dropped.
+type memoryLocker struct {
+	mu        sync.Mutex
+	locks     map[string]*memoryLockState
+	fence     int64 // last token issued
+	nextSweep time.Time
+}
+
+// NewMemoryLocker creates a model.Locker that only excludes callers within
+// this process; use it in tests and single-instance deployments.
+func NewMemoryLocker() model.Locker {
+	return &memoryLocker{
+		locks: make(map[string]*memoryLockState),
+		// Seeded like the Redis counter so tokens stay ahead of ones it issued.
+		fence:     time.Now().UnixMicro(),
+		nextSweep: time.Now().Add(memoryLockSweep),
+	}
+}
+
+func (l *memoryLocker) Obtain(ctx context.Context, key string, ttl time.Duration) (model.Lock, error) {
+	return obtain(ctx, key, func() (model.Lock, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		now := time.Now()
+		l.sweep(now)
+		if st, ok := l.locks[key]; ok && now.Before(st.expiresAt) {
+			return nil, nil
+		}
+		l.fence++
+		l.locks[key] = &memoryLockState{holder: l.fence, expiresAt: now.Add(ttl)}
+		return &memoryLock{locker: l, key: key, token: l.fence}, nil
+	})
+}
+
+type memoryLock struct {
+	locker *memoryLocker
+	key    string
+	token  int64
+}
+
+func (l *memoryLock) Token() int64 { return l.token }
+
+func (l *memoryLock) Release(context.Context) error {
+	l.locker.mu.Lock()
+	defer l.locker.mu.Unlock()
+	st, ok := l.locker.locks[l.key]
+	if !ok || st.holder != l.token {
+		return model.ErrLockNotHeld
+	}
+	delete(l.locker.locks, l.key)
+	if !time.Now().Before(st.expiresAt) {
+		return model.ErrLockNotHeld
+	}
+	return nil
+}
+
+// sweep drops expired locks, at most once per memoryLockSweep; l.mu is held.
+func (l *memoryLocker) sweep(now time.Time) {
+	if now.Before(l.nextSweep) {
+		return
+	}
+	l.nextSweep = now.Add(memoryLockSweep)
+	for key, st := range l.locks {
+		if !now.Before(st.expiresAt) {
+			delete(l.locks, key)
+		}
+	}
+}
+
+var (
+	_ model.Locker = (*memoryLocker)(nil) // compile-time interface check
+	_ model.Lock   = (*memoryLock)(nil)
+)
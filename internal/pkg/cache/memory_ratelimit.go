@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go-gin-project/internal/pkg/model"
+)
+
+// memorySweepEvery is how many calls pass between sweeps of idle keys.
+const memorySweepEvery = 1024
+
+type memoryRateLimiter struct {
+	mu    sync.Mutex
+	tats  map[string]time.Time // theoretical arrival time per key
+	calls int
+}
+
+// NewMemoryRateLimiter creates a model.RateLimiter that counts requests in
+// this process only, using the same algorithm as the Redis limiter.
+func NewMemoryRateLimiter() model.RateLimiter {
+	return &memoryRateLimiter{tats: make(map[string]time.Time)}
+}
+
+func (l *memoryRateLimiter) Allow(_ context.Context, key string, limit model.RateLimit) (*model.RateLimitResult, error) {
+	interval := limit.Interval()
+	tolerance := interval * time.Duration(limit.Burst)
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.calls++; l.calls%memorySweepEvery == 0 {
+		for k, tat := range l.tats {
+			if tat.Before(now) {
+				delete(l.tats, k)
+			}
+		}
+	}
+
+	tat := l.tats[key]
+	if tat.Before(now) {
+		tat = now
+	}
+	newTAT := tat.Add(interval)
+	allowAt := newTAT.Add(-tolerance)
+	if now.Before(allowAt) {
+		return &model.RateLimitResult{
+			Limit:      limit.Burst,
+			RetryAfter: allowAt.Sub(now),
+			ResetAfter: tat.Sub(now),
+		}, nil
+	}
+	l.tats[key] = newTAT
+	return &model.RateLimitResult{
+		Allowed:    true,
+		Limit:      limit.Burst,
+		Remaining:  int(now.Sub(allowAt) / interval),
+		ResetAfter: newTAT.Sub(now),
+	}, nil
+}
+
+var _ model.RateLimiter = (*memoryRateLimiter)(nil) // compile-time interface check
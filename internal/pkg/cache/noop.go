@@ -0,0 +1,27 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"go-gin-project/internal/pkg/model"
+)
+
+type noopCache struct{}
+
+// NewNoop creates a model.CacheService that stores nothing; every Get misses.
+func NewNoop() model.CacheService {
+	return noopCache{}
+}
+
+func (noopCache) Get(context.Context, string, interface{}) error { return ErrMiss }
+
+func (noopCache) Set(context.Context, string, interface{}, time.Duration, ...string) error {
+	return nil
+}
+
+func (noopCache) Delete(context.Context, string) error { return nil }
+
+func (noopCache) InvalidateTag(context.Context, string) error { return nil }
+
+var _ model.CacheService = noopCache{} // compile-time interface check
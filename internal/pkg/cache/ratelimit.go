@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go-gin-project/internal/pkg/model"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// gcraScript applies the generic cell rate algorithm to KEYS[1], which holds
+// the theoretical arrival time (TAT) in microseconds of the server clock.
+// ARGV[1] is the emission interval and ARGV[2] the burst tolerance, both in
+// microseconds. It returns {allowed, remaining, retry_after_us, reset_after_us}.
+const gcraScript = `
+local interval = tonumber(ARGV[1])
+local tolerance = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local tat = tonumber(redis.call('GET', KEYS[1]) or now)
+if tat < now then
+	tat = now
+end
+local new_tat = tat + interval
+local allow_at = new_tat - tolerance
+if now < allow_at then
+	return {0, 0, allow_at - now, tat - now}
+end
+redis.call('SET', KEYS[1], string.format('%.0f', new_tat), 'PX', math.ceil((new_tat - now) / 1000))
+return {1, math.floor((now - allow_at) / interval), 0, new_tat - now}
+`
+
+type redisRateLimiter struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRateLimiter creates a Redis-backed model.RateLimiter. Limits are shared
+// by every instance using the same Redis.
+func NewRateLimiter(opts RedisOptions) (model.RateLimiter, error) {
+	c, err := newRedis(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &redisRateLimiter{client: c.client, prefix: c.prefix}, nil
+}
+
+func (l *redisRateLimiter) Allow(ctx context.Context, key string, limit model.RateLimit) (*model.RateLimitResult, error) {
+	interval := limit.Interval()
+	tolerance := interval * time.Duration(limit.Burst)
+	res, err := l.client.Eval(ctx, gcraScript, []string{l.prefix + rateLimitKeyPrefix + key},
+		interval.Microseconds(), tolerance.Microseconds()).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("rate limit %q: %w", key, err)
+	}
+	return &model.RateLimitResult{
+		Allowed:    res[0] == 1,
+		Limit:      limit.Burst,
+		Remaining:  int(res[1]),
+		RetryAfter: time.Duration(res[2]) * time.Microsecond,
+		ResetAfter: time.Duration(res[3]) * time.Microsecond,
+	}, nil
+}
+
+type fallbackRateLimiter struct {
+	primary model.RateLimiter
+	local   model.RateLimiter
+	retry   time.Duration
+
+	mu        sync.Mutex
+	downUntil time.Time
+}
+
+// NewFallbackRateLimiter creates a model.RateLimiter that uses primary and
+// switches to local for retry whenever primary fails, so an unreachable Redis
+// neither rejects all traffic nor slows every request down.
+func NewFallbackRateLimiter(primary, local model.RateLimiter, retry time.Duration) model.RateLimiter {
+	return &fallbackRateLimiter{primary: primary, local: local, retry: retry}
+}
+
+func (l *fallbackRateLimiter) Allow(ctx context.Context, key string, limit model.RateLimit) (*model.RateLimitResult, error) {
+	l.mu.Lock()
+	down := time.Now().Before(l.downUntil)
+	l.mu.Unlock()
+	if !down {
+		res, err := l.primary.Allow(ctx, key, limit)
+		if err == nil || ctx.Err() != nil {
+			return res, err
+		}
+		slog.Warn("rate limiter unavailable, limiting per instance", "retry", l.retry, "err", err)
+		l.mu.Lock()
+		l.downUntil = time.Now().Add(l.retry)
+		l.mu.Unlock()
+	}
+	return l.local.Allow(ctx, key, limit)
+}
+
+var (
+	_ model.RateLimiter = (*redisRateLimiter)(nil) // compile-time interface check
+	_ model.RateLimiter = (*fallbackRateLimiter)(nil)
+)
@@ -0,0 +1,26 @@
+package cache
+
+// PoolStats is a snapshot of a Redis connection pool.
+type PoolStats struct {
+	Hits       uint32 `json:"hits"`        // free connection found in the pool
+	Misses     uint32 `json:"misses"`      // no free connection, a new one was dialled
+	Timeouts   uint32 `json:"timeouts"`    // waits for a connection that timed out
+	TotalConns uint32 `json:"total_conns"` // open connections
+	IdleConns  uint32 `json:"idle_conns"`  // idle connections
+	StaleConns uint32 `json:"stale_conns"` // connections removed from the pool
+}
+
+// StatsProvider is implemented by caches backed by a connection pool.
+type StatsProvider interface {
+	PoolStats() (PoolStats, bool)
+}
+
+// Stats returns c's pool statistics, or ok=false if c has no pool (for
+// example the in-memory cache, or a fallback cache that is degraded).
+func Stats(c interface{}) (stats PoolStats, ok bool) {
+	p, isProvider := c.(StatsProvider)
+	if !isProvider {
+		return PoolStats{}, false
+	}
+	return p.PoolStats()
+}
@@ -0,0 +1,172 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"go-gin-project/internal/pkg/model"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// invalidationChannel carries "<instance>|<key>" messages between instances.
+const invalidationChannel = "cache:invalidate"
+
+// purgeAllKey in an invalidation message stands for every key, after Purge.
+const purgeAllKey = "*"
+
+type tieredCache struct {
+	local    *memoryCache
+	remote   *redisCache
+	localTTL time.Duration
+	instance string
+	cancel   context.CancelFunc
+}
+
+// NewTiered creates a model.CacheService that keeps up to localEntries values
+// in a process-local LRU in front of Redis. Writes and deletes are broadcast
+// over Redis pub/sub so every instance drops its local copy; localTTL bounds
+// how long a local copy can outlive a missed invalidation.
+func NewTiered(opts RedisOptions, localEntries int, localTTL time.Duration) (model.CacheService, error) {
+	remote, err := newRedis(opts)
+	if err != nil {
+		return nil, err
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		remote.Close() //nolint:errcheck
+		return nil, fmt.Errorf("tiered cache: instance id: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &tieredCache{
+		local:    NewMemory(localEntries, 0).(*memoryCache),
+		remote:   remote,
+		localTTL: localTTL,
+		instance: hex.EncodeToString(id),
+		cancel:   cancel,
+	}
+	pubsub := remote.client.Subscribe(ctx, remote.prefix+invalidationChannel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		cancel()
+		remote.Close() //nolint:errcheck
+		return nil, fmt.Errorf("tiered cache: subscribe: %w", err)
+	}
+	go c.listen(ctx, pubsub)
+	return c, nil
+}
+
+func (c *tieredCache) Get(ctx context.Context, key string, dest interface{}) error {
+	if err := c.local.Get(ctx, key, dest); err == nil {
+		return nil
+	}
+	if err := c.remote.Get(ctx, key, dest); err != nil {
+		return err
+	}
+	c.local.Set(ctx, key, dest, c.localTTL) //nolint:errcheck
+	return nil
+}
+
+// Set tags the key in Redis only; other instances learn which local copies to
+// drop from the per-key messages InvalidateTag publishes.
+func (c *tieredCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration, tags ...string) error {
+	if err := c.remote.Set(ctx, key, value, expiration, tags...); err != nil {
+		return err
+	}
+	c.publish(ctx, key)
+	localTTL := c.localTTL
+	if expiration > 0 && expiration < localTTL {
+		localTTL = expiration
+	}
+	return c.local.Set(ctx, key, value, localTTL)
+}
+
+func (c *tieredCache) Delete(ctx context.Context, key string) error {
+	c.local.Delete(ctx, key) //nolint:errcheck
+	if err := c.remote.Delete(ctx, key); err != nil {
+		return err
+	}
+	c.publish(ctx, key)
+	return nil
+}
+
+func (c *tieredCache) InvalidateTag(ctx context.Context, tag string) error {
+	keys, err := c.remote.invalidateTag(ctx, tag)
+	for _, key := range keys {
+		c.local.Delete(ctx, key) //nolint:errcheck
+		c.publish(ctx, key)
+	}
+	return err
+}
+
+// Purge empties Redis (see redisCache.Purge) and every instance's local tier.
+func (c *tieredCache) Purge(ctx context.Context) error {
+	c.local.purge()
+	if err := c.remote.Purge(ctx); err != nil {
+		return err
+	}
+	c.publish(ctx, purgeAllKey)
+	return nil
+}
+
+func (c *tieredCache) Ping() error {
+	return c.remote.Ping()
+}
+
+func (c *tieredCache) Close() error {
+	c.cancel()
+	return c.remote.Close()
+}
+
+func (c *tieredCache) PoolStats() (PoolStats, bool) {
+	return c.remote.PoolStats()
+}
+
+func (c *tieredCache) publish(ctx context.Context, key string) {
+	msg := c.instance + "|" + key
+	if err := c.remote.client.Publish(ctx, c.remote.prefix+invalidationChannel, msg).Err(); err != nil {
+		slog.Warn("cache invalidation publish", "key", key, "err", err)
+	}
+}
+
+// listen evicts local copies named by other instances. go-redis resubscribes
+// after a dropped connection; messages sent meanwhile are lost, so the whole
+// local tier is cleared on errors and on every (re)subscription.
+func (c *tieredCache) listen(ctx context.Context, pubsub *redis.PubSub) {
+	defer pubsub.Close() //nolint:errcheck
+	for {
+		msg, err := pubsub.Receive(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			c.local.purge()
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+		switch m := msg.(type) {
+		case *redis.Subscription:
+			c.local.purge()
+		case *redis.Message:
+			origin, key, ok := strings.Cut(m.Payload, "|")
+			switch {
+			case !ok || origin == c.instance:
+			case key == purgeAllKey:
+				c.local.purge()
+			default:
+				c.local.Delete(ctx, key) //nolint:errcheck
+			}
+		}
+	}
+}
+
+var _ model.CacheService = (*tieredCache)(nil) // compile-time interface check
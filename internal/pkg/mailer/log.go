@@ -0,0 +1,22 @@
+package mailer
+
+import (
+	"log/slog"
+
+	"go-gin-project/internal/pkg/model"
+)
+
+type logMailer struct{}
+
+// NewLog creates a model.Mailer that writes messages to the application log
+// instead of delivering them. Useful for development until SMTP is configured.
+func NewLog() model.Mailer {
+	return &logMailer{}
+}
+
+func (m *logMailer) Send(to, subject, body string) error {
+	slog.Info("mail", "to", to, "subject", subject, "body", body)
+	return nil
+}
+
+var _ model.Mailer = (*logMailer)(nil) // compile-time interface check
@@ -0,0 +1,412 @@
+// Package migrate applies versioned SQL migrations and records them in the
+// schema_migrations table.
+//
+// Migrations are pairs of files named <version>_<name>.up.sql and
+// <version>_<name>.down.sql, applied in version order. Statements are
+// separated by a semicolon at the end of a line. Each migration runs in a
+// transaction together with its schema_migrations row. PostgreSQL and SQLite
+// roll back a failing migration completely; MySQL commits DDL statements
+// implicitly, so there a failing migration with several DDL statements can
+// leave the earlier ones applied.
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// lockTimeout bounds how long a migrator waits for another one to finish,
+// e.g. when several instances start at once.
+const lockTimeout = time.Minute
+
+// Migration is one schema change.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status is a migration and when it was applied, if it was.
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+var fileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Load reads the migrations in the top level of source.
+func Load(source fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(source, ".")
+	if err != nil {
+		return nil, fmt.Errorf("load migrations: %w", err)
+	}
+	byVersion := map[int64]*Migration{}
+	for _, e := range entries {
+		match := fileName.FindStringSubmatch(e.Name())
+		if e.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("load migrations: %s: %w", e.Name(), err)
+		}
+		data, err := fs.ReadFile(source, e.Name())
+		if err != nil {
+			return nil, fmt.Errorf("load migrations: %w", err)
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("load migrations: version %d is used by %q and %q", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(data)
+		} else {
+			m.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if strings.TrimSpace(m.Up) == "" {
+			return nil, fmt.Errorf("load migrations: %d_%s has no up migration", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// schemaMigration is a row of schema_migrations.
+type schemaMigration struct {
+	Version   int64  `gorm:"primaryKey;autoIncrement:false"`
+	Name      string `gorm:"type:varchar(255);not null"`
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string { return "schema_migrations" }
+
+// Migrator applies a set of migrations to a database.
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// New creates a Migrator for the migrations in source.
+func New(db *gorm.DB, source fs.FS) (*Migrator, error) {
+	migrations, err := Load(source)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Status lists every known migration in version order, plus applied versions
+// that have no file (e.g. from a newer release), with their applied times.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(m.db.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	var out []Status
+	for _, mig := range m.migrations {
+		s := Status{Migration: mig}
+		if row, ok := applied[mig.Version]; ok {
+			s.AppliedAt = &row.AppliedAt
+			delete(applied, mig.Version)
+		}
+		out = append(out, s)
+	}
+	for _, row := range applied {
+		out = append(out, Status{Migration: Migration{Version: row.Version, Name: row.Name}, AppliedAt: &row.AppliedAt})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out, nil
+}
+
+// Pending returns the migrations that have not been applied.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, s := range statuses {
+		if s.AppliedAt == nil {
+			pending = append(pending, s.Migration)
+		}
+	}
+	return pending, nil
+}
+
+// Up applies up to steps pending migrations in version order, or all of them
+// if steps <= 0, and returns those it applied.
+func (m *Migrator) Up(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(ctx, func(db *gorm.DB) error {
+		applied, err := m.applied(db)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+			if steps > 0 && len(done) == steps {
+				break
+			}
+			err := db.Transaction(func(tx *gorm.DB) error {
+				if err := exec(tx, mig.Up); err != nil {
+					return err
+				}
+				return tx.Create(&schemaMigration{Version: mig.Version, Name: mig.Name, AppliedAt: time.Now().UTC()}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migrate up %d_%s: %w", mig.Version, mig.Name, err)
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down reverts the steps most recently applied migrations (at least one)
+// and returns those it reverted.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		steps = 1
+	}
+	byVersion := map[int64]Migration{}
+	for _, mig := range m.migrations {
+		byVersion[mig.Version] = mig
+	}
+
+	var done []Migration
+	err := m.locked(ctx, func(db *gorm.DB) error {
+		var rows []schemaMigration
+		if err := db.Order("version DESC").Limit(steps).Find(&rows).Error; err != nil {
+			return fmt.Errorf("migrate down: %w", err)
+		}
+		for _, row := range rows {
+			mig, ok := byVersion[row.Version]
+			if !ok || strings.TrimSpace(mig.Down) == "" {
+				return fmt.Errorf("migrate down %d_%s: no down migration", row.Version, row.Name)
+			}
+			err := db.Transaction(func(tx *gorm.DB) error {
+				if err := exec(tx, mig.Down); err != nil {
+					return err
+				}
+				return tx.Delete(&schemaMigration{}, row.Version).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migrate down %d_%s: %w", mig.Version, mig.Name, err)
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Adopt brings a database created before versioned migrations, by GORM
+// AutoMigrate, under them. The first migration must be the baseline: the
+// schema AutoMigrate created then. If the database has tables but no applied
+// migrations, Adopt records that baseline as applied without running it and
+// leaves every later migration to Up, so none of their schema or data changes
+// are skipped. It returns the migrations it recorded: none for an empty or
+// already versioned database.
+func (m *Migrator) Adopt(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(ctx, func(db *gorm.DB) error {
+		applied, err := m.applied(db)
+		if err != nil || len(applied) > 0 || len(m.migrations) == 0 {
+			return err
+		}
+		tables, err := db.Migrator().GetTables()
+		if err != nil {
+			return fmt.Errorf("migrate adopt: %w", err)
+		}
+		legacy := false
+		for _, t := range tables {
+			if t != (schemaMigration{}).TableName() {
+				legacy = true
+			}
+		}
+		if !legacy {
+			return nil
+		}
+		baseline := m.migrations[0]
+		if err := db.Create(&schemaMigration{Version: baseline.Version, Name: baseline.Name, AppliedAt: time.Now().UTC()}).Error; err != nil {
+			return fmt.Errorf("migrate adopt %d_%s: %w", baseline.Version, baseline.Name, err)
+		}
+		done = append(done, baseline)
+		return nil
+	})
+	return done, err
+}
+
+func (m *Migrator) applied(db *gorm.DB) (map[int64]schemaMigration, error) {
+	applied := map[int64]schemaMigration{}
+	if !db.Migrator().HasTable(&schemaMigration{}) {
+		return applied, nil
+	}
+	var rows []schemaMigration
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("read schema_migrations: %w", err)
+	}
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// locked runs fn on a single connection holding the migration lock, so
+// concurrent migrators (e.g. several instances starting together) take turns
+// instead of applying the same migration twice.
+func (m *Migrator) locked(ctx context.Context, fn func(db *gorm.DB) error) error {
+	return m.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		if err := lock(conn); err != nil {
+			return err
+		}
+		defer unlock(conn)
+		if err := conn.AutoMigrate(&schemaMigration{}); err != nil {
+			return fmt.Errorf("create schema_migrations: %w", err)
+		}
+		return fn(conn)
+	})
+}
+
+// lockName identifies the migration lock across connections; lockKey is
+// the same for PostgreSQL advisory locks, which take an integer.
+const (
+	lockName = "schema_migrations"
+	lockKey  = 0x6d6967726174 // "migrat"
+)
+
+func lock(db *gorm.DB) error {
+	switch db.Dialector.Name() {
+	case "mysql":
+		var got *int
+		if err := db.Raw("SELECT GET_LOCK(?, ?)", lockName, int(lockTimeout.Seconds())).Scan(&got).Error; err != nil {
+			return fmt.Errorf("acquire migration lock: %w", err)
+		}
+		if got == nil || *got != 1 {
+			return errors.New("acquire migration lock: timed out waiting for another migrator")
+		}
+		return nil
+	case "postgres":
+		deadline := time.Now().Add(lockTimeout)
+		for {
+			var got bool
+			if err := db.Raw("SELECT pg_try_advisory_lock(?)", lockKey).Scan(&got).Error; err != nil {
+				return fmt.Errorf("acquire migration lock: %w", err)
+			}
+			if got {
+				return nil
+			}
+			if time.Now().After(deadline) {
+				return errors.New("acquire migration lock: timed out waiting for another migrator")
+			}
+			select {
+			case <-db.Statement.Context.Done():
+				return fmt.Errorf("acquire migration lock: %w", db.Statement.Context.Err())
+			case <-time.After(time.Second):
+			}
+		}
+	case "sqlite":
+		// The database is a local file used by one process; SQLite's own
+		// write lock serializes the migration transactions.
+		return nil
+	default:
+		return fmt.Errorf("acquire migration lock: unsupported database %q", db.Dialector.Name())
+	}
+}
+
+func unlock(db *gorm.DB) {
+	switch db.Dialector.Name() {
+	case "mysql":
+		db.Exec("SELECT RELEASE_LOCK(?)", lockName)
+	case "postgres":
+		db.Exec("SELECT pg_advisory_unlock(?)", lockKey)
+	}
+}
+
+// exec runs each statement of a migration file.
+func exec(tx *gorm.DB, script string) error {
+	for _, stmt := range statements(script) {
+		if err := tx.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// statements splits script at semicolons ending a line and drops
+// comment-only chunks.
+func statements(script string) []string {
+	var out []string
+	var b strings.Builder
+	flush := func() {
+		stmt := strings.TrimSpace(b.String())
+		b.Reset()
+		for _, line := range strings.Split(stmt, "\n") {
+			if l := strings.TrimSpace(line); l != "" && !strings.HasPrefix(l, "--") {
+				out = append(out, strings.TrimSuffix(stmt, ";"))
+				return
+			}
+		}
+	}
+	for _, line := range strings.SplitAfter(script, "\n") {
+		b.WriteString(line)
+		if strings.HasSuffix(strings.TrimSpace(line), ";") {
+			flush()
+		}
+	}
+	flush()
+	return out
+}
+
+// Create writes empty up and down files for a new migration named name into
+// dir, versioned by the current UTC time so migrations written on separate
+// branches do not collide, and returns their paths.
+func Create(dir, name string, now time.Time) (string, string, error) {
+	name = strings.ToLower(strings.NewReplacer(" ", "_", "-", "_").Replace(strings.TrimSpace(name)))
+	base := now.UTC().Format("20060102150405") + "_" + name
+	if !fileName.MatchString(base + ".up.sql") {
+		return "", "", fmt.Errorf("create migration: name %q must use letters, digits and underscores", name)
+	}
+	up := filepath.Join(dir, base+".up.sql")
+	down := filepath.Join(dir, base+".down.sql")
+	for path, body := range map[string]string{
+		up:   "-- Statements end with a semicolon at the end of a line.\n",
+		down: "-- Revert everything the up migration does.\n",
+	} {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return "", "", fmt.Errorf("create migration: %w", err)
+		}
+		_, err = f.WriteString(body)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return "", "", fmt.Errorf("create migration: %w", err)
+		}
+	}
+	return up, down, nil
+}
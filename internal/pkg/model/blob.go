@@ -0,0 +1,15 @@
+package model
+
+import (
+	"io"
+	"time"
+)
+
+// BlobStore defines object storage for uploaded files such as avatars.
+// Implemented by infrastructure/blobstore, consumed by application.
+type BlobStore interface {
+	Put(key string, r io.Reader, size int64, contentType string) error
+	Delete(key string) error
+	// SignedURL returns a URL from which the object can be fetched until ttl elapses.
+	SignedURL(key string, ttl time.Duration) (string, error)
+}
@@ -1,11 +1,40 @@
 package model
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // CacheService defines cache operations.
 // Implemented by infrastructure/redis, consumed by application.
 type CacheService interface {
-	Get(key string, dest interface{}) error
-	Set(key string, value interface{}, expiration time.Duration) error
-	Delete(key string) error
+	Get(ctx context.Context, key string, dest interface{}) error
+	// Set stores value under key and records key under each tag so that
+	// InvalidateTag can later remove it along with the tag's other keys.
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration, tags ...string) error
+	Delete(ctx context.Context, key string) error
+	// InvalidateTag deletes every key stored with tag.
+	InvalidateTag(ctx context.Context, tag string) error
+}
+
+// Tagged can be returned by a GetOrLoad loader to store the loaded Value
+// under Tags, which may depend on the value itself.
+type Tagged struct {
+	Value interface{}
+	Tags  []string
+}
+
+// LoadingCache is a CacheService that can also fill itself on a miss.
+type LoadingCache interface {
+	CacheService
+	// GetOrLoad decodes the cached value for key into dest, calling load on a
+	// miss and caching its result for about ttl. Concurrent misses for the
+	// same key share one load, which runs with ctx detached from the
+	// caller's cancellation and under a timeout, as does the background
+	// refresh of a stale value. If load fails with ErrNotFound, that outcome
+	// is cached briefly too and later calls return ErrNotFound without
+	// calling load. A Tagged result is cached as its Value under its Tags.
+	// A Set, Delete or InvalidateTag that lands while a load runs keeps its
+	// result out of the cache.
+	GetOrLoad(ctx context.Context, key string, dest interface{}, ttl time.Duration, load func(ctx context.Context) (interface{}, error)) error
 }
@@ -0,0 +1,19 @@
+package model
+
+import "context"
+
+type readPrimaryKey struct{}
+
+// ReadPrimary returns a context whose repository reads go to the primary
+// database rather than a read replica. Use it where a replica lagging behind
+// would be wrong: reads that decide a write, and reads whose result is
+// cached after a write invalidated the previous copy.
+func ReadPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readPrimaryKey{}, true)
+}
+
+// ReadsPrimary reports whether ctx was marked by ReadPrimary.
+func ReadsPrimary(ctx context.Context) bool {
+	v, _ := ctx.Value(readPrimaryKey{}).(bool)
+	return v
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+// EmailChange is a pending request to move a user to a new email address.
+// It takes effect only once the token sent to the new address is confirmed.
+type EmailChange struct {
+	ID          uint
+	UserID      uint
+	NewEmail    string
+	TokenHash   string `json:"-"`
+	ExpiresAt   time.Time
+	ConfirmedAt *time.Time
+	CreatedAt   time.Time
+}
+
+// EmailChangeRepository defines persistence operations for pending email changes.
+// Implemented by infrastructure/repository, consumed by application.
+type EmailChangeRepository interface {
+	// Create stores a pending change, discarding any earlier unconfirmed one for the user.
+	Create(ctx context.Context, change *EmailChange) (*EmailChange, error)
+	FindByTokenHash(ctx context.Context, tokenHash string) (*EmailChange, error)
+	// Confirm swaps the user's email and bumps their token version in one
+	// transaction. It fails if the change is no longer pending or the address is taken.
+	Confirm(ctx context.Context, change *EmailChange) (*User, error)
+}
@@ -0,0 +1,16 @@
+package model
+
+import "errors"
+
+var (
+	// ErrNotFound is wrapped by repositories when a lookup matches no record.
+	ErrNotFound = errors.New("not found")
+	// ErrDuplicate is wrapped by repositories when a write violates a unique index.
+	ErrDuplicate = errors.New("already exists")
+	// ErrLockNotHeld is returned when releasing a lock that expired or was taken over.
+	ErrLockNotHeld = errors.New("lock not held")
+	// ErrStaleToken is returned by writes fenced with a token older than one already applied.
+	ErrStaleToken = errors.New("stale fencing token")
+	// ErrLastOwner is returned when a change would leave an organization without an owner.
+	ErrLastOwner = errors.New("an organization must keep at least one owner")
+)
@@ -0,0 +1,90 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Event types. Payloads carry IDs rather than personal data, so erasing a
+// user does not have to reach events already published.
+const (
+	// EventUserCreated has a UserCreated payload.
+	EventUserCreated = "user.created"
+	// EventPaymentStatusChanged has a PaymentStatusChanged payload.
+	EventPaymentStatusChanged = "payment.status_changed"
+)
+
+// Aggregate types; events of the same aggregate are published in order.
+const (
+	AggregateUser    = "user"
+	AggregatePayment = "payment"
+)
+
+// Event is a domain event. Repositories record events in the outbox in the
+// same transaction as the change they describe, and the outbox relay
+// publishes them to an EventBroker.
+type Event struct {
+	// ID is unique per event; a crash between publishing and settling an
+	// event delivers it again, so consumers should drop IDs they have seen.
+	ID            uint
+	Type          string
+	AggregateType string
+	AggregateID   string
+	Payload       json.RawMessage
+	CreatedAt     time.Time
+	// Attempts counts failed publish attempts, and LastError holds the
+	// error of the latest one.
+	Attempts  int
+	LastError string
+	// DeadAt is when the relay gave up on the event; nil while pending.
+	DeadAt *time.Time
+}
+
+// UserCreated is the payload of EventUserCreated.
+type UserCreated struct {
+	UserID uint `json:"user_id"`
+	// OrgID is the organization the user was created in; zero if none.
+	OrgID uint `json:"org_id,omitempty"`
+}
+
+// PaymentStatusChanged is the payload of EventPaymentStatusChanged.
+type PaymentStatusChanged struct {
+	PaymentID uint   `json:"payment_id"`
+	StripeID  string `json:"stripe_id"`
+	UserID    uint   `json:"user_id"`
+	OrgID     uint   `json:"org_id,omitempty"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+}
+
+// EventBroker delivers events to downstream consumers.
+// Implemented by infrastructure/broker, consumed by application.
+type EventBroker interface {
+	// Publish delivers event, returning once the broker has stored it.
+	Publish(ctx context.Context, event *Event) error
+}
+
+// OutboxStats counts the events in the outbox.
+type OutboxStats struct {
+	Pending int64 `json:"pending"`
+	Dead    int64 `json:"dead"`
+}
+
+// OutboxRepository reads and settles the events other repositories record.
+// Implemented by infrastructure/repository, consumed by application.
+type OutboxRepository interface {
+	// Pending returns up to limit events that are due for publishing, oldest
+	// first. It returns at most one event per aggregate, the oldest one not
+	// dead-lettered, so an aggregate's events are published in order and a
+	// retrying event holds back those after it.
+	Pending(ctx context.Context, limit int) ([]*Event, error)
+	// MarkPublished removes a published event from the outbox.
+	MarkPublished(ctx context.Context, id uint) error
+	// MarkFailed records a failed publish attempt. The event is retried at
+	// retryAt, or dead-lettered if retryAt is zero.
+	MarkFailed(ctx context.Context, id uint, cause error, retryAt time.Time) error
+	// DeadLetters lists up to limit dead-lettered events, newest first.
+	DeadLetters(ctx context.Context, limit int) ([]*Event, error)
+	Stats(ctx context.Context) (OutboxStats, error)
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+// Invitation asks someone to join an organization with a given role.
+// Only the SHA-256 hash of the single-use token is stored.
+type Invitation struct {
+	ID         uint
+	OrgID      uint
+	Email      string
+	Role       string
+	TokenHash  string `json:"-"`
+	InvitedBy  uint
+	ExpiresAt  time.Time
+	AcceptedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
+
+// IsPending reports whether the invitation can still be accepted at now.
+func (i *Invitation) IsPending(now time.Time) bool {
+	return i.AcceptedAt == nil && i.RevokedAt == nil && now.Before(i.ExpiresAt)
+}
+
+// InvitationRepository defines persistence operations for invitations.
+// Implemented by infrastructure/repository, consumed by application.
+type InvitationRepository interface {
+	Create(ctx context.Context, inv *Invitation) (*Invitation, error)
+	ListByOrg(ctx context.Context, orgID uint) ([]*Invitation, error)
+	FindByTokenHash(ctx context.Context, tokenHash string) (*Invitation, error)
+	Revoke(ctx context.Context, orgID, id uint) error
+	// Delete removes an invitation whose email could not be sent.
+	Delete(ctx context.Context, id uint) error
+	// Accept creates the user and their membership, records EventUserCreated
+	// and marks the invitation used in one transaction. It fails if the
+	// invitation is no longer pending.
+	Accept(ctx context.Context, inv *Invitation, user *User) (*User, error)
+	// Join marks the invitation used and adds the existing user userID to its
+	// organization in one transaction. It fails if the invitation is no
+	// longer pending, or with ErrDuplicate if the user is already a member.
+	Join(ctx context.Context, inv *Invitation, userID uint) (*Membership, error)
+}
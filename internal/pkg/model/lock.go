@@ -0,0 +1,26 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+// Locker hands out exclusive locks that expire after a TTL, so a crashed
+// holder cannot block others forever.
+// Implemented by infrastructure/cache, consumed by application.
+type Locker interface {
+	// Obtain waits until the lock for key is free, then holds it for ttl.
+	// It fails with ctx's error if ctx ends first.
+	Obtain(ctx context.Context, key string, ttl time.Duration) (Lock, error)
+}
+
+// Lock is a held lock.
+type Lock interface {
+	// Token is a fencing token: it grows with every acquisition of the same
+	// key, so storage can reject writes from a holder whose lock expired and
+	// was taken over.
+	Token() int64
+	// Release frees the lock if it is still held by this holder; otherwise it
+	// returns ErrLockNotHeld and leaves the current holder alone.
+	Release(ctx context.Context) error
+}
@@ -0,0 +1,7 @@
+package model
+
+// Mailer defines outbound email delivery.
+// Implemented by infrastructure/mailer, consumed by application.
+type Mailer interface {
+	Send(to, subject, body string) error
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+// Organization roles, ordered from most to least privileged.
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+)
+
+// Organization is a tenant: users and payments are scoped to one.
+type Organization struct {
+	ID        uint
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Membership links a user to an organization with a per-org role.
+type Membership struct {
+	ID        uint
+	OrgID     uint
+	UserID    uint
+	Role      string
+	CreatedAt time.Time
+}
+
+// IsValidRole reports whether role is one of the known organization roles.
+func IsValidRole(role string) bool {
+	return role == RoleOwner || role == RoleAdmin || role == RoleMember
+}
+
+// IsAdminRole reports whether role may manage the organization.
+func IsAdminRole(role string) bool {
+	return role == RoleOwner || role == RoleAdmin
+}
+
+// OrganizationRepository defines persistence operations for organizations and memberships.
+// Implemented by infrastructure/repository, consumed by application.
+type OrganizationRepository interface {
+	// Create stores the organization and makes ownerID its owner.
+	Create(ctx context.Context, org *Organization, ownerID uint) (*Organization, error)
+	FindByID(ctx context.Context, id uint) (*Organization, error)
+	ListForUser(ctx context.Context, userID uint) ([]*Organization, error)
+	FindMembership(ctx context.Context, orgID, userID uint) (*Membership, error)
+	ListMembers(ctx context.Context, orgID uint) ([]*Membership, error)
+	// UpdateMemberRole and RemoveMember fail with ErrLastOwner rather than
+	// demote or remove the organization's only owner.
+	UpdateMemberRole(ctx context.Context, orgID, userID uint, role string) (*Membership, error)
+	RemoveMember(ctx context.Context, orgID, userID uint) error
+}
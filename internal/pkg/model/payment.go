@@ -1,6 +1,7 @@
 package model
 
 import (
+	"context"
 	"time"
 
 	"github.com/stripe/stripe-go/v72"
@@ -9,22 +10,32 @@ import (
 // Payment represents the payment domain entity.
 type Payment struct {
 	ID            uint
+	OrgID         uint
 	UserID        uint
 	Amount        float64
 	Currency      string
 	StripeID      string
 	PaymentStatus string
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	DeletedAt     *time.Time
+	// FenceToken, when non-zero, is the fencing token of the lock guarding a
+	// status update; see PaymentRepository.UpdateStatus.
+	FenceToken int64 `json:"-"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	DeletedAt  *time.Time
 }
 
 // PaymentRepository defines persistence operations for payments.
 // Implemented by infrastructure/repository, consumed by application.
 type PaymentRepository interface {
-	Create(payment *Payment) (*Payment, error)
-	FindByStripeID(stripeID string) (*Payment, error)
-	UpdateStatus(payment *Payment) (*Payment, error)
+	// WithTenant returns a repository restricted to payments of orgID. Zero means unscoped.
+	WithTenant(orgID uint) PaymentRepository
+	Create(ctx context.Context, payment *Payment) (*Payment, error)
+	FindByStripeID(ctx context.Context, stripeID string) (*Payment, error)
+	FindByUserID(ctx context.Context, userID uint) ([]*Payment, error)
+	// UpdateStatus stores payment.PaymentStatus. With a non-zero FenceToken the
+	// write fails with ErrStaleToken if a newer token was already applied.
+	// Status changes are recorded as EventPaymentStatusChanged.
+	UpdateStatus(ctx context.Context, payment *Payment) (*Payment, error)
 }
 
 // StripeService defines external Stripe payment operations.
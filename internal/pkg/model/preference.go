@@ -0,0 +1,12 @@
+package model
+
+import "context"
+
+// PreferenceRepository defines persistence operations for per-user key/value preferences.
+// Implemented by infrastructure/repository, consumed by application.
+type PreferenceRepository interface {
+	// List returns only the preferences the user has explicitly set.
+	List(ctx context.Context, userID uint) (map[string]string, error)
+	// Set upserts the given preferences in one transaction.
+	Set(ctx context.Context, userID uint, prefs map[string]string) error
+}
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+// UserExport is the data-subject access bundle for a single user.
+type UserExport struct {
+	User        *User             `json:"user"`
+	Preferences map[string]string `json:"preferences"`
+	Payments    []*Payment        `json:"payments"`
+	Sessions    *SessionSummary   `json:"sessions"`
+	AuditLog    []*AuditEntry     `json:"audit_log"`
+	ExportedAt  time.Time         `json:"exported_at"`
+}
+
+// SessionSummary describes a user's sessions. Sessions are stateless JWTs,
+// so none is stored: each token carries TokenVersion and one of Memberships,
+// and bumping TokenVersion revokes them all.
+type SessionSummary struct {
+	TokenVersion uint          `json:"token_version"`
+	Memberships  []*Membership `json:"memberships"`
+}
+
+// AuditEntry is one recorded event in the history of a user's account.
+type AuditEntry struct {
+	At     time.Time `json:"at"`
+	Action string    `json:"action"` // e.g. "email_change.confirmed"
+	Detail string    `json:"detail,omitempty"`
+}
+
+// ErasureReceipt records that a user's personal data was erased.
+type ErasureReceipt struct {
+	ID          uint      `json:"id"`
+	UserID      uint      `json:"user_id"`
+	RequestedBy uint      `json:"requested_by"`
+	ErasedAt    time.Time `json:"erased_at"`
+}
+
+// PrivacyRepository defines persistence operations for data-subject requests.
+// Implemented by infrastructure/repository, consumed by application.
+type PrivacyRepository interface {
+	// Memberships lists the organizations the user belongs to, with their roles.
+	Memberships(ctx context.Context, userID uint) ([]*Membership, error)
+	// AuditLog lists the recorded events of the user's account, oldest first:
+	// memberships, invitations, email changes and erasures they requested.
+	AuditLog(ctx context.Context, userID uint) ([]*AuditEntry, error)
+	// Erase anonymizes the user's PII, deletes their preferences, email
+	// changes and the invitations sent to their address, and stores a
+	// receipt, all in one transaction.
+	Erase(ctx context.Context, userID string, requestedBy uint) (*ErasureReceipt, error)
+}
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimit allows Rate requests per Period on average, with bursts of up to
+// Burst requests at once.
+type RateLimit struct {
+	Rate   int
+	Period time.Duration
+	Burst  int
+}
+
+// ParseRateLimit parses "<rate>/<period>" or "<rate>/<period>/<burst>", e.g.
+// "10/1m" or "100/1s/200". Burst defaults to rate.
+func ParseRateLimit(s string) (RateLimit, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) != 2 && len(parts) != 3 {
+		return RateLimit{}, fmt.Errorf("rate limit %q: want <rate>/<period>[/<burst>]", s)
+	}
+	rate, err := strconv.Atoi(parts[0])
+	if err != nil || rate <= 0 {
+		return RateLimit{}, fmt.Errorf("rate limit %q: rate must be a positive integer", s)
+	}
+	period, err := time.ParseDuration(parts[1])
+	if err != nil || period <= 0 {
+		return RateLimit{}, fmt.Errorf("rate limit %q: period must be a positive duration", s)
+	}
+	limit := RateLimit{Rate: rate, Period: period, Burst: rate}
+	// The limiters divide by the interval, and the Redis one counts in microseconds.
+	if limit.Interval() < time.Microsecond {
+		return RateLimit{}, fmt.Errorf("rate limit %q: period/rate must be at least 1µs", s)
+	}
+	if len(parts) == 3 {
+		if limit.Burst, err = strconv.Atoi(parts[2]); err != nil || limit.Burst <= 0 {
+			return RateLimit{}, fmt.Errorf("rate limit %q: burst must be a positive integer", s)
+		}
+	}
+	return limit, nil
+}
+
+func (l RateLimit) String() string {
+	return fmt.Sprintf("%d/%s/%d", l.Rate, l.Period, l.Burst)
+}
+
+// Interval is the time it takes to earn back one request.
+func (l RateLimit) Interval() time.Duration {
+	return l.Period / time.Duration(l.Rate)
+}
+
+// RateLimitResult describes one rate limiting decision.
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int // the burst size
+	Remaining int
+	// RetryAfter is how long until a denied request would be allowed.
+	RetryAfter time.Duration
+	// ResetAfter is how long until the full burst is available again.
+	ResetAfter time.Duration
+}
+
+// RateLimiter counts requests against per-key limits.
+// Implemented by infrastructure/cache, consumed by transport middleware.
+type RateLimiter interface {
+	Allow(ctx context.Context, key string, limit RateLimit) (*RateLimitResult, error)
+}
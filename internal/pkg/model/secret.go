@@ -0,0 +1,10 @@
+package model
+
+import "context"
+
+// SecretProvider reads secrets from an external store such as Vault.
+// Implemented by infrastructure/secrets, consumed by config.
+type SecretProvider interface {
+	// Secret returns the value stored under key in the secret at path.
+	Secret(ctx context.Context, path, key string) (string, error)
+}
@@ -0,0 +1,13 @@
+package model
+
+import "context"
+
+// TxManager runs a unit of work in a database transaction.
+// Implemented by repository, consumed by application.
+type TxManager interface {
+	// WithinTx calls fn in a transaction that commits if fn returns nil and
+	// rolls back otherwise. Repository calls made with the ctx passed to fn
+	// join the transaction. Called with such a ctx, WithinTx nests: fn runs
+	// under a savepoint, so its failure only undoes its own writes.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
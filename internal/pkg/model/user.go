@@ -1,24 +1,58 @@
 package model
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // User represents the user domain entity.
 type User struct {
-	ID        uint
-	Name      string
-	Email     string
-	Password  string
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	DeletedAt *time.Time
+	ID           uint
+	Name         string
+	Email        string
+	Password     string `json:"-"`
+	DisplayName  string
+	Locale       string // BCP 47 tag, e.g. "en-US"
+	Timezone     string // IANA zone, e.g. "Europe/Berlin"
+	Phone        string // E.164, e.g. "+14155550123"
+	AvatarKey    string `json:"-"` // blob key prefix of the avatar thumbnails; empty if none
+	TokenVersion uint   `json:"-"` // embedded in issued JWTs; bumping it revokes them all
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	DeletedAt    *time.Time
+
+	// AvatarURLs holds signed thumbnail URLs keyed by size. It is filled in
+	// per response and never persisted.
+	AvatarURLs map[string]string `json:"avatar_urls,omitempty"`
 }
 
 // UserRepository defines persistence operations for users.
 // Implemented by infrastructure/repository, consumed by application.
 type UserRepository interface {
-	Create(user *User) (*User, error)
-	FindByID(id string) (*User, error)
-	FindByEmail(email string) (*User, error)
-	Update(id string, data *User) (*User, error)
-	Delete(id string) error
+	// WithTenant returns a repository whose lookups only see members of orgID
+	// and whose inserts add a member membership. Zero means unscoped.
+	WithTenant(orgID uint) UserRepository
+	// Create and CreateBatch record EventUserCreated for each user.
+	Create(ctx context.Context, user *User) (*User, error)
+	// CreateBatch inserts all users in a single transaction; either all or none are stored.
+	CreateBatch(ctx context.Context, users []*User) ([]*User, error)
+	FindByID(ctx context.Context, id string) (*User, error)
+	// FindByEmail is never tenant-scoped: email is the global login identity.
+	FindByEmail(ctx context.Context, email string) (*User, error)
+	// ExistingEmails returns those of emails that already belong to a user,
+	// in one query. Like FindByEmail it is never tenant-scoped.
+	ExistingEmails(ctx context.Context, emails []string) ([]string, error)
+	// LockByID is FindByID that also keeps the user from being changed or
+	// deleted until the transaction ctx carries ends; see TxManager.
+	LockByID(ctx context.Context, id string) (*User, error)
+	// Update changes the profile fields; the password is left untouched.
+	Update(ctx context.Context, id string, data *User) (*User, error)
+	// UpdatePassword hashes and stores a new password and bumps TokenVersion,
+	// revoking the tokens issued before.
+	UpdatePassword(ctx context.Context, id string, password string) error
+	// UpdateAvatar stores a new avatar key and returns the one it replaced.
+	UpdateAvatar(ctx context.Context, id string, avatarKey string) (previous string, err error)
+	Delete(ctx context.Context, id string) error
+	// OrgIDs lists the organizations the user belongs to.
+	OrgIDs(ctx context.Context, id string) ([]uint, error)
 }
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-gin-project/internal/pkg/model"
+
+	"gorm.io/gorm"
+)
+
+type emailChangeRepository struct {
+	db *gorm.DB
+}
+
+// NewEmailChangeRepository creates a GORM-backed model.EmailChangeRepository.
+func NewEmailChangeRepository(db *gorm.DB) model.EmailChangeRepository {
+	return &emailChangeRepository{db: db}
+}
+
+func (r *emailChangeRepository) Create(ctx context.Context, change *model.EmailChange) (*model.EmailChange, error) {
+	m := &emailChangeModel{
+		UserID:    change.UserID,
+		NewEmail:  change.NewEmail,
+		TokenHash: change.TokenHash,
+		ExpiresAt: change.ExpiresAt,
+	}
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND confirmed_at IS NULL", change.UserID).Delete(&emailChangeModel{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(m).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create email change: %w", err)
+	}
+	return toEmailChangeDomain(m), nil
+}
+
+func (r *emailChangeRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*model.EmailChange, error) {
+	var m emailChangeModel
+	if err := reader(ctx, r.db).Where("token_hash = ?", tokenHash).First(&m).Error; err != nil {
+		return nil, fmt.Errorf("find email change: %w", notFound(err))
+	}
+	return toEmailChangeDomain(&m), nil
+}
+
+func (r *emailChangeRepository) Confirm(ctx context.Context, change *model.EmailChange) (*model.User, error) {
+	var user userModel
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		claim := tx.Model(&emailChangeModel{}).
+			Where("id = ? AND confirmed_at IS NULL AND expires_at > ?", change.ID, now).
+			Update("confirmed_at", now)
+		if claim.Error != nil {
+			return claim.Error
+		}
+		if claim.RowsAffected == 0 {
+			return errors.New("email change is no longer valid")
+		}
+
+		var existing userModel
+		if err := tx.Where("email = ?", change.NewEmail).First(&existing).Error; err == nil {
+			return errors.New("email address is already in use")
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if err := tx.First(&user, change.UserID).Error; err != nil {
+			return err
+		}
+		user.Email = change.NewEmail
+		user.TokenVersion++
+		return duplicate(tx, tx.Save(&user).Error)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("confirm email change: %w", err)
+	}
+
+	result := toUserDomain(&user)
+	result.Password = ""
+	return result, nil
+}
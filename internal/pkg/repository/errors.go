@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"go-gin-project/internal/pkg/model"
+
+	"gorm.io/gorm"
+)
+
+// notFound tags gorm.ErrRecordNotFound with model.ErrNotFound so callers can
+// test for it without importing GORM; other errors pass through unchanged.
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("%w: %w", model.ErrNotFound, err)
+	}
+	return err
+}
+
+// duplicate tags a unique index violation with model.ErrDuplicate. Drivers
+// report these differently (MySQL error 1062, PostgreSQL 23505, SQLite
+// constraint errors), so db's dialect translates err first.
+func duplicate(db *gorm.DB, err error) error {
+	if t, ok := db.Dialector.(gorm.ErrorTranslator); ok && !errors.Is(err, gorm.ErrDuplicatedKey) {
+		err = t.Translate(err)
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return fmt.Errorf("%w: %w", model.ErrDuplicate, err)
+	}
+	return err
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-gin-project/internal/pkg/model"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type invitationRepository struct {
+	db *gorm.DB
+}
+
+// NewInvitationRepository creates a GORM-backed model.InvitationRepository.
+func NewInvitationRepository(db *gorm.DB) model.InvitationRepository {
+	return &invitationRepository{db: db}
+}
+
+func (r *invitationRepository) Create(ctx context.Context, inv *model.Invitation) (*model.Invitation, error) {
+	m := &invitationModel{
+		OrgID:     inv.OrgID,
+		Email:     inv.Email,
+		Role:      inv.Role,
+		TokenHash: inv.TokenHash,
+		InvitedBy: inv.InvitedBy,
+		ExpiresAt: inv.ExpiresAt,
+	}
+	if err := conn(ctx, r.db).Create(m).Error; err != nil {
+		return nil, fmt.Errorf("create invitation: %w", err)
+	}
+	return toInvitationDomain(m), nil
+}
+
+func (r *invitationRepository) ListByOrg(ctx context.Context, orgID uint) ([]*model.Invitation, error) {
+	var ms []invitationModel
+	if err := reader(ctx, r.db).Where("org_id = ?", orgID).Order("id DESC").Find(&ms).Error; err != nil {
+		return nil, fmt.Errorf("list invitations: %w", err)
+	}
+	invitations := make([]*model.Invitation, 0, len(ms))
+	for i := range ms {
+		invitations = append(invitations, toInvitationDomain(&ms[i]))
+	}
+	return invitations, nil
+}
+
+func (r *invitationRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*model.Invitation, error) {
+	var m invitationModel
+	if err := reader(ctx, r.db).Where("token_hash = ?", tokenHash).First(&m).Error; err != nil {
+		return nil, fmt.Errorf("find invitation: %w", notFound(err))
+	}
+	return toInvitationDomain(&m), nil
+}
+
+func (r *invitationRepository) Revoke(ctx context.Context, orgID, id uint) error {
+	result := conn(ctx, r.db).Model(&invitationModel{}).
+		Where("id = ? AND org_id = ? AND accepted_at IS NULL AND revoked_at IS NULL", id, orgID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return fmt.Errorf("revoke invitation: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("revoke invitation: %w", notFound(gorm.ErrRecordNotFound))
+	}
+	return nil
+}
+
+func (r *invitationRepository) Delete(ctx context.Context, id uint) error {
+	if err := conn(ctx, r.db).Delete(&invitationModel{}, id).Error; err != nil {
+		return fmt.Errorf("delete invitation: %w", err)
+	}
+	return nil
+}
+
+func (r *invitationRepository) Accept(ctx context.Context, inv *model.Invitation, user *model.User) (*model.User, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("accept invitation: hash password: %w", err)
+	}
+	m := toUserModel(user)
+	m.Email = inv.Email
+	m.Password = string(hashed)
+
+	err = conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := claim(tx, inv); err != nil {
+			return err
+		}
+
+		var existing userModel
+		if err := tx.Where("email = ?", inv.Email).First(&existing).Error; err == nil {
+			return errors.New("user already exists")
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if err := tx.Create(m).Error; err != nil {
+			return duplicate(tx, err)
+		}
+		if err := tx.Create(&membershipModel{OrgID: inv.OrgID, UserID: m.ID, Role: inv.Role}).Error; err != nil {
+			return err
+		}
+		return recordEvent(tx, model.AggregateUser, m.ID, model.EventUserCreated, model.UserCreated{UserID: m.ID, OrgID: inv.OrgID})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("accept invitation: %w", err)
+	}
+
+	result := toUserDomain(m)
+	result.Password = ""
+	return result, nil
+}
+
+func (r *invitationRepository) Join(ctx context.Context, inv *model.Invitation, userID uint) (*model.Membership, error) {
+	m := &membershipModel{OrgID: inv.OrgID, UserID: userID, Role: inv.Role}
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := claim(tx, inv); err != nil {
+			return err
+		}
+		if err := tx.Create(m).Error; err != nil {
+			return duplicate(tx, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("join organization: %w", err)
+	}
+	return toMembershipDomain(m), nil
+}
+
+// claim marks inv accepted, first thing in the transaction, so that a
+// concurrent accept of the same token fails.
+func claim(tx *gorm.DB, inv *model.Invitation) error {
+	now := time.Now()
+	result := tx.Model(&invitationModel{}).
+		Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", inv.ID, now).
+		Update("accepted_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("invitation is no longer valid")
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+// Package migrations embeds the versioned SQL schema migrations, one
+// directory per database driver. Every directory holds the same versions;
+// add new ones with "go run ./cmd/migrate create <name>".
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+)
+
+// Dialects are the database drivers with migrations, named as by
+// gorm.Dialector.Name.
+var Dialects = []string{"mysql", "postgres", "sqlite"}
+
+//go:embed mysql postgres sqlite
+var files embed.FS
+
+// For returns the <version>_<name>.up.sql and .down.sql files for dialect.
+func For(dialect string) (fs.FS, error) {
+	for _, d := range Dialects {
+		if d == dialect {
+			return fs.Sub(files, d)
+		}
+	}
+	return nil, fmt.Errorf("no migrations for database %q", dialect)
+}
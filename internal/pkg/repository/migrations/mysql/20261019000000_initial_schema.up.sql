@@ -0,0 +1,31 @@
+-- Baseline: the schema GORM AutoMigrate created before versioned
+-- migrations. Databases created that way are adopted at this version
+-- without running it (see migrate.Migrator.Adopt), and take the later
+-- migrations from here.
+
+CREATE TABLE users (
+  id bigint unsigned NOT NULL AUTO_INCREMENT,
+  name varchar(255) NOT NULL,
+  email varchar(255) NOT NULL,
+  password varchar(255) NOT NULL,
+  created_at datetime(3) NULL,
+  updated_at datetime(3) NULL,
+  deleted_at datetime(3) NULL,
+  PRIMARY KEY (id),
+  UNIQUE INDEX idx_users_email (email),
+  INDEX idx_users_deleted_at (deleted_at)
+);
+
+CREATE TABLE payments (
+  id bigint unsigned NOT NULL AUTO_INCREMENT,
+  user_id bigint unsigned,
+  amount decimal(10,2) NOT NULL,
+  currency varchar(3) NOT NULL,
+  stripe_id varchar(255) NOT NULL,
+  payment_status varchar(255) NOT NULL,
+  created_at datetime(3) NULL,
+  updated_at datetime(3) NULL,
+  deleted_at datetime(3) NULL,
+  PRIMARY KEY (id),
+  INDEX idx_payments_deleted_at (deleted_at)
+);
@@ -0,0 +1,81 @@
+-- Organizations, memberships and the account features added since the baseline.
+
+ALTER TABLE users
+  ADD COLUMN display_name varchar(255),
+  ADD COLUMN locale varchar(35),
+  ADD COLUMN timezone varchar(64),
+  ADD COLUMN phone varchar(32),
+  ADD COLUMN avatar_key varchar(255),
+  ADD COLUMN token_version bigint unsigned NOT NULL DEFAULT 0;
+
+ALTER TABLE payments
+  ADD COLUMN org_id bigint unsigned,
+  ADD COLUMN fence_token bigint NOT NULL DEFAULT 0,
+  ADD INDEX idx_payments_org_id (org_id);
+
+CREATE TABLE erasure_receipts (
+  id bigint unsigned NOT NULL AUTO_INCREMENT,
+  user_id bigint unsigned NOT NULL,
+  requested_by bigint unsigned NOT NULL,
+  erased_at datetime(3) NULL,
+  PRIMARY KEY (id),
+  INDEX idx_erasure_receipts_user_id (user_id)
+);
+
+CREATE TABLE organizations (
+  id bigint unsigned NOT NULL AUTO_INCREMENT,
+  name varchar(255) NOT NULL,
+  created_at datetime(3) NULL,
+  updated_at datetime(3) NULL,
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE memberships (
+  id bigint unsigned NOT NULL AUTO_INCREMENT,
+  org_id bigint unsigned NOT NULL,
+  user_id bigint unsigned NOT NULL,
+  role varchar(32) NOT NULL,
+  created_at datetime(3) NULL,
+  PRIMARY KEY (id),
+  UNIQUE INDEX idx_memberships_org_user (org_id, user_id),
+  INDEX idx_memberships_user_id (user_id)
+);
+
+CREATE TABLE invitations (
+  id bigint unsigned NOT NULL AUTO_INCREMENT,
+  org_id bigint unsigned NOT NULL,
+  email varchar(255) NOT NULL,
+  role varchar(32) NOT NULL,
+  token_hash char(64) NOT NULL,
+  invited_by bigint unsigned NOT NULL,
+  expires_at datetime(3) NULL,
+  accepted_at datetime(3) NULL,
+  revoked_at datetime(3) NULL,
+  created_at datetime(3) NULL,
+  PRIMARY KEY (id),
+  INDEX idx_invitations_org_id (org_id),
+  UNIQUE INDEX idx_invitations_token_hash (token_hash)
+);
+
+CREATE TABLE email_changes (
+  id bigint unsigned NOT NULL AUTO_INCREMENT,
+  user_id bigint unsigned NOT NULL,
+  new_email varchar(255) NOT NULL,
+  token_hash char(64) NOT NULL,
+  expires_at datetime(3) NULL,
+  confirmed_at datetime(3) NULL,
+  created_at datetime(3) NULL,
+  PRIMARY KEY (id),
+  INDEX idx_email_changes_user_id (user_id),
+  UNIQUE INDEX idx_email_changes_token_hash (token_hash)
+);
+
+CREATE TABLE user_preferences (
+  id bigint unsigned NOT NULL AUTO_INCREMENT,
+  user_id bigint unsigned NOT NULL,
+  `key` varchar(64) NOT NULL,
+  value varchar(255) NOT NULL,
+  updated_at datetime(3) NULL,
+  PRIMARY KEY (id),
+  UNIQUE INDEX idx_user_preferences_user_key (user_id, `key`)
+);
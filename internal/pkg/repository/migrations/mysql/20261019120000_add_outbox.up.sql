@@ -0,0 +1,17 @@
+-- Domain events waiting to be published; published events are deleted.
+CREATE TABLE outbox_events (
+  id bigint unsigned NOT NULL AUTO_INCREMENT,
+  aggregate_type varchar(32) NOT NULL,
+  aggregate_id varchar(64) NOT NULL,
+  event_type varchar(64) NOT NULL,
+  payload text NOT NULL,
+  created_at datetime(3) NULL,
+  attempts bigint NOT NULL DEFAULT 0,
+  next_attempt_at datetime(3) NOT NULL,
+  last_error text,
+  dead_at datetime(3) NULL,
+  PRIMARY KEY (id),
+  INDEX idx_outbox_events_aggregate (aggregate_type, aggregate_id),
+  INDEX idx_outbox_events_next_attempt_at (next_attempt_at),
+  INDEX idx_outbox_events_dead_at (dead_at)
+);
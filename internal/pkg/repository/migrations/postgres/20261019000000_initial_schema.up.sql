@@ -0,0 +1,29 @@
+-- Baseline: the schema GORM AutoMigrate created before versioned
+-- migrations. Databases created that way are adopted at this version
+-- without running it (see migrate.Migrator.Adopt), and take the later
+-- migrations from here.
+
+CREATE TABLE users (
+  id bigserial PRIMARY KEY,
+  name varchar(255) NOT NULL,
+  email varchar(255) NOT NULL,
+  password varchar(255) NOT NULL,
+  created_at timestamptz,
+  updated_at timestamptz,
+  deleted_at timestamptz
+);
+CREATE UNIQUE INDEX idx_users_email ON users (email);
+CREATE INDEX idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE payments (
+  id bigserial PRIMARY KEY,
+  user_id bigint,
+  amount numeric(10,2) NOT NULL,
+  currency varchar(3) NOT NULL,
+  stripe_id varchar(255) NOT NULL,
+  payment_status varchar(255) NOT NULL,
+  created_at timestamptz,
+  updated_at timestamptz,
+  deleted_at timestamptz
+);
+CREATE INDEX idx_payments_deleted_at ON payments (deleted_at);
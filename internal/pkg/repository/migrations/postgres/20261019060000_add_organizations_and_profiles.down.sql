@@ -0,0 +1,16 @@
+DROP TABLE IF EXISTS user_preferences;
+DROP TABLE IF EXISTS email_changes;
+DROP TABLE IF EXISTS invitations;
+DROP TABLE IF EXISTS memberships;
+DROP TABLE IF EXISTS organizations;
+DROP TABLE IF EXISTS erasure_receipts;
+ALTER TABLE payments
+  DROP COLUMN org_id,
+  DROP COLUMN fence_token;
+ALTER TABLE users
+  DROP COLUMN display_name,
+  DROP COLUMN locale,
+  DROP COLUMN timezone,
+  DROP COLUMN phone,
+  DROP COLUMN avatar_key,
+  DROP COLUMN token_version;
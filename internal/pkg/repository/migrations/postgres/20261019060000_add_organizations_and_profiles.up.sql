@@ -0,0 +1,75 @@
+-- Organizations, memberships and the account features added since the baseline.
+
+ALTER TABLE users
+  ADD COLUMN display_name varchar(255),
+  ADD COLUMN locale varchar(35),
+  ADD COLUMN timezone varchar(64),
+  ADD COLUMN phone varchar(32),
+  ADD COLUMN avatar_key varchar(255),
+  ADD COLUMN token_version bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE payments
+  ADD COLUMN org_id bigint,
+  ADD COLUMN fence_token bigint NOT NULL DEFAULT 0;
+CREATE INDEX idx_payments_org_id ON payments (org_id);
+
+CREATE TABLE erasure_receipts (
+  id bigserial PRIMARY KEY,
+  user_id bigint NOT NULL,
+  requested_by bigint NOT NULL,
+  erased_at timestamptz
+);
+CREATE INDEX idx_erasure_receipts_user_id ON erasure_receipts (user_id);
+
+CREATE TABLE organizations (
+  id bigserial PRIMARY KEY,
+  name varchar(255) NOT NULL,
+  created_at timestamptz,
+  updated_at timestamptz
+);
+
+CREATE TABLE memberships (
+  id bigserial PRIMARY KEY,
+  org_id bigint NOT NULL,
+  user_id bigint NOT NULL,
+  role varchar(32) NOT NULL,
+  created_at timestamptz
+);
+CREATE UNIQUE INDEX idx_memberships_org_user ON memberships (org_id, user_id);
+CREATE INDEX idx_memberships_user_id ON memberships (user_id);
+
+CREATE TABLE invitations (
+  id bigserial PRIMARY KEY,
+  org_id bigint NOT NULL,
+  email varchar(255) NOT NULL,
+  role varchar(32) NOT NULL,
+  token_hash char(64) NOT NULL,
+  invited_by bigint NOT NULL,
+  expires_at timestamptz,
+  accepted_at timestamptz,
+  revoked_at timestamptz,
+  created_at timestamptz
+);
+CREATE INDEX idx_invitations_org_id ON invitations (org_id);
+CREATE UNIQUE INDEX idx_invitations_token_hash ON invitations (token_hash);
+
+CREATE TABLE email_changes (
+  id bigserial PRIMARY KEY,
+  user_id bigint NOT NULL,
+  new_email varchar(255) NOT NULL,
+  token_hash char(64) NOT NULL,
+  expires_at timestamptz,
+  confirmed_at timestamptz,
+  created_at timestamptz
+);
+CREATE INDEX idx_email_changes_user_id ON email_changes (user_id);
+CREATE UNIQUE INDEX idx_email_changes_token_hash ON email_changes (token_hash);
+
+CREATE TABLE user_preferences (
+  id bigserial PRIMARY KEY,
+  user_id bigint NOT NULL,
+  "key" varchar(64) NOT NULL,
+  value varchar(255) NOT NULL,
+  updated_at timestamptz
+);
+CREATE UNIQUE INDEX idx_user_preferences_user_key ON user_preferences (user_id, "key");
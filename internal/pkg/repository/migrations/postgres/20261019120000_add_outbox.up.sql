@@ -0,0 +1,16 @@
+-- Domain events waiting to be published; published events are deleted.
+CREATE TABLE outbox_events (
+  id bigserial PRIMARY KEY,
+  aggregate_type varchar(32) NOT NULL,
+  aggregate_id varchar(64) NOT NULL,
+  event_type varchar(64) NOT NULL,
+  payload text NOT NULL,
+  created_at timestamptz,
+  attempts bigint NOT NULL DEFAULT 0,
+  next_attempt_at timestamptz NOT NULL,
+  last_error text,
+  dead_at timestamptz
+);
+CREATE INDEX idx_outbox_events_aggregate ON outbox_events (aggregate_type, aggregate_id);
+CREATE INDEX idx_outbox_events_next_attempt_at ON outbox_events (next_attempt_at);
+CREATE INDEX idx_outbox_events_dead_at ON outbox_events (dead_at);
@@ -0,0 +1,31 @@
+-- Baseline: the schema GORM AutoMigrate created before versioned
+-- migrations. Databases created that way are adopted at this version
+-- without running it (see migrate.Migrator.Adopt), and take the later
+-- migrations from here. SQLite ignores column sizes, and stores NUMERIC
+-- amounts with a fractional part as REAL rather than exact decimals, so it
+-- is meant for development and tests.
+
+CREATE TABLE users (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  name text NOT NULL,
+  email text NOT NULL,
+  password text NOT NULL,
+  created_at datetime,
+  updated_at datetime,
+  deleted_at datetime
+);
+CREATE UNIQUE INDEX idx_users_email ON users (email);
+CREATE INDEX idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE payments (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  user_id integer,
+  amount numeric NOT NULL,
+  currency text NOT NULL,
+  stripe_id text NOT NULL,
+  payment_status text NOT NULL,
+  created_at datetime,
+  updated_at datetime,
+  deleted_at datetime
+);
+CREATE INDEX idx_payments_deleted_at ON payments (deleted_at);
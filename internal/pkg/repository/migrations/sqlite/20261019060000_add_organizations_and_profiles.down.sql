@@ -0,0 +1,15 @@
+DROP TABLE IF EXISTS user_preferences;
+DROP TABLE IF EXISTS email_changes;
+DROP TABLE IF EXISTS invitations;
+DROP TABLE IF EXISTS memberships;
+DROP TABLE IF EXISTS organizations;
+DROP TABLE IF EXISTS erasure_receipts;
+DROP INDEX IF EXISTS idx_payments_org_id;
+ALTER TABLE payments DROP COLUMN org_id;
+ALTER TABLE payments DROP COLUMN fence_token;
+ALTER TABLE users DROP COLUMN display_name;
+ALTER TABLE users DROP COLUMN locale;
+ALTER TABLE users DROP COLUMN timezone;
+ALTER TABLE users DROP COLUMN phone;
+ALTER TABLE users DROP COLUMN avatar_key;
+ALTER TABLE users DROP COLUMN token_version;
@@ -0,0 +1,73 @@
+-- Organizations, memberships and the account features added since the baseline.
+
+ALTER TABLE users ADD COLUMN display_name text;
+ALTER TABLE users ADD COLUMN locale text;
+ALTER TABLE users ADD COLUMN timezone text;
+ALTER TABLE users ADD COLUMN phone text;
+ALTER TABLE users ADD COLUMN avatar_key text;
+ALTER TABLE users ADD COLUMN token_version integer NOT NULL DEFAULT 0;
+
+ALTER TABLE payments ADD COLUMN org_id integer;
+ALTER TABLE payments ADD COLUMN fence_token integer NOT NULL DEFAULT 0;
+CREATE INDEX idx_payments_org_id ON payments (org_id);
+
+CREATE TABLE erasure_receipts (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  user_id integer NOT NULL,
+  requested_by integer NOT NULL,
+  erased_at datetime
+);
+CREATE INDEX idx_erasure_receipts_user_id ON erasure_receipts (user_id);
+
+CREATE TABLE organizations (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  name text NOT NULL,
+  created_at datetime,
+  updated_at datetime
+);
+
+CREATE TABLE memberships (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  org_id integer NOT NULL,
+  user_id integer NOT NULL,
+  role text NOT NULL,
+  created_at datetime
+);
+CREATE UNIQUE INDEX idx_memberships_org_user ON memberships (org_id, user_id);
+CREATE INDEX idx_memberships_user_id ON memberships (user_id);
+
+CREATE TABLE invitations (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  org_id integer NOT NULL,
+  email text NOT NULL,
+  role text NOT NULL,
+  token_hash text NOT NULL,
+  invited_by integer NOT NULL,
+  expires_at datetime,
+  accepted_at datetime,
+  revoked_at datetime,
+  created_at datetime
+);
+CREATE INDEX idx_invitations_org_id ON invitations (org_id);
+CREATE UNIQUE INDEX idx_invitations_token_hash ON invitations (token_hash);
+
+CREATE TABLE email_changes (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  user_id integer NOT NULL,
+  new_email text NOT NULL,
+  token_hash text NOT NULL,
+  expires_at datetime,
+  confirmed_at datetime,
+  created_at datetime
+);
+CREATE INDEX idx_email_changes_user_id ON email_changes (user_id);
+CREATE UNIQUE INDEX idx_email_changes_token_hash ON email_changes (token_hash);
+
+CREATE TABLE user_preferences (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  user_id integer NOT NULL,
+  "key" text NOT NULL,
+  value text NOT NULL,
+  updated_at datetime
+);
+CREATE UNIQUE INDEX idx_user_preferences_user_key ON user_preferences (user_id, "key");
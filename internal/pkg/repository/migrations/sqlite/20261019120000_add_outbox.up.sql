@@ -0,0 +1,16 @@
+-- Domain events waiting to be published; published events are deleted.
+CREATE TABLE outbox_events (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  aggregate_type text NOT NULL,
+  aggregate_id text NOT NULL,
+  event_type text NOT NULL,
+  payload text NOT NULL,
+  created_at datetime,
+  attempts integer NOT NULL DEFAULT 0,
+  next_attempt_at datetime NOT NULL,
+  last_error text,
+  dead_at datetime
+);
+CREATE INDEX idx_outbox_events_aggregate ON outbox_events (aggregate_type, aggregate_id);
+CREATE INDEX idx_outbox_events_next_attempt_at ON outbox_events (next_attempt_at);
+CREATE INDEX idx_outbox_events_dead_at ON outbox_events (dead_at);
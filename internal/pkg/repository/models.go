@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"encoding/json"
 	"time"
 
 	"go-gin-project/internal/pkg/model"
@@ -10,13 +11,19 @@ import (
 
 // userModel is the GORM persistence model for User.
 type userModel struct {
-	ID        uint           `gorm:"primaryKey"`
-	Name      string         `gorm:"type:varchar(255);not null"`
-	Email     string         `gorm:"type:varchar(255);uniqueIndex;not null"`
-	Password  string         `gorm:"type:varchar(255);not null"`
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	DeletedAt gorm.DeletedAt `gorm:"index"`
+	ID           uint   `gorm:"primaryKey"`
+	Name         string `gorm:"type:varchar(255);not null"`
+	Email        string `gorm:"type:varchar(255);uniqueIndex;not null"`
+	Password     string `gorm:"type:varchar(255);not null"`
+	DisplayName  string `gorm:"type:varchar(255)"`
+	Locale       string `gorm:"type:varchar(35)"`
+	Timezone     string `gorm:"type:varchar(64)"`
+	Phone        string `gorm:"type:varchar(32)"`
+	AvatarKey    string `gorm:"type:varchar(255)"`
+	TokenVersion uint   `gorm:"not null;default:0"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	DeletedAt    gorm.DeletedAt `gorm:"index"`
 }
 
 func (userModel) TableName() string { return "users" }
@@ -27,33 +34,45 @@ func toUserDomain(m *userModel) *model.User {
 		deletedAt = &m.DeletedAt.Time
 	}
 	return &model.User{
-		ID:        m.ID,
-		Name:      m.Name,
-		Email:     m.Email,
-		Password:  m.Password,
-		CreatedAt: m.CreatedAt,
-		UpdatedAt: m.UpdatedAt,
-		DeletedAt: deletedAt,
+		ID:           m.ID,
+		Name:         m.Name,
+		Email:        m.Email,
+		Password:     m.Password,
+		DisplayName:  m.DisplayName,
+		Locale:       m.Locale,
+		Timezone:     m.Timezone,
+		Phone:        m.Phone,
+		AvatarKey:    m.AvatarKey,
+		TokenVersion: m.TokenVersion,
+		CreatedAt:    m.CreatedAt,
+		UpdatedAt:    m.UpdatedAt,
+		DeletedAt:    deletedAt,
 	}
 }
 
 func toUserModel(u *model.User) *userModel {
 	return &userModel{
-		ID:       u.ID,
-		Name:     u.Name,
-		Email:    u.Email,
-		Password: u.Password,
+		ID:          u.ID,
+		Name:        u.Name,
+		Email:       u.Email,
+		Password:    u.Password,
+		DisplayName: u.DisplayName,
+		Locale:      u.Locale,
+		Timezone:    u.Timezone,
+		Phone:       u.Phone,
 	}
 }
 
 // paymentModel is the GORM persistence model for Payment.
 type paymentModel struct {
-	ID            uint           `gorm:"primaryKey"`
+	ID            uint `gorm:"primaryKey"`
+	OrgID         uint `gorm:"index"`
 	UserID        uint
-	Amount        float64        `gorm:"type:decimal(10,2);not null"`
-	Currency      string         `gorm:"type:varchar(3);not null"`
-	StripeID      string         `gorm:"type:varchar(255);not null"`
-	PaymentStatus string         `gorm:"type:varchar(255);not null"`
+	Amount        float64 `gorm:"type:decimal(10,2);not null"`
+	Currency      string  `gorm:"type:varchar(3);not null"`
+	StripeID      string  `gorm:"type:varchar(255);not null"`
+	PaymentStatus string  `gorm:"type:varchar(255);not null"`
+	FenceToken    int64   `gorm:"not null;default:0"`
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 	DeletedAt     gorm.DeletedAt `gorm:"index"`
@@ -68,11 +87,13 @@ func toPaymentDomain(m *paymentModel) *model.Payment {
 	}
 	return &model.Payment{
 		ID:            m.ID,
+		OrgID:         m.OrgID,
 		UserID:        m.UserID,
 		Amount:        m.Amount,
 		Currency:      m.Currency,
 		StripeID:      m.StripeID,
 		PaymentStatus: m.PaymentStatus,
+		FenceToken:    m.FenceToken,
 		CreatedAt:     m.CreatedAt,
 		UpdatedAt:     m.UpdatedAt,
 		DeletedAt:     deletedAt,
@@ -82,6 +103,7 @@ func toPaymentDomain(m *paymentModel) *model.Payment {
 func toPaymentModel(p *model.Payment) *paymentModel {
 	return &paymentModel{
 		ID:            p.ID,
+		OrgID:         p.OrgID,
 		UserID:        p.UserID,
 		Amount:        p.Amount,
 		Currency:      p.Currency,
@@ -90,7 +112,176 @@ func toPaymentModel(p *model.Payment) *paymentModel {
 	}
 }
 
-// Migrate runs GORM AutoMigrate for all repository models.
-func Migrate(db *gorm.DB) error {
-	return db.AutoMigrate(&userModel{}, &paymentModel{})
+// organizationModel is the GORM persistence model for Organization.
+type organizationModel struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"type:varchar(255);not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (organizationModel) TableName() string { return "organizations" }
+
+func toOrganizationDomain(m *organizationModel) *model.Organization {
+	return &model.Organization{
+		ID:        m.ID,
+		Name:      m.Name,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
+
+// membershipModel is the GORM persistence model for Membership.
+type membershipModel struct {
+	ID        uint   `gorm:"primaryKey"`
+	OrgID     uint   `gorm:"uniqueIndex:idx_memberships_org_user;not null"`
+	UserID    uint   `gorm:"uniqueIndex:idx_memberships_org_user;index;not null"`
+	Role      string `gorm:"type:varchar(32);not null"`
+	CreatedAt time.Time
+}
+
+func (membershipModel) TableName() string { return "memberships" }
+
+func toMembershipDomain(m *membershipModel) *model.Membership {
+	return &model.Membership{
+		ID:        m.ID,
+		OrgID:     m.OrgID,
+		UserID:    m.UserID,
+		Role:      m.Role,
+		CreatedAt: m.CreatedAt,
+	}
+}
+
+// invitationModel is the GORM persistence model for Invitation.
+type invitationModel struct {
+	ID         uint   `gorm:"primaryKey"`
+	OrgID      uint   `gorm:"index;not null"`
+	Email      string `gorm:"type:varchar(255);not null"`
+	Role       string `gorm:"type:varchar(32);not null"`
+	TokenHash  string `gorm:"type:char(64);uniqueIndex;not null"`
+	InvitedBy  uint   `gorm:"not null"`
+	ExpiresAt  time.Time
+	AcceptedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
+
+func (invitationModel) TableName() string { return "invitations" }
+
+func toInvitationDomain(m *invitationModel) *model.Invitation {
+	return &model.Invitation{
+		ID:         m.ID,
+		OrgID:      m.OrgID,
+		Email:      m.Email,
+		Role:       m.Role,
+		TokenHash:  m.TokenHash,
+		InvitedBy:  m.InvitedBy,
+		ExpiresAt:  m.ExpiresAt,
+		AcceptedAt: m.AcceptedAt,
+		RevokedAt:  m.RevokedAt,
+		CreatedAt:  m.CreatedAt,
+	}
+}
+
+// emailChangeModel is the GORM persistence model for EmailChange.
+type emailChangeModel struct {
+	ID          uint   `gorm:"primaryKey"`
+	UserID      uint   `gorm:"index;not null"`
+	NewEmail    string `gorm:"type:varchar(255);not null"`
+	TokenHash   string `gorm:"type:char(64);uniqueIndex;not null"`
+	ExpiresAt   time.Time
+	ConfirmedAt *time.Time
+	CreatedAt   time.Time
+}
+
+func (emailChangeModel) TableName() string { return "email_changes" }
+
+func toEmailChangeDomain(m *emailChangeModel) *model.EmailChange {
+	return &model.EmailChange{
+		ID:          m.ID,
+		UserID:      m.UserID,
+		NewEmail:    m.NewEmail,
+		TokenHash:   m.TokenHash,
+		ExpiresAt:   m.ExpiresAt,
+		ConfirmedAt: m.ConfirmedAt,
+		CreatedAt:   m.CreatedAt,
+	}
+}
+
+// preferenceModel is the GORM persistence model for one user preference.
+type preferenceModel struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"uniqueIndex:idx_user_preferences_user_key;not null"`
+	Key       string `gorm:"type:varchar(64);uniqueIndex:idx_user_preferences_user_key;not null"`
+	Value     string `gorm:"type:varchar(255);not null"`
+	UpdatedAt time.Time
+}
+
+func (preferenceModel) TableName() string { return "user_preferences" }
+
+// erasureReceiptModel is the GORM persistence model for ErasureReceipt.
+type erasureReceiptModel struct {
+	ID          uint `gorm:"primaryKey"`
+	UserID      uint `gorm:"index;not null"`
+	RequestedBy uint `gorm:"not null"`
+	ErasedAt    time.Time
+}
+
+func (erasureReceiptModel) TableName() string { return "erasure_receipts" }
+
+func toErasureReceiptDomain(m *erasureReceiptModel) *model.ErasureReceipt {
+	return &model.ErasureReceipt{
+		ID:          m.ID,
+		UserID:      m.UserID,
+		RequestedBy: m.RequestedBy,
+		ErasedAt:    m.ErasedAt,
+	}
+}
+
+// outboxEventModel is the GORM persistence model for Event. Published events
+// are deleted; dead-lettered ones stay with DeadAt set.
+type outboxEventModel struct {
+	ID            uint   `gorm:"primaryKey"`
+	AggregateType string `gorm:"type:varchar(32);not null;index:idx_outbox_events_aggregate,priority:1"`
+	AggregateID   string `gorm:"type:varchar(64);not null;index:idx_outbox_events_aggregate,priority:2"`
+	EventType     string `gorm:"type:varchar(64);not null"`
+	Payload       string `gorm:"type:text;not null"`
+	CreatedAt     time.Time
+	Attempts      int        `gorm:"not null;default:0"`
+	NextAttemptAt time.Time  `gorm:"not null;index"`
+	LastError     string     `gorm:"type:text"`
+	DeadAt        *time.Time `gorm:"index"`
+}
+
+func (outboxEventModel) TableName() string { return "outbox_events" }
+
+func toEventDomain(m *outboxEventModel) *model.Event {
+	return &model.Event{
+		ID:            m.ID,
+		Type:          m.EventType,
+		AggregateType: m.AggregateType,
+		AggregateID:   m.AggregateID,
+		Payload:       json.RawMessage(m.Payload),
+		CreatedAt:     m.CreatedAt,
+		Attempts:      m.Attempts,
+		LastError:     m.LastError,
+		DeadAt:        m.DeadAt,
+	}
+}
+
+// AutoMigrate creates or alters tables to match the repository models with
+// GORM AutoMigrate. It is meant for development databases; others are
+// changed by the versioned migrations in package migrations.
+func AutoMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&userModel{},
+		&paymentModel{},
+		&erasureReceiptModel{},
+		&organizationModel{},
+		&membershipModel{},
+		&invitationModel{},
+		&emailChangeModel{},
+		&preferenceModel{},
+		&outboxEventModel{},
+	)
 }
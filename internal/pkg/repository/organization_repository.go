@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"go-gin-project/internal/pkg/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type organizationRepository struct {
+	db *gorm.DB
+}
+
+// NewOrganizationRepository creates a GORM-backed model.OrganizationRepository.
+func NewOrganizationRepository(db *gorm.DB) model.OrganizationRepository {
+	return &organizationRepository{db: db}
+}
+
+func (r *organizationRepository) Create(ctx context.Context, org *model.Organization, ownerID uint) (*model.Organization, error) {
+	m := &organizationModel{Name: org.Name}
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(m).Error; err != nil {
+			return err
+		}
+		return tx.Create(&membershipModel{OrgID: m.ID, UserID: ownerID, Role: model.RoleOwner}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create organization: %w", err)
+	}
+	return toOrganizationDomain(m), nil
+}
+
+func (r *organizationRepository) FindByID(ctx context.Context, id uint) (*model.Organization, error) {
+	var m organizationModel
+	if err := reader(ctx, r.db).First(&m, id).Error; err != nil {
+		return nil, fmt.Errorf("find organization: %w", notFound(err))
+	}
+	return toOrganizationDomain(&m), nil
+}
+
+func (r *organizationRepository) ListForUser(ctx context.Context, userID uint) ([]*model.Organization, error) {
+	var ms []organizationModel
+	err := reader(ctx, r.db).
+		Joins("JOIN memberships ON memberships.org_id = organizations.id").
+		Where("memberships.user_id = ?", userID).
+		Order("organizations.id").
+		Find(&ms).Error
+	if err != nil {
+		return nil, fmt.Errorf("list organizations: %w", err)
+	}
+	orgs := make([]*model.Organization, 0, len(ms))
+	for i := range ms {
+		orgs = append(orgs, toOrganizationDomain(&ms[i]))
+	}
+	return orgs, nil
+}
+
+func (r *organizationRepository) FindMembership(ctx context.Context, orgID, userID uint) (*model.Membership, error) {
+	var m membershipModel
+	if err := reader(ctx, r.db).Where("org_id = ? AND user_id = ?", orgID, userID).First(&m).Error; err != nil {
+		return nil, fmt.Errorf("find membership: %w", notFound(err))
+	}
+	return toMembershipDomain(&m), nil
+}
+
+func (r *organizationRepository) ListMembers(ctx context.Context, orgID uint) ([]*model.Membership, error) {
+	var ms []membershipModel
+	if err := reader(ctx, r.db).Where("org_id = ?", orgID).Order("id").Find(&ms).Error; err != nil {
+		return nil, fmt.Errorf("list members: %w", err)
+	}
+	members := make([]*model.Membership, 0, len(ms))
+	for i := range ms {
+		members = append(members, toMembershipDomain(&ms[i]))
+	}
+	return members, nil
+}
+
+func (r *organizationRepository) UpdateMemberRole(ctx context.Context, orgID, userID uint, role string) (*model.Membership, error) {
+	var m membershipModel
+	err := inTx(ctx, r.db, func(ctx context.Context) error {
+		if err := conn(ctx, r.db).Where("org_id = ? AND user_id = ?", orgID, userID).First(&m).Error; err != nil {
+			return fmt.Errorf("not found: %w", notFound(err))
+		}
+		if m.Role == model.RoleOwner && role != model.RoleOwner {
+			if err := keepOwner(conn(ctx, r.db), orgID, userID); err != nil {
+				return err
+			}
+		}
+		m.Role = role
+		if err := conn(ctx, r.db).Save(&m).Error; err != nil {
+			return fmt.Errorf("save: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("update member role: %w", err)
+	}
+	return toMembershipDomain(&m), nil
+}
+
+func (r *organizationRepository) RemoveMember(ctx context.Context, orgID, userID uint) error {
+	err := inTx(ctx, r.db, func(ctx context.Context) error {
+		var m membershipModel
+		if err := conn(ctx, r.db).Where("org_id = ? AND user_id = ?", orgID, userID).First(&m).Error; err != nil {
+			return notFound(err)
+		}
+		if m.Role == model.RoleOwner {
+			if err := keepOwner(conn(ctx, r.db), orgID, userID); err != nil {
+				return err
+			}
+		}
+		return conn(ctx, r.db).Delete(&m).Error
+	})
+	if err != nil {
+		return fmt.Errorf("remove member: %w", err)
+	}
+	return nil
+}
+
+// keepOwner fails with model.ErrLastOwner if userID is the only owner of
+// orgID. It locks the owners' memberships, so concurrent demotions and
+// removals are checked one after another and cannot both pass.
+func keepOwner(tx *gorm.DB, orgID, userID uint) error {
+	var owners []membershipModel
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("org_id = ? AND role = ?", orgID, model.RoleOwner).Find(&owners).Error
+	if err != nil {
+		return err
+	}
+	for _, o := range owners {
+		if o.UserID != userID {
+			return nil
+		}
+	}
+	return model.ErrLastOwner
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go-gin-project/internal/pkg/model"
+
+	"gorm.io/gorm"
+)
+
+// recordEvent adds an event to the outbox through tx, so that it commits or
+// rolls back together with the change it describes.
+func recordEvent(tx *gorm.DB, aggregateType string, aggregateID uint, eventType string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("record %s event: %w", eventType, err)
+	}
+	m := &outboxEventModel{
+		AggregateType: aggregateType,
+		AggregateID:   strconv.FormatUint(uint64(aggregateID), 10),
+		EventType:     eventType,
+		Payload:       string(data),
+		NextAttemptAt: time.Now(),
+	}
+	if err := tx.Create(m).Error; err != nil {
+		return fmt.Errorf("record %s event: %w", eventType, err)
+	}
+	return nil
+}
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+// NewOutboxRepository creates a GORM-backed model.OutboxRepository.
+func NewOutboxRepository(db *gorm.DB) model.OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+// primary binds db to ctx on the primary: a replica may still show events
+// that were already settled.
+func (r *outboxRepository) primary(ctx context.Context) *gorm.DB {
+	return reader(model.ReadPrimary(ctx), r.db)
+}
+
+func (r *outboxRepository) Pending(ctx context.Context, limit int) ([]*model.Event, error) {
+	earlier := r.db.Table("outbox_events AS earlier").Select("1").
+		Where("earlier.aggregate_type = outbox_events.aggregate_type AND earlier.aggregate_id = outbox_events.aggregate_id").
+		Where("earlier.id < outbox_events.id AND earlier.dead_at IS NULL")
+	var ms []outboxEventModel
+	err := r.primary(ctx).
+		Where("dead_at IS NULL AND next_attempt_at <= ?", time.Now()).
+		Where("NOT EXISTS (?)", earlier).
+		Order("id").Limit(limit).Find(&ms).Error
+	if err != nil {
+		return nil, fmt.Errorf("list pending events: %w", err)
+	}
+	return toEventDomains(ms), nil
+}
+
+func (r *outboxRepository) MarkPublished(ctx context.Context, id uint) error {
+	if err := conn(ctx, r.db).Delete(&outboxEventModel{}, id).Error; err != nil {
+		return fmt.Errorf("mark event published: %w", err)
+	}
+	return nil
+}
+
+func (r *outboxRepository) MarkFailed(ctx context.Context, id uint, cause error, retryAt time.Time) error {
+	updates := map[string]interface{}{
+		"attempts":   gorm.Expr("attempts + 1"),
+		"last_error": cause.Error(),
+	}
+	if retryAt.IsZero() {
+		updates["dead_at"] = time.Now()
+	} else {
+		updates["next_attempt_at"] = retryAt
+	}
+	if err := conn(ctx, r.db).Model(&outboxEventModel{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		return fmt.Errorf("mark event failed: %w", err)
+	}
+	return nil
+}
+
+func (r *outboxRepository) DeadLetters(ctx context.Context, limit int) ([]*model.Event, error) {
+	var ms []outboxEventModel
+	if err := r.primary(ctx).Where("dead_at IS NOT NULL").Order("id DESC").Limit(limit).Find(&ms).Error; err != nil {
+		return nil, fmt.Errorf("list dead-lettered events: %w", err)
+	}
+	return toEventDomains(ms), nil
+}
+
+func (r *outboxRepository) Stats(ctx context.Context) (model.OutboxStats, error) {
+	var stats model.OutboxStats
+	if err := r.primary(ctx).Model(&outboxEventModel{}).Where("dead_at IS NULL").Count(&stats.Pending).Error; err != nil {
+		return stats, fmt.Errorf("outbox stats: %w", err)
+	}
+	if err := r.primary(ctx).Model(&outboxEventModel{}).Where("dead_at IS NOT NULL").Count(&stats.Dead).Error; err != nil {
+		return stats, fmt.Errorf("outbox stats: %w", err)
+	}
+	return stats, nil
+}
+
+func toEventDomains(ms []outboxEventModel) []*model.Event {
+	events := make([]*model.Event, 0, len(ms))
+	for i := range ms {
+		events = append(events, toEventDomain(&ms[i]))
+	}
+	return events
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"fmt"
 
 	"go-gin-project/internal/pkg/model"
@@ -9,7 +10,8 @@ import (
 )
 
 type paymentRepository struct {
-	db *gorm.DB
+	db    *gorm.DB
+	orgID uint
 }
 
 // NewPaymentRepository creates a GORM-backed model.PaymentRepository.
@@ -17,30 +19,110 @@ func NewPaymentRepository(db *gorm.DB) model.PaymentRepository {
 	return &paymentRepository{db: db}
 }
 
-func (r *paymentRepository) Create(payment *model.Payment) (*model.Payment, error) {
+func (r *paymentRepository) WithTenant(orgID uint) model.PaymentRepository {
+	return &paymentRepository{db: r.db, orgID: orgID}
+}
+
+// scoped restricts a payments query to the repository's tenant.
+func (r *paymentRepository) scoped(ctx context.Context) *gorm.DB {
+	db := reader(ctx, r.db)
+	if r.orgID == 0 {
+		return db
+	}
+	return db.Where("org_id = ?", r.orgID)
+}
+
+func (r *paymentRepository) Create(ctx context.Context, payment *model.Payment) (*model.Payment, error) {
 	m := toPaymentModel(payment)
-	if err := r.db.Create(m).Error; err != nil {
+	if r.orgID != 0 {
+		m.OrgID = r.orgID
+	}
+	if err := conn(ctx, r.db).Create(m).Error; err != nil {
 		return nil, fmt.Errorf("create payment: %w", err)
 	}
 	return toPaymentDomain(m), nil
 }
 
-func (r *paymentRepository) FindByStripeID(stripeID string) (*model.Payment, error) {
+func (r *paymentRepository) FindByStripeID(ctx context.Context, stripeID string) (*model.Payment, error) {
 	var m paymentModel
-	if err := r.db.Where("stripe_id = ?", stripeID).First(&m).Error; err != nil {
-		return nil, fmt.Errorf("find payment: %w", err)
+	if err := r.scoped(ctx).Where("stripe_id = ?", stripeID).First(&m).Error; err != nil {
+		return nil, fmt.Errorf("find payment: %w", notFound(err))
 	}
 	return toPaymentDomain(&m), nil
 }
 
-func (r *paymentRepository) UpdateStatus(payment *model.Payment) (*model.Payment, error) {
-	var m paymentModel
-	if err := r.db.Where("stripe_id = ?", payment.StripeID).First(&m).Error; err != nil {
-		return nil, fmt.Errorf("update payment status: not found: %w", err)
+func (r *paymentRepository) FindByUserID(ctx context.Context, userID uint) ([]*model.Payment, error) {
+	var ms []paymentModel
+	if err := r.scoped(ctx).Where("user_id = ?", userID).Order("id").Find(&ms).Error; err != nil {
+		return nil, fmt.Errorf("find payments by user: %w", err)
 	}
-	m.PaymentStatus = payment.PaymentStatus
-	if err := r.db.Save(&m).Error; err != nil {
-		return nil, fmt.Errorf("update payment status: save: %w", err)
+	payments := make([]*model.Payment, 0, len(ms))
+	for i := range ms {
+		payments = append(payments, toPaymentDomain(&ms[i]))
+	}
+	return payments, nil
+}
+
+func (r *paymentRepository) UpdateStatus(ctx context.Context, payment *model.Payment) (*model.Payment, error) {
+	var m paymentModel
+	// Inside the transaction reads use the primary, so the fencing checks
+	// compare against the latest committed token.
+	err := inTx(ctx, r.db, func(ctx context.Context) error {
+		if err := r.scoped(ctx).Where("stripe_id = ?", payment.StripeID).First(&m).Error; err != nil {
+			return fmt.Errorf("not found: %w", err)
+		}
+		previous := m.PaymentStatus
+		if payment.FenceToken == 0 {
+			if err := conn(ctx, r.db).Model(&m).Update("payment_status", payment.PaymentStatus).Error; err != nil {
+				return fmt.Errorf("save: %w", err)
+			}
+			return r.recordStatusChange(ctx, &m, previous)
+		}
+
+		// The token check is repeated in the UPDATE so a newer holder that wrote
+		// between the read and the write still wins.
+		if payment.FenceToken < m.FenceToken {
+			return model.ErrStaleToken
+		}
+		res := conn(ctx, r.db).Model(&m).Where("fence_token <= ?", payment.FenceToken).Updates(map[string]interface{}{
+			"payment_status": payment.PaymentStatus,
+			"fence_token":    payment.FenceToken,
+		})
+		if res.Error != nil {
+			return fmt.Errorf("save: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			// MySQL counts changed rows only, so zero may also mean "no change".
+			var current int64
+			if err := reader(ctx, r.db).Model(&paymentModel{}).Where("id = ?", m.ID).Pluck("fence_token", &current).Error; err != nil {
+				return err
+			}
+			if current > payment.FenceToken {
+				return model.ErrStaleToken
+			}
+		}
+		m.PaymentStatus = payment.PaymentStatus
+		m.FenceToken = payment.FenceToken
+		return r.recordStatusChange(ctx, &m, previous)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("update payment status: %w", err)
 	}
 	return toPaymentDomain(&m), nil
 }
+
+// recordStatusChange records EventPaymentStatusChanged if m's status is no
+// longer previous.
+func (r *paymentRepository) recordStatusChange(ctx context.Context, m *paymentModel, previous string) error {
+	if m.PaymentStatus == previous {
+		return nil
+	}
+	return recordEvent(conn(ctx, r.db), model.AggregatePayment, m.ID, model.EventPaymentStatusChanged, model.PaymentStatusChanged{
+		PaymentID: m.ID,
+		StripeID:  m.StripeID,
+		UserID:    m.UserID,
+		OrgID:     m.OrgID,
+		From:      previous,
+		To:        m.PaymentStatus,
+	})
+}
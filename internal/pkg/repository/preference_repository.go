@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"go-gin-project/internal/pkg/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type preferenceRepository struct {
+	db *gorm.DB
+}
+
+// NewPreferenceRepository creates a GORM-backed model.PreferenceRepository.
+func NewPreferenceRepository(db *gorm.DB) model.PreferenceRepository {
+	return &preferenceRepository{db: db}
+}
+
+func (r *preferenceRepository) List(ctx context.Context, userID uint) (map[string]string, error) {
+	var ms []preferenceModel
+	if err := reader(ctx, r.db).Where("user_id = ?", userID).Find(&ms).Error; err != nil {
+		return nil, fmt.Errorf("list preferences: %w", err)
+	}
+	prefs := make(map[string]string, len(ms))
+	for _, m := range ms {
+		prefs[m.Key] = m.Value
+	}
+	return prefs, nil
+}
+
+func (r *preferenceRepository) Set(ctx context.Context, userID uint, prefs map[string]string) error {
+	if len(prefs) == 0 {
+		return nil
+	}
+	ms := make([]preferenceModel, 0, len(prefs))
+	for key, value := range prefs {
+		ms = append(ms, preferenceModel{UserID: userID, Key: key, Value: value})
+	}
+	err := conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "updated_at"}),
+	}).Create(&ms).Error
+	if err != nil {
+		return fmt.Errorf("set preferences: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"sort"
+	"time"
+
+	"go-gin-project/internal/pkg/model"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type privacyRepository struct {
+	db *gorm.DB
+}
+
+// NewPrivacyRepository creates a GORM-backed model.PrivacyRepository.
+func NewPrivacyRepository(db *gorm.DB) model.PrivacyRepository {
+	return &privacyRepository{db: db}
+}
+
+func (r *privacyRepository) Memberships(ctx context.Context, userID uint) ([]*model.Membership, error) {
+	var ms []membershipModel
+	if err := reader(ctx, r.db).Where("user_id = ?", userID).Order("org_id").Find(&ms).Error; err != nil {
+		return nil, fmt.Errorf("list memberships: %w", err)
+	}
+	memberships := make([]*model.Membership, 0, len(ms))
+	for i := range ms {
+		memberships = append(memberships, toMembershipDomain(&ms[i]))
+	}
+	return memberships, nil
+}
+
+// AuditLog rebuilds the account history from the tables that record it.
+// Invitations the user sent name only the organization, not the invitee.
+func (r *privacyRepository) AuditLog(ctx context.Context, userID uint) ([]*model.AuditEntry, error) {
+	var user userModel
+	if err := reader(ctx, r.db).First(&user, userID).Error; err != nil {
+		return nil, fmt.Errorf("audit log: %w", notFound(err))
+	}
+	var (
+		memberships []membershipModel
+		received    []invitationModel
+		sent        []invitationModel
+		changes     []emailChangeModel
+		erasures    []erasureReceiptModel
+	)
+	queries := []struct {
+		dest  interface{}
+		query string
+		arg   interface{}
+	}{
+		{&memberships, "user_id = ?", userID},
+		{&received, "email = ?", user.Email},
+		{&sent, "invited_by = ?", userID},
+		{&changes, "user_id = ?", userID},
+		{&erasures, "requested_by = ?", userID},
+	}
+	for _, q := range queries {
+		if err := reader(ctx, r.db).Where(q.query, q.arg).Find(q.dest).Error; err != nil {
+			return nil, fmt.Errorf("audit log: %w", err)
+		}
+	}
+
+	var entries []*model.AuditEntry
+	add := func(at *time.Time, action, detail string, args ...interface{}) {
+		if at != nil {
+			entries = append(entries, &model.AuditEntry{At: *at, Action: action, Detail: fmt.Sprintf(detail, args...)})
+		}
+	}
+	for _, m := range memberships {
+		add(&m.CreatedAt, "membership.created", "organization %d as %s", m.OrgID, m.Role)
+	}
+	for _, inv := range received {
+		add(&inv.CreatedAt, "invitation.received", "organization %d as %s", inv.OrgID, inv.Role)
+		add(inv.AcceptedAt, "invitation.accepted", "organization %d", inv.OrgID)
+	}
+	for _, inv := range sent {
+		add(&inv.CreatedAt, "invitation.sent", "organization %d as %s", inv.OrgID, inv.Role)
+	}
+	for _, c := range changes {
+		add(&c.CreatedAt, "email_change.requested", "to %s", c.NewEmail)
+		add(c.ConfirmedAt, "email_change.confirmed", "to %s", c.NewEmail)
+	}
+	for _, e := range erasures {
+		add(&e.ErasedAt, "erasure.requested", "user %d", e.UserID)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].At.Before(entries[j].At) })
+	return entries, nil
+}
+
+// Erase overwrites the user's name, email and password with non-identifying
+// values, soft-deletes the row, removes the other rows that hold their
+// address or settings and records a receipt. Payment rows are left untouched
+// so they remain available for accounting.
+func (r *privacyRepository) Erase(ctx context.Context, userID string, requestedBy uint) (*model.ErasureReceipt, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("erase user: random password: %w", err)
+	}
+	hashed, err := bcrypt.GenerateFromPassword(secret, bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("erase user: hash password: %w", err)
+	}
+
+	var receipt *erasureReceiptModel
+	err = conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var m userModel
+		if err := tx.First(&m, userID).Error; err != nil {
+			return fmt.Errorf("not found: %w", notFound(err))
+		}
+
+		if err := tx.Where("user_id = ?", m.ID).Delete(&preferenceModel{}).Error; err != nil {
+			return fmt.Errorf("delete preferences: %w", err)
+		}
+		if err := tx.Where("user_id = ?", m.ID).Delete(&emailChangeModel{}).Error; err != nil {
+			return fmt.Errorf("delete email changes: %w", err)
+		}
+		if err := tx.Where("email = ?", m.Email).Delete(&invitationModel{}).Error; err != nil {
+			return fmt.Errorf("delete invitations: %w", err)
+		}
+
+		m.Name = "Erased User"
+		m.Email = fmt.Sprintf("erased-%d@erased.invalid", m.ID)
+		m.Password = string(hashed)
+		m.DisplayName = ""
+		m.Phone = ""
+		m.AvatarKey = ""
+		if err := tx.Save(&m).Error; err != nil {
+			return fmt.Errorf("anonymize: %w", err)
+		}
+		if err := tx.Delete(&m).Error; err != nil {
+			return fmt.Errorf("delete: %w", err)
+		}
+
+		receipt = &erasureReceiptModel{
+			UserID:      m.ID,
+			RequestedBy: requestedBy,
+			ErasedAt:    time.Now(),
+		}
+		if err := tx.Create(receipt).Error; err != nil {
+			return fmt.Errorf("receipt: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erase user: %w", err)
+	}
+	return toErasureReceiptDomain(receipt), nil
+}
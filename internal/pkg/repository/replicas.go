@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"go-gin-project/internal/pkg/model"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// reader binds db to ctx for a query, like conn. When read replicas are
+// configured, plain queries go to a replica; writes and transactions always
+// use the primary, and so do reads in a context marked with model.ReadPrimary.
+func reader(ctx context.Context, db *gorm.DB) *gorm.DB {
+	db = conn(ctx, db)
+	if model.ReadsPrimary(ctx) {
+		return db.Clauses(dbresolver.Write)
+	}
+	return db
+}
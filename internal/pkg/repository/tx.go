@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+
+	"go-gin-project/internal/pkg/model"
+
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+type txManager struct {
+	db *gorm.DB
+}
+
+var _ model.TxManager = (*txManager)(nil)
+
+// NewTxManager creates a model.TxManager for the repositories built on db.
+func NewTxManager(db *gorm.DB) model.TxManager {
+	return &txManager{db: db}
+}
+
+func (m *txManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return inTx(ctx, m.db, fn)
+}
+
+// inTx runs fn in a transaction on db, or under a savepoint if ctx already
+// carries one, passing fn a ctx that conn and reader resolve to it.
+func inTx(ctx context.Context, db *gorm.DB, fn func(ctx context.Context) error) error {
+	// GORM runs Transaction on a transaction as a savepoint.
+	return conn(ctx, db).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// conn binds db to ctx for a statement, using the transaction started by
+// TxManager.WithinTx if ctx carries one. Repositories that take a context
+// must go through conn (or reader) so their writes join that transaction.
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
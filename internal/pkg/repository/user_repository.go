@@ -1,16 +1,20 @@
 package repository
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
 	"go-gin-project/internal/pkg/model"
 
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type userRepository struct {
-	db *gorm.DB
+	db    *gorm.DB
+	orgID uint
 }
 
 // NewUserRepository creates a GORM-backed model.UserRepository.
@@ -18,41 +22,69 @@ func NewUserRepository(db *gorm.DB) model.UserRepository {
 	return &userRepository{db: db}
 }
 
-func (r *userRepository) Create(user *model.User) (*model.User, error) {
-	tx := r.db.Begin()
-	if tx.Error != nil {
-		return nil, fmt.Errorf("create user: begin transaction: %w", tx.Error)
+func (r *userRepository) WithTenant(orgID uint) model.UserRepository {
+	return &userRepository{db: r.db, orgID: orgID}
+}
+
+// scoped restricts a users query to members of the repository's tenant.
+func (r *userRepository) scoped(db *gorm.DB) *gorm.DB {
+	if r.orgID == 0 {
+		return db
+	}
+	members := r.db.Model(&membershipModel{}).Select("user_id").Where("org_id = ?", r.orgID)
+	return db.Where("users.id IN (?)", members)
+}
+
+// addMemberships makes the given users members of the repository's tenant.
+func (r *userRepository) addMemberships(tx *gorm.DB, ms ...*userModel) error {
+	if r.orgID == 0 {
+		return nil
+	}
+	memberships := make([]*membershipModel, 0, len(ms))
+	for _, m := range ms {
+		memberships = append(memberships, &membershipModel{OrgID: r.orgID, UserID: m.ID, Role: model.RoleMember})
 	}
-	defer func() {
-		if rec := recover(); rec != nil {
-			tx.Rollback()
+	return tx.Create(&memberships).Error
+}
+
+// recordCreated records EventUserCreated for each of the given users.
+func (r *userRepository) recordCreated(tx *gorm.DB, ms ...*userModel) error {
+	for _, m := range ms {
+		if err := recordEvent(tx, model.AggregateUser, m.ID, model.EventUserCreated, model.UserCreated{UserID: m.ID, OrgID: r.orgID}); err != nil {
+			return err
 		}
-	}()
-
-	var existing userModel
-	if err := tx.Where("email = ?", user.Email).First(&existing).Error; err == nil {
-		tx.Rollback()
-		return nil, fmt.Errorf("create user: user already exists")
-	} else if err != gorm.ErrRecordNotFound {
-		tx.Rollback()
-		return nil, fmt.Errorf("create user: %w", err)
 	}
+	return nil
+}
 
+func (r *userRepository) Create(ctx context.Context, user *model.User) (*model.User, error) {
 	hashed, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
 	if err != nil {
-		tx.Rollback()
 		return nil, fmt.Errorf("create user: hash password: %w", err)
 	}
-
 	m := toUserModel(user)
 	m.Password = string(hashed)
 
-	if err := tx.Create(m).Error; err != nil {
-		tx.Rollback()
-		return nil, fmt.Errorf("create user: insert: %w", err)
-	}
-	if err := tx.Commit().Error; err != nil {
-		return nil, fmt.Errorf("create user: commit: %w", err)
+	err = conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var existing userModel
+		if err := tx.Where("email = ?", user.Email).First(&existing).Error; err == nil {
+			return errors.New("user already exists")
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		// The email check above misses soft-deleted users and concurrent
+		// inserts; the unique index catches both.
+		if err := tx.Create(m).Error; err != nil {
+			return fmt.Errorf("insert: %w", duplicate(tx, err))
+		}
+		if err := r.addMemberships(tx, m); err != nil {
+			return fmt.Errorf("membership: %w", err)
+		}
+		return r.recordCreated(tx, m)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create user: %w", err)
 	}
 
 	result := toUserDomain(m)
@@ -60,57 +92,100 @@ func (r *userRepository) Create(user *model.User) (*model.User, error) {
 	return result, nil
 }
 
-func (r *userRepository) FindByID(id string) (*model.User, error) {
+func (r *userRepository) CreateBatch(ctx context.Context, users []*model.User) ([]*model.User, error) {
+	ms := make([]*userModel, 0, len(users))
+	for _, u := range users {
+		hashed, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, fmt.Errorf("create users: hash password for %s: %w", u.Email, err)
+		}
+		m := toUserModel(u)
+		m.Password = string(hashed)
+		ms = append(ms, m)
+	}
+
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&ms).Error; err != nil {
+			return err
+		}
+		if err := r.addMemberships(tx, ms...); err != nil {
+			return err
+		}
+		return r.recordCreated(tx, ms...)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create users: insert: %w", duplicate(r.db, err))
+	}
+
+	result := make([]*model.User, 0, len(ms))
+	for _, m := range ms {
+		u := toUserDomain(m)
+		u.Password = ""
+		result = append(result, u)
+	}
+	return result, nil
+}
+
+func (r *userRepository) FindByID(ctx context.Context, id string) (*model.User, error) {
 	var m userModel
-	if err := r.db.First(&m, id).Error; err != nil {
-		return nil, fmt.Errorf("find user: %w", err)
+	if err := r.scoped(reader(ctx, r.db)).First(&m, id).Error; err != nil {
+		return nil, fmt.Errorf("find user: %w", notFound(err))
 	}
 	return toUserDomain(&m), nil
 }
 
-func (r *userRepository) FindByEmail(email string) (*model.User, error) {
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (*model.User, error) {
 	var m userModel
-	if err := r.db.Where("email = ?", email).First(&m).Error; err != nil {
-		return nil, fmt.Errorf("find user by email: %w", err)
+	if err := reader(ctx, r.db).Where("email = ?", email).First(&m).Error; err != nil {
+		return nil, fmt.Errorf("find user by email: %w", notFound(err))
 	}
 	return toUserDomain(&m), nil
 }
 
-func (r *userRepository) Update(id string, data *model.User) (*model.User, error) {
-	tx := r.db.Begin()
-	if tx.Error != nil {
-		return nil, fmt.Errorf("update user: begin transaction: %w", tx.Error)
+func (r *userRepository) ExistingEmails(ctx context.Context, emails []string) ([]string, error) {
+	var existing []string
+	if len(emails) == 0 {
+		return existing, nil
 	}
-	defer func() {
-		if rec := recover(); rec != nil {
-			tx.Rollback()
-		}
-	}()
+	if err := reader(ctx, r.db).Model(&userModel{}).Where("email IN ?", emails).Pluck("email", &existing).Error; err != nil {
+		return nil, fmt.Errorf("find existing emails: %w", err)
+	}
+	return existing, nil
+}
 
+func (r *userRepository) LockByID(ctx context.Context, id string) (*model.User, error) {
 	var m userModel
-	if err := tx.First(&m, id).Error; err != nil {
-		tx.Rollback()
-		return nil, fmt.Errorf("update user: not found: %w", err)
+	// SQLite has no row locks; its transactions already exclude other writers.
+	if err := r.scoped(conn(ctx, r.db)).Clauses(clause.Locking{Strength: "SHARE"}).First(&m, id).Error; err != nil {
+		return nil, fmt.Errorf("lock user: %w", notFound(err))
 	}
+	return toUserDomain(&m), nil
+}
 
-	m.Name = data.Name
-	m.Email = data.Email
-
-	if data.Password != "" {
-		hashed, err := bcrypt.GenerateFromPassword([]byte(data.Password), bcrypt.DefaultCost)
-		if err != nil {
-			tx.Rollback()
-			return nil, fmt.Errorf("update user: hash password: %w", err)
+func (r *userRepository) Update(ctx context.Context, id string, data *model.User) (*model.User, error) {
+	var m userModel
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := r.scoped(tx).First(&m, id).Error; err != nil {
+			return fmt.Errorf("not found: %w", err)
 		}
-		m.Password = string(hashed)
-	}
 
-	if err := tx.Save(&m).Error; err != nil {
-		tx.Rollback()
-		return nil, fmt.Errorf("update user: save: %w", err)
-	}
-	if err := tx.Commit().Error; err != nil {
-		return nil, fmt.Errorf("update user: commit: %w", err)
+		// Email is the login identity; it only changes through a confirmed EmailChange.
+		if data.Email != "" && data.Email != m.Email {
+			return errors.New("email changes must be confirmed via the new address")
+		}
+		m.Name = data.Name
+		m.DisplayName = data.DisplayName
+		m.Locale = data.Locale
+		m.Timezone = data.Timezone
+		m.Phone = data.Phone
+
+		if err := tx.Save(&m).Error; err != nil {
+			return fmt.Errorf("save: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("update user: %w", err)
 	}
 
 	result := toUserDomain(&m)
@@ -118,28 +193,58 @@ func (r *userRepository) Update(id string, data *model.User) (*model.User, error
 	return result, nil
 }
 
-func (r *userRepository) Delete(id string) error {
-	tx := r.db.Begin()
-	if tx.Error != nil {
-		return fmt.Errorf("delete user: begin transaction: %w", tx.Error)
+func (r *userRepository) UpdatePassword(ctx context.Context, id string, password string) error {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("update password: hash: %w", err)
 	}
-	defer func() {
-		if rec := recover(); rec != nil {
-			tx.Rollback()
-		}
-	}()
+	result := r.scoped(conn(ctx, r.db).Model(&userModel{})).Where("id = ?", id).Updates(map[string]interface{}{
+		"password":      string(hashed),
+		"token_version": gorm.Expr("token_version + 1"),
+	})
+	if result.Error != nil {
+		return fmt.Errorf("update password: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("update password: %w", notFound(gorm.ErrRecordNotFound))
+	}
+	return nil
+}
 
-	var m userModel
-	if err := tx.First(&m, id).Error; err != nil {
-		tx.Rollback()
-		return fmt.Errorf("delete user: not found: %w", err)
+func (r *userRepository) UpdateAvatar(ctx context.Context, id string, avatarKey string) (string, error) {
+	var previous string
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var m userModel
+		if err := r.scoped(tx).First(&m, id).Error; err != nil {
+			return err
+		}
+		previous = m.AvatarKey
+		return tx.Model(&m).Update("avatar_key", avatarKey).Error
+	})
+	if err != nil {
+		return "", fmt.Errorf("update avatar: %w", err)
 	}
-	if err := tx.Delete(&m).Error; err != nil {
-		tx.Rollback()
+	return previous, nil
+}
+
+func (r *userRepository) Delete(ctx context.Context, id string) error {
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var m userModel
+		if err := r.scoped(tx).First(&m, id).Error; err != nil {
+			return fmt.Errorf("not found: %w", err)
+		}
+		return tx.Delete(&m).Error
+	})
+	if err != nil {
 		return fmt.Errorf("delete user: %w", err)
 	}
-	if err := tx.Commit().Error; err != nil {
-		return fmt.Errorf("delete user: commit: %w", err)
-	}
 	return nil
 }
+
+func (r *userRepository) OrgIDs(ctx context.Context, id string) ([]uint, error) {
+	var orgIDs []uint
+	if err := reader(ctx, r.db).Model(&membershipModel{}).Where("user_id = ?", id).Order("org_id").Pluck("org_id", &orgIDs).Error; err != nil {
+		return nil, fmt.Errorf("list user organizations: %w", err)
+	}
+	return orgIDs, nil
+}
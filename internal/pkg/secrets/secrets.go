@@ -0,0 +1,162 @@
+// Package secrets resolves secret references from configuration and keeps
+// them current while the process runs.
+//
+// A configured secret is either a literal value or a reference:
+//
+//	file:/run/secrets/jwt       contents of a file, trailing newline removed
+//	vault:app/auth#jwt_secret   key jwt_secret of the secret at app/auth
+//
+// Any scheme other than file names a model.SecretProvider passed to NewManager.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go-gin-project/internal/pkg/model"
+)
+
+// Value is a secret that may be rotated while the process runs. Consumers
+// that can pick up a new value, such as connection dialers, call Get each
+// time they need it instead of keeping a copy.
+type Value struct {
+	v atomic.Value // string
+}
+
+// Static returns a Value that never changes.
+func Static(s string) *Value {
+	v := &Value{}
+	v.v.Store(s)
+	return v
+}
+
+// Get returns the current value.
+func (v *Value) Get() string {
+	s, _ := v.v.Load().(string)
+	return s
+}
+
+// ref is a parsed secret reference.
+type ref struct {
+	scheme string // "file" or a provider name
+	path   string
+	key    string // provider secrets only
+}
+
+// parseRef reports whether raw is a reference to a known scheme; anything
+// else is a literal, so passwords that merely contain a colon still work.
+func (m *Manager) parseRef(raw string) (ref, bool, error) {
+	scheme, rest, ok := strings.Cut(raw, ":")
+	if !ok {
+		return ref{}, false, nil
+	}
+	if scheme == "file" {
+		return ref{scheme: scheme, path: rest}, true, nil
+	}
+	if _, known := m.providers[scheme]; !known {
+		return ref{}, false, nil
+	}
+	path, key, ok := strings.Cut(rest, "#")
+	if !ok || path == "" || key == "" {
+		return ref{}, false, fmt.Errorf("want %s:<path>#<key>, got %q", scheme, raw)
+	}
+	return ref{scheme: scheme, path: path, key: key}, true, nil
+}
+
+type entry struct {
+	name  string
+	ref   ref
+	value *Value
+}
+
+// Manager resolves secret references and refreshes them periodically.
+type Manager struct {
+	providers map[string]model.SecretProvider
+
+	mu      sync.Mutex
+	entries []entry
+}
+
+// NewManager creates a Manager resolving "<scheme>:" references with the
+// provider registered under scheme, e.g. {"vault": vaultProvider}.
+func NewManager(providers map[string]model.SecretProvider) *Manager {
+	return &Manager{providers: providers}
+}
+
+// Resolve returns the Value for raw, reading it now. References are kept and
+// refreshed by Refresh; name identifies the secret in errors and logs and
+// never the value itself.
+func (m *Manager) Resolve(ctx context.Context, name, raw string) (*Value, error) {
+	r, ok, err := m.parseRef(raw)
+	if err != nil {
+		return nil, fmt.Errorf("secret %s: %w", name, err)
+	}
+	if !ok {
+		return Static(raw), nil
+	}
+	s, err := m.read(ctx, r)
+	if err != nil {
+		return nil, fmt.Errorf("secret %s: %w", name, err)
+	}
+	v := Static(s)
+	m.mu.Lock()
+	m.entries = append(m.entries, entry{name: name, ref: r, value: v})
+	m.mu.Unlock()
+	return v, nil
+}
+
+func (m *Manager) read(ctx context.Context, r ref) (string, error) {
+	if r.scheme == "file" {
+		data, err := os.ReadFile(r.path)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+	return m.providers[r.scheme].Secret(ctx, r.path, r.key)
+}
+
+// Refresh re-reads every resolved reference. A secret that cannot be read
+// keeps its previous value; all failures are returned together.
+func (m *Manager) Refresh(ctx context.Context) error {
+	m.mu.Lock()
+	entries := append([]entry(nil), m.entries...)
+	m.mu.Unlock()
+
+	var errs []error
+	for _, e := range entries {
+		s, err := m.read(ctx, e.ref)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("secret %s: %w", e.name, err))
+			continue
+		}
+		if s != e.value.Get() {
+			e.value.v.Store(s)
+			slog.Info("secret rotated", "name", e.name)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Run calls Refresh every interval until ctx ends.
+func (m *Manager) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.Refresh(ctx); err != nil {
+				slog.Warn("secret refresh failed, keeping previous values", "err", err)
+			}
+		}
+	}
+}
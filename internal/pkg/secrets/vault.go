@@ -0,0 +1,114 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go-gin-project/internal/pkg/model"
+)
+
+// VaultOptions configures a HashiCorp Vault KV secrets engine.
+type VaultOptions struct {
+	Addr      string // e.g. "https://vault.internal:8200"
+	Token     string
+	Namespace string       // Vault Enterprise namespace, optional
+	Mount     string       // KV mount path, defaults to "secret"
+	KVVersion int          // 1 or 2 (default)
+	Client    *http.Client // defaults to a client with a 10s timeout
+}
+
+type vault struct {
+	addr   *url.URL
+	opts   VaultOptions
+	client *http.Client
+}
+
+// NewVault creates a model.SecretProvider reading a Vault KV mount. Paths are
+// relative to the mount, so "app/stripe" in KV v2 reads
+// <mount>/data/app/stripe.
+func NewVault(opts VaultOptions) (model.SecretProvider, error) {
+	if opts.Addr == "" || opts.Token == "" {
+		return nil, errors.New("vault: address and token are required")
+	}
+	addr, err := url.Parse(strings.TrimSuffix(opts.Addr, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("vault: address: %w", err)
+	}
+	if opts.Mount == "" {
+		opts.Mount = "secret"
+	}
+	switch opts.KVVersion {
+	case 0:
+		opts.KVVersion = 2
+	case 1, 2:
+	default:
+		return nil, fmt.Errorf("vault: unsupported KV version %d", opts.KVVersion)
+	}
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &vault{addr: addr, opts: opts, client: client}, nil
+}
+
+func (v *vault) Secret(ctx context.Context, path, key string) (string, error) {
+	mount := strings.Trim(v.opts.Mount, "/")
+	path = strings.Trim(path, "/")
+	endpoint := v.addr.String() + "/v1/" + mount + "/" + path
+	if v.opts.KVVersion == 2 {
+		endpoint = v.addr.String() + "/v1/" + mount + "/data/" + path
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", fmt.Errorf("vault read %s: %w", path, err)
+	}
+	req.Header.Set("X-Vault-Token", v.opts.Token)
+	if v.opts.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.opts.Namespace)
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("vault read %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault read %s: status %d", path, resp.StatusCode)
+	}
+
+	// KV v1 returns the secret in "data"; v2 nests it in "data.data" next to
+	// version metadata.
+	var body struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("vault read %s: %w", path, err)
+	}
+	data := body.Data
+	if v.opts.KVVersion == 2 {
+		var versioned struct {
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(data, &versioned); err != nil {
+			return "", fmt.Errorf("vault read %s: %w", path, err)
+		}
+		data = versioned.Data
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return "", fmt.Errorf("vault read %s: %w", path, err)
+	}
+	value, ok := values[key].(string)
+	if !ok {
+		return "", fmt.Errorf("vault read %s: no string key %q", path, key)
+	}
+	return value, nil
+}
+
+var _ model.SecretProvider = (*vault)(nil) // compile-time interface check
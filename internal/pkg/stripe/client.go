@@ -2,7 +2,6 @@ package stripe
 
 import (
 	"fmt"
-	"os"
 
 	"go-gin-project/internal/pkg/model"
 
@@ -10,24 +9,29 @@ import (
 	"github.com/stripe/stripe-go/v72/paymentintent"
 )
 
-type client struct{}
+type client struct {
+	key func() string
+}
 
-// New creates a Stripe client and initializes the Stripe SDK key from STRIPE_SECRET_KEY env var.
-func New() (model.StripeService, error) {
-	key := os.Getenv("STRIPE_SECRET_KEY")
-	if key == "" {
-		return nil, fmt.Errorf("STRIPE_SECRET_KEY is not set")
+// New creates a Stripe client. key is called for every request, so a rotated
+// secret key takes effect without a restart.
+func New(key func() string) (model.StripeService, error) {
+	if key() == "" {
+		return nil, fmt.Errorf("stripe secret key is not set")
 	}
-	stripelib.Key = key
-	return &client{}, nil
+	return &client{key: key}, nil
+}
+
+func (c *client) intents() paymentintent.Client {
+	return paymentintent.Client{B: stripelib.GetBackend(stripelib.APIBackend), Key: c.key()}
 }
 
 func (c *client) New(params *stripelib.PaymentIntentParams) (*stripelib.PaymentIntent, error) {
-	return paymentintent.New(params)
+	return c.intents().New(params)
 }
 
 func (c *client) Get(id string, params *stripelib.PaymentIntentParams) (*stripelib.PaymentIntent, error) {
-	return paymentintent.Get(id, params)
+	return c.intents().Get(id, params)
 }
 
 var _ model.StripeService = (*client)(nil) // compile-time interface check
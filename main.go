@@ -2,7 +2,10 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -12,8 +15,13 @@ import (
 	"go-gin-project/config"
 	apppkg "go-gin-project/internal/app"
 	"go-gin-project/internal/app/handler"
+	"go-gin-project/internal/app/middleware"
 	"go-gin-project/internal/app/service"
+	"go-gin-project/internal/pkg/blobstore"
+	"go-gin-project/internal/pkg/broker"
 	"go-gin-project/internal/pkg/cache"
+	"go-gin-project/internal/pkg/mailer"
+	"go-gin-project/internal/pkg/model"
 	"go-gin-project/internal/pkg/repository"
 	stripepkg "go-gin-project/internal/pkg/stripe"
 	grpcserver "go-gin-project/grpc/server"
@@ -21,50 +29,193 @@ import (
 	_ "go-gin-project/docs"
 
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
 func main() {
-	if err := godotenv.Load(); err != nil {
-		log.Printf("Warning: .env file not found: %v", err)
+	cfg, err := config.Load(flag.CommandLine, os.Args[1:], "db", "auth")
+	if err != nil {
+		log.Fatal(err)
+	}
+	secretManager, err := cfg.ResolveSecrets(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to read secrets: %v", err)
 	}
+	if cfg.Secrets.RefreshInterval > 0 {
+		go secretManager.Run(context.Background(), cfg.Secrets.RefreshInterval)
+	}
+	// Log level, rate limits, cache TTLs and CORS origins reload on SIGHUP
+	// or when the config file changes.
+	live := config.NewLive(cfg)
+	go live.Watch(context.Background())
 
-	if err := config.InitDB(); err != nil {
+	db, err := cfg.OpenDB()
+	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
-	defer config.CloseDB() //nolint:errcheck
+	defer config.CloseDB(db) //nolint:errcheck
 
 	// Infrastructure layer
-	cacheService, err := cache.New()
+	var baseCache model.CacheService
+	var locker model.Locker
+	var limiter model.RateLimiter
+	redisOpts := cfg.RedisOptions()
+	switch cfg.Cache.Driver {
+	case "none":
+		baseCache = cache.NewNoop()
+		locker = cache.NewMemoryLocker()
+		limiter = cache.NewMemoryRateLimiter()
+	case "memory":
+		baseCache = cache.NewMemory(10000, 64<<20)
+		locker = cache.NewMemoryLocker()
+		limiter = cache.NewMemoryRateLimiter()
+	default:
+		// A local LRU in front of Redis, kept coherent across instances via
+		// pub/sub, degrading to an in-process LRU while Redis is unreachable.
+		tiered := func() (model.CacheService, error) { return cache.NewTiered(redisOpts, 1000, 30*time.Second) }
+		baseCache = cache.NewFallback(tiered, cache.NewMemory(10000, 64<<20), 10*time.Second)
+		if redisLocker, err := cache.NewLocker(redisOpts); err != nil {
+			slog.Warn("Redis locks unavailable, locking within this instance only", "err", err)
+			locker = cache.NewMemoryLocker()
+		} else {
+			locker = cache.NewFallbackLocker(redisLocker, cache.NewMemoryLocker(), 10*time.Second)
+		}
+		if redisLimiter, err := cache.NewRateLimiter(redisOpts); err != nil {
+			slog.Warn("Redis rate limiter unavailable, limiting per instance", "err", err)
+			limiter = cache.NewMemoryRateLimiter()
+		} else {
+			limiter = cache.NewFallbackRateLimiter(redisLimiter, cache.NewMemoryRateLimiter(), 10*time.Second)
+		}
+	}
+	cacheService := cache.NewLoading(baseCache, cache.LoadingOptions{Codec: redisOpts.Codec})
+
+	mailService := mailer.NewLog()
+
+	blobStore, err := blobstore.New(cfg.BlobOptions())
 	if err != nil {
-		log.Printf("Warning: Redis unavailable, continuing without cache: %v", err)
+		log.Fatalf("Failed to initialize blob store: %v", err)
 	}
 
-	stripeClient, err := stripepkg.New()
+	stripeClient, err := stripepkg.New(cfg.Secret("stripe.secret_key").Get)
 	if err != nil {
-		log.Printf("Warning: Stripe unavailable: %v", err)
+		slog.Warn("Stripe unavailable", "err", err)
 	}
 
-	userRepo := repository.NewUserRepository(config.DB)
-	paymentRepo := repository.NewPaymentRepository(config.DB)
+	userRepo := repository.NewUserRepository(db)
+	paymentRepo := repository.NewPaymentRepository(db)
+	privacyRepo := repository.NewPrivacyRepository(db)
+	orgRepo := repository.NewOrganizationRepository(db)
+	invitationRepo := repository.NewInvitationRepository(db)
+	emailChangeRepo := repository.NewEmailChangeRepository(db)
+	prefRepo := repository.NewPreferenceRepository(db)
+	outboxRepo := repository.NewOutboxRepository(db)
+	txManager := repository.NewTxManager(db)
 
 	// Application layer
-	userService := service.NewUserService(userRepo, cacheService)
-	authService := service.NewAuthService(userRepo)
-	paymentService := service.NewPaymentService(paymentRepo, userRepo, cacheService, stripeClient)
+	userTTL := func() time.Duration { return live.Config().Cache.UserTTL }
+	paymentTTL := func() time.Duration { return live.Config().Cache.PaymentTTL }
+	userService := service.NewUserService(userRepo, cacheService, userTTL)
+	authService := service.NewAuthService(userRepo, orgRepo, cfg.Secret("auth.jwt_secret").Get)
+	paymentService := service.NewPaymentService(paymentRepo, userRepo, txManager, cacheService, paymentTTL, stripeClient, locker)
+	privacyService := service.NewPrivacyService(userRepo, paymentRepo, privacyRepo, prefRepo, blobStore, cacheService)
+	importService := service.NewImportService(userRepo)
+	orgService := service.NewOrganizationService(orgRepo, cacheService)
+	invitationService := service.NewInvitationService(invitationRepo, orgRepo, userRepo, mailService)
+	accountService := service.NewAccountService(userRepo, emailChangeRepo, cacheService, mailService)
+	preferenceService := service.NewPreferenceService(prefRepo)
+	avatarService := service.NewAvatarService(userRepo, blobStore, cacheService)
+
+	// Publish the events repositories record in the outbox. Instances take
+	// turns through the locker, which is only shared with CACHE_DRIVER=redis.
+	if cfg.Events.Broker == "redis" {
+		eventBroker, err := broker.NewRedisStreams(redisOpts, cfg.Events.StreamMaxLen)
+		if err != nil {
+			log.Fatalf("Failed to initialize event broker: %v", err)
+		}
+		relay := service.NewOutboxRelay(outboxRepo, eventBroker, locker, service.OutboxRelayOptions{
+			PollInterval: cfg.Events.PollInterval,
+			BatchSize:    cfg.Events.BatchSize,
+			MaxAttempts:  cfg.Events.MaxAttempts,
+			RetryBackoff: cfg.Events.RetryBackoff,
+			MaxBackoff:   cfg.Events.MaxBackoff,
+		})
+		go relay.Run(context.Background())
+	}
 
 	// Transport layer
 	r := gin.Default()
+	if err := r.SetTrustedProxies(cfg.HTTP.TrustedProxies); err != nil {
+		log.Fatalf("Failed to set trusted proxies: %v", err)
+	}
+	r.Use(middleware.CORS(func() []string { return live.Config().HTTP.CORSOrigins }))
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	if blobHandler, ok := blobStore.(http.Handler); ok {
+		r.GET("/blobs/*key", gin.WrapH(http.StripPrefix("/blobs", blobHandler)))
+	}
+
+	// Connection pool counters for the Redis cache; 404 while it is degraded or not in use.
+	// The /debug routes expose internals shared by every tenant, so only operators may read them.
+	debug := r.Group("/debug", middleware.AuthMiddleware(authService),
+		middleware.RequireOperator(func() []string { return live.Config().Auth.Operators }))
+	debug.GET("/cache/stats", func(c *gin.Context) {
+		stats, ok := cache.Stats(cacheService)
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "cache has no connection pool"})
+			return
+		}
+		c.JSON(http.StatusOK, stats)
+	})
+
+	// Connection pool counters for the primary database and each read replica.
+	debug.GET("/db/stats", func(c *gin.Context) {
+		stats, err := config.DBStats(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, stats)
+	})
+
+	// Outbox backlog and the most recently dead-lettered events of every tenant.
+	debug.GET("/outbox", func(c *gin.Context) {
+		stats, err := outboxRepo.Stats(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		dead, err := outboxRepo.DeadLetters(c.Request.Context(), 50)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"pending": stats.Pending, "dead": stats.Dead, "dead_letters": dead})
+	})
 
-	userHandler := handler.NewUserHandler(userService)
+	userHandler := handler.NewUserHandler(userService, avatarService, orgService)
 	authHandler := handler.NewAuthHandler(authService)
 	paymentHandler := handler.NewPaymentHandler(paymentService)
-	apppkg.SetupRoutes(r, userHandler, authHandler, paymentHandler)
+	privacyHandler := handler.NewPrivacyHandler(privacyService)
+	importHandler := handler.NewImportHandler(importService)
+	orgHandler := handler.NewOrganizationHandler(orgService)
+	invitationHandler := handler.NewInvitationHandler(invitationService)
+	accountHandler := handler.NewAccountHandler(accountService)
+	profileHandler := handler.NewProfileHandler(userService, preferenceService, avatarService)
+	limits := apppkg.RouteLimits{
+		Auth: middleware.RateLimit(limiter, "auth",
+			live.RateLimit(func(c *config.Config) string { return c.RateLimit.Auth }), middleware.RateLimitByIP),
+		API: middleware.RateLimit(limiter, "api",
+			live.RateLimit(func(c *config.Config) string { return c.RateLimit.API }), middleware.RateLimitByClient),
+		Payments: middleware.RateLimit(limiter, "payments",
+			live.RateLimit(func(c *config.Config) string { return c.RateLimit.Payments }), middleware.RateLimitByClient),
+	}
+	apppkg.SetupRoutes(
+		r, middleware.AuthMiddleware(authService), limits,
+		userHandler, authHandler, paymentHandler, privacyHandler,
+		importHandler, orgHandler, invitationHandler, accountHandler, profileHandler,
+	)
 
-	srv := &http.Server{Addr: ":8080", Handler: r}
+	srv := &http.Server{Addr: fmt.Sprintf(":%d", cfg.HTTP.Port), Handler: r}
 
 	go func() {
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -73,7 +224,13 @@ func main() {
 	}()
 
 	go func() {
-		if err := grpcserver.StartGrpcServer(cacheService); err != nil {
+		grpcOpts := grpcserver.Options{
+			Port:         cfg.GRPC.Port,
+			RateLimit:    live.RateLimit(func(c *config.Config) string { return c.RateLimit.GRPC }),
+			UserCacheTTL: userTTL,
+			JWTSecret:    cfg.Secret("auth.jwt_secret").Get,
+		}
+		if err := grpcserver.StartGrpcServer(db, cacheService, limiter, grpcOpts); err != nil {
 			log.Fatalf("gRPC server error: %v", err)
 		}
 	}()
@@ -81,12 +238,12 @@ func main() {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
-	log.Println("Shutting down servers...")
+	slog.Info("shutting down servers")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Fatal("Server forced to shutdown:", err)
 	}
-	log.Println("Servers exited properly")
+	slog.Info("servers exited properly")
 }
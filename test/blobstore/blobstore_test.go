@@ -0,0 +1,138 @@
+package blobstore_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go-gin-project/internal/pkg/blobstore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// minioStub is a minimal S3-compatible server: it stores objects in memory,
+// requires SigV4 headers on writes and presigned query parameters on reads.
+type minioStub struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (m *minioStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut, http.MethodDelete:
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=minio/") ||
+			!strings.Contains(auth, "/us-east-1/s3/aws4_request") {
+			http.Error(w, "AccessDenied", http.StatusForbidden)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		sum := sha256.Sum256(body)
+		if r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) {
+			http.Error(w, "XAmzContentSHA256Mismatch", http.StatusBadRequest)
+			return
+		}
+		if r.Method == http.MethodPut {
+			m.objects[r.URL.Path] = body
+		} else {
+			delete(m.objects, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	case http.MethodGet:
+		q := r.URL.Query()
+		if q.Get("X-Amz-Algorithm") != "AWS4-HMAC-SHA256" || q.Get("X-Amz-Signature") == "" {
+			http.Error(w, "AccessDenied", http.StatusForbidden)
+			return
+		}
+		obj, ok := m.objects[r.URL.Path]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		w.Write(obj) //nolint:errcheck
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestS3Store(t *testing.T) {
+	stub := &minioStub{objects: map[string][]byte{}}
+	srv := httptest.NewServer(stub)
+	defer srv.Close()
+
+	store, err := blobstore.NewS3(blobstore.S3Config{
+		Endpoint:  srv.URL,
+		Bucket:    "avatars",
+		AccessKey: "minio",
+		SecretKey: "minio-secret",
+	})
+	require.NoError(t, err)
+
+	t.Run("put, presigned get and delete", func(t *testing.T) {
+		require.NoError(t, store.Put("avatars/1/abc/small.png", strings.NewReader("png-bytes"), 9, "image/png"))
+		assert.Equal(t, []byte("png-bytes"), stub.objects["/avatars/avatars/1/abc/small.png"])
+
+		signed, err := store.SignedURL("avatars/1/abc/small.png", time.Hour)
+		require.NoError(t, err)
+		assert.Contains(t, signed, "X-Amz-Expires=3600")
+
+		resp, err := http.Get(signed)
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "png-bytes", string(body))
+
+		require.NoError(t, store.Delete("avatars/1/abc/small.png"))
+		assert.Empty(t, stub.objects)
+	})
+
+	t.Run("ttl beyond seven days is rejected", func(t *testing.T) {
+		_, err := store.SignedURL("avatars/1/abc/small.png", 8*24*time.Hour)
+		assert.Error(t, err)
+	})
+}
+
+func TestLocalStore(t *testing.T) {
+	store, err := blobstore.NewLocal(t.TempDir(), "http://example.test/blobs", []byte("signing-key"))
+	require.NoError(t, err)
+	handler, ok := store.(http.Handler)
+	require.True(t, ok)
+
+	require.NoError(t, store.Put("avatars/2/def/large.png", strings.NewReader("image"), 5, "image/png"))
+
+	t.Run("signed url is served", func(t *testing.T) {
+		signed, err := store.SignedURL("avatars/2/def/large.png", time.Minute)
+		require.NoError(t, err)
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, strings.TrimPrefix(signed, "http://example.test/blobs"), nil)
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "image", rec.Body.String())
+	})
+
+	t.Run("tampered signature is rejected", func(t *testing.T) {
+		signed, err := store.SignedURL("avatars/2/def/large.png", time.Minute)
+		require.NoError(t, err)
+
+		rec := httptest.NewRecorder()
+		path := strings.Replace(strings.TrimPrefix(signed, "http://example.test/blobs"), "large", "small", 1)
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("keys cannot escape the directory", func(t *testing.T) {
+		assert.Error(t, store.Put("../outside.png", strings.NewReader("x"), 1, "image/png"))
+	})
+}
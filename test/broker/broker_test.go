@@ -0,0 +1,55 @@
+package broker_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"go-gin-project/internal/pkg/broker"
+	"go-gin-project/internal/pkg/cache"
+	"go-gin-project/internal/pkg/model"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisStreams(t *testing.T) {
+	ctx := context.Background()
+	srv := miniredis.RunT(t)
+	b, err := broker.NewRedisStreams(cache.RedisOptions{Addrs: []string{srv.Addr()}, KeyPrefix: "test:"}, 1000)
+	require.NoError(t, err)
+
+	created := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	for i, status := range []string{"processing", "succeeded"} {
+		payload, _ := json.Marshal(model.PaymentStatusChanged{PaymentID: 3, To: status})
+		require.NoError(t, b.Publish(ctx, &model.Event{
+			ID: uint(i + 1), Type: model.EventPaymentStatusChanged,
+			AggregateType: model.AggregatePayment, AggregateID: "3",
+			Payload: payload, CreatedAt: created,
+		}))
+	}
+
+	entries, err := srv.Stream("test:events:payment")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, []string{
+		"id", "2",
+		"type", "payment.status_changed",
+		"aggregate_type", "payment",
+		"aggregate_id", "3",
+		"payload", `{"payment_id":3,"stripe_id":"","user_id":0,"from":"","to":"succeeded"}`,
+		"created_at", "2026-10-19T12:00:00Z",
+	}, entries[1].Values)
+}
+
+func TestMemory(t *testing.T) {
+	b := broker.NewMemory()
+	require.NoError(t, b.Publish(context.Background(), &model.Event{ID: 1}))
+	require.NoError(t, b.Publish(context.Background(), &model.Event{ID: 2}))
+
+	events := b.Events()
+	require.Len(t, events, 2)
+	assert.Equal(t, uint(2), events[1].ID)
+}
@@ -0,0 +1,214 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"go-gin-project/internal/pkg/cache"
+	"go-gin-project/internal/pkg/model"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryCache(t *testing.T) {
+	ctx := context.Background()
+	t.Run("evicts least recently used entry", func(t *testing.T) {
+		c := cache.NewMemory(2, 0)
+		require.NoError(t, c.Set(ctx, "a", 1, 0))
+		require.NoError(t, c.Set(ctx, "b", 2, 0))
+
+		var v int
+		require.NoError(t, c.Get(ctx, "a", &v)) // "a" becomes most recently used
+		require.NoError(t, c.Set(ctx, "c", 3, 0))
+
+		assert.ErrorIs(t, c.Get(ctx, "b", &v), cache.ErrMiss)
+		assert.NoError(t, c.Get(ctx, "a", &v))
+		assert.Equal(t, 1, v)
+	})
+
+	t.Run("byte bound evicts oldest", func(t *testing.T) {
+		c := cache.NewMemory(0, 10)
+		require.NoError(t, c.Set(ctx, "a", "12345", 0)) // 7 bytes JSON-encoded
+		require.NoError(t, c.Set(ctx, "b", "12345", 0))
+
+		var v string
+		assert.ErrorIs(t, c.Get(ctx, "a", &v), cache.ErrMiss)
+		assert.NoError(t, c.Get(ctx, "b", &v))
+	})
+
+	t.Run("expired entries miss", func(t *testing.T) {
+		c := cache.NewMemory(10, 0)
+		require.NoError(t, c.Set(ctx, "k", "v", time.Millisecond))
+		time.Sleep(5 * time.Millisecond)
+
+		var v string
+		assert.ErrorIs(t, c.Get(ctx, "k", &v), cache.ErrMiss)
+	})
+
+	t.Run("values are copied", func(t *testing.T) {
+		c := cache.NewMemory(10, 0)
+		user := &model.User{ID: 1, Name: "Alice"}
+		require.NoError(t, c.Set(ctx, "user:1", user, time.Minute))
+		user.Name = "Changed"
+
+		var got model.User
+		require.NoError(t, c.Get(ctx, "user:1", &got))
+		assert.Equal(t, "Alice", got.Name)
+	})
+}
+
+// flakyCache is a primary cache whose availability the test controls.
+type flakyCache struct {
+	mu      sync.Mutex
+	down    bool
+	store   model.CacheService
+	deletes []string
+}
+
+var errDown = errors.New("connection refused")
+
+func (f *flakyCache) isDown() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.down
+}
+
+func (f *flakyCache) setDown(down bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.down = down
+}
+
+func (f *flakyCache) Get(ctx context.Context, key string, dest interface{}) error {
+	if f.isDown() {
+		return errDown
+	}
+	return f.store.Get(ctx, key, dest)
+}
+
+func (f *flakyCache) Set(ctx context.Context, key string, value interface{}, exp time.Duration, tags ...string) error {
+	if f.isDown() {
+		return errDown
+	}
+	return f.store.Set(ctx, key, value, exp, tags...)
+}
+
+func (f *flakyCache) Delete(ctx context.Context, key string) error {
+	if f.isDown() {
+		return errDown
+	}
+	f.mu.Lock()
+	f.deletes = append(f.deletes, key)
+	f.mu.Unlock()
+	return f.store.Delete(ctx, key)
+}
+
+func (f *flakyCache) InvalidateTag(ctx context.Context, tag string) error {
+	if f.isDown() {
+		return errDown
+	}
+	return f.store.InvalidateTag(ctx, tag)
+}
+
+func (f *flakyCache) Ping() error {
+	if f.isDown() {
+		return errDown
+	}
+	return nil
+}
+
+func TestFallbackCache(t *testing.T) {
+	ctx := context.Background()
+	primary := &flakyCache{store: cache.NewMemory(10, 0)}
+	connect := func() (model.CacheService, error) {
+		if primary.isDown() {
+			return nil, errDown
+		}
+		return primary, nil
+	}
+	c := cache.NewFallback(connect, cache.NewMemory(10, 0), 10*time.Millisecond)
+
+	require.NoError(t, c.Set(ctx, "user:1", "cached", time.Minute))
+	require.NoError(t, c.Set(ctx, "payment:pi_1", "cached", time.Minute, "user:3"))
+	var v string
+	require.NoError(t, c.Get(ctx, "user:1", &v))
+
+	// Redis goes away: reads and writes keep working against the local cache
+	primary.setDown(true)
+	assert.ErrorIs(t, c.Get(ctx, "user:1", &v), cache.ErrMiss)
+	require.NoError(t, c.Set(ctx, "user:2", "local", time.Minute))
+	require.NoError(t, c.Get(ctx, "user:2", &v))
+	assert.Equal(t, "local", v)
+	require.NoError(t, c.Delete(ctx, "user:1"))
+	require.NoError(t, c.InvalidateTag(ctx, "user:3"))
+
+	// Redis returns: the outage's evictions are replayed and the primary is used again
+	primary.setDown(false)
+	assert.Eventually(t, func() bool {
+		primary.mu.Lock()
+		defer primary.mu.Unlock()
+		return len(primary.deletes) == 1
+	}, time.Second, 5*time.Millisecond)
+	assert.ErrorIs(t, c.Get(ctx, "user:1", &v), cache.ErrMiss)
+	assert.ErrorIs(t, c.Get(ctx, "user:2", &v), cache.ErrMiss)
+	assert.ErrorIs(t, c.Get(ctx, "payment:pi_1", &v), cache.ErrMiss)
+}
+
+func TestMemoryCache_InvalidateTag(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewMemory(10, 0)
+	require.NoError(t, c.Set(ctx, "user:1", "u", time.Minute, "user:1"))
+	require.NoError(t, c.Set(ctx, "payment:pi_1", "p", time.Minute, "user:1"))
+	require.NoError(t, c.Set(ctx, "user:2", "u", time.Minute, "user:2"))
+
+	require.NoError(t, c.InvalidateTag(ctx, "user:1"))
+	var v string
+	assert.ErrorIs(t, c.Get(ctx, "user:1", &v), cache.ErrMiss)
+	assert.ErrorIs(t, c.Get(ctx, "payment:pi_1", &v), cache.ErrMiss)
+	assert.NoError(t, c.Get(ctx, "user:2", &v))
+}
+
+func TestNoopCache(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewNoop()
+	require.NoError(t, c.Set(ctx, "k", "v", time.Minute))
+	var v string
+	assert.ErrorIs(t, c.Get(ctx, "k", &v), cache.ErrMiss)
+}
+
+func TestFallbackCache_PurgesAfterTooManyEvictions(t *testing.T) {
+	ctx := context.Background()
+	srv := miniredis.RunT(t)
+	connect := func() (model.CacheService, error) {
+		return cache.New(cache.RedisOptions{Addrs: []string{srv.Addr()}})
+	}
+	c := cache.NewFallback(connect, cache.NewMemory(10, 0), 10*time.Millisecond)
+	require.NoError(t, c.Set(ctx, "user:1", "cached", time.Minute))
+	require.NoError(t, srv.Set("ratelimit:ip:1", "1"))
+	_, err := srv.XAdd("events:payment", "*", []string{"type", "payment.created"})
+	require.NoError(t, err)
+	require.NoError(t, srv.Set("other-app:key", "1"))
+
+	srv.Close()
+	var v string
+	assert.Error(t, c.Get(ctx, "user:1", &v))
+	// More evictions than the fallback remembers one by one.
+	for i := 0; i < 20000; i++ {
+		require.NoError(t, c.Delete(ctx, fmt.Sprintf("user:%d", i)))
+	}
+
+	require.NoError(t, srv.Restart())
+	assert.Eventually(t, func() bool { return !srv.Exists("cache:user:1") }, time.Second, 5*time.Millisecond)
+	assert.True(t, srv.Exists("ratelimit:ip:1"), "rate-limit counters survive the purge")
+	assert.True(t, srv.Exists("events:payment"), "unread events survive the purge")
+	assert.True(t, srv.Exists("other-app:key"), "keys outside the cache namespace survive the purge")
+	assert.Eventually(t, func() bool {
+		return c.Set(ctx, "user:2", "fresh", time.Minute) == nil && srv.Exists("cache:user:2")
+	}, time.Second, 5*time.Millisecond)
+}
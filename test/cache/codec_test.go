@@ -0,0 +1,120 @@
+package cache_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	pb "go-gin-project/api/proto"
+	"go-gin-project/internal/pkg/cache"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type cachedUser struct {
+	Name      string
+	CreatedAt time.Time
+}
+
+func TestSerializer_RoundTrip(t *testing.T) {
+	zone := time.FixedZone("UTC+2", 2*60*60)
+	in := cachedUser{Name: strings.Repeat("x", 2000), CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 123456789, zone)}
+
+	for _, codec := range []cache.Codec{cache.JSON, cache.MessagePack, cache.Protobuf} {
+		for _, compression := range []cache.Compression{cache.CompressionNone, cache.CompressionZstd, cache.CompressionSnappy} {
+			s := cache.NewSerializer(codec, compression, 0)
+			data, err := s.Encode(in)
+			require.NoError(t, err)
+			if compression != cache.CompressionNone {
+				assert.Less(t, len(data), 2000, "%s/%d should compress", codec.Name(), compression)
+			}
+
+			var out cachedUser
+			// Any serializer can read what another wrote.
+			require.NoError(t, cache.NewSerializer(nil, cache.CompressionNone, 0).Decode(data, &out))
+			assert.Equal(t, in.Name, out.Name)
+			assert.True(t, in.CreatedAt.Equal(out.CreatedAt))
+		}
+	}
+}
+
+func TestSerializer_SmallValuesUncompressedAndLegacyJSON(t *testing.T) {
+	s := cache.NewSerializer(cache.JSON, cache.CompressionZstd, 0)
+	data, err := s.Encode("short")
+	require.NoError(t, err)
+	assert.Equal(t, `"short"`, string(data[3:]))
+
+	var v string
+	require.NoError(t, s.Decode([]byte(`"written before versioning"`), &v))
+	assert.Equal(t, "written before versioning", v)
+}
+
+func TestSerializer_Protobuf(t *testing.T) {
+	s := cache.NewSerializer(cache.Protobuf, cache.CompressionNone, 0)
+	data, err := s.Encode(&pb.UserResponse{Id: "1", Email: "a@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, cache.Protobuf.ID(), data[1])
+
+	var out pb.UserResponse
+	require.NoError(t, s.Decode(data, &out))
+	assert.Equal(t, "a@example.com", out.Email)
+}
+
+func TestRedisCache_Codec(t *testing.T) {
+	ctx := context.Background()
+	srv := miniredis.RunT(t)
+	c, err := cache.New(cache.RedisOptions{
+		Addrs:       []string{srv.Addr()},
+		Codec:       cache.MessagePack,
+		Compression: cache.CompressionSnappy,
+	})
+	require.NoError(t, err)
+	require.NoError(t, c.Set(ctx, "user:1", cachedUser{Name: "Ada"}, time.Minute))
+
+	raw, err := srv.Get("cache:user:1")
+	require.NoError(t, err)
+	assert.Equal(t, cache.MessagePack.ID(), raw[1])
+
+	var out cachedUser
+	require.NoError(t, c.Get(ctx, "user:1", &out))
+	assert.Equal(t, "Ada", out.Name)
+
+	// A value written by an older JSON-only release still reads.
+	require.NoError(t, srv.Set("cache:user:2", `{"Name":"Grace"}`))
+	require.NoError(t, c.Get(ctx, "user:2", &out))
+	assert.Equal(t, "Grace", out.Name)
+}
+
+func TestLoadingCache_Codec(t *testing.T) {
+	ctx := context.Background()
+	srv := miniredis.RunT(t)
+	inner, err := cache.New(cache.RedisOptions{Addrs: []string{srv.Addr()}, Codec: cache.MessagePack})
+	require.NoError(t, err)
+	c := cache.NewLoading(inner, cache.LoadingOptions{Codec: cache.MessagePack})
+
+	zone := time.FixedZone("UTC+2", 2*60*60)
+	in := cachedUser{Name: "Ada", CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 123456789, zone)}
+	load := func(context.Context) (interface{}, error) { return in, nil }
+
+	var loaded, cached cachedUser
+	require.NoError(t, c.GetOrLoad(ctx, "user:1", &loaded, time.Minute, load))
+	require.NoError(t, c.GetOrLoad(ctx, "user:1", &cached, time.Minute, func(context.Context) (interface{}, error) {
+		t.Fatal("second read should hit the cache")
+		return nil, nil
+	}))
+	for _, out := range []cachedUser{loaded, cached} {
+		assert.Equal(t, in.Name, out.Name)
+		assert.True(t, in.CreatedAt.Equal(out.CreatedAt))
+	}
+
+	// The value inside the envelope carries the codec's own header, not JSON.
+	raw, err := srv.Get("cache:user:1")
+	require.NoError(t, err)
+	var env struct{ Value []byte }
+	require.NoError(t, cache.NewSerializer(nil, cache.CompressionNone, 0).Decode([]byte(raw), &env))
+	require.NotEmpty(t, env.Value)
+	assert.Equal(t, cache.MessagePack.ID(), env.Value[1])
+}
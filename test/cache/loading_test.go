@@ -0,0 +1,172 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-gin-project/internal/pkg/cache"
+	"go-gin-project/internal/pkg/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadingCache_GetOrLoad(t *testing.T) {
+	ctx := context.Background()
+	t.Run("concurrent misses share one load", func(t *testing.T) {
+		c := cache.NewLoading(cache.NewMemory(100, 0), cache.LoadingOptions{})
+		var calls atomic.Int32
+		release := make(chan struct{})
+		load := func(context.Context) (interface{}, error) {
+			calls.Add(1)
+			<-release
+			return "value", nil
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				var v string
+				assert.NoError(t, c.GetOrLoad(ctx, "hot", &v, time.Minute, load))
+				assert.Equal(t, "value", v)
+			}()
+		}
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("a bare value under the key is reloaded", func(t *testing.T) {
+		inner := cache.NewMemory(100, 0)
+		require.NoError(t, inner.Set(ctx, "user:1", map[string]string{"name": "Old"}, time.Minute))
+		c := cache.NewLoading(inner, cache.LoadingOptions{})
+
+		var v string
+		require.NoError(t, c.GetOrLoad(ctx, "user:1", &v, time.Minute, func(context.Context) (interface{}, error) {
+			return "loaded", nil
+		}))
+		assert.Equal(t, "loaded", v)
+	})
+
+	t.Run("not found is cached briefly", func(t *testing.T) {
+		c := cache.NewLoading(cache.NewMemory(100, 0), cache.LoadingOptions{NegativeTTL: time.Minute})
+		var calls int
+		load := func(context.Context) (interface{}, error) {
+			calls++
+			return nil, model.ErrNotFound
+		}
+
+		var v string
+		assert.ErrorIs(t, c.GetOrLoad(ctx, "missing", &v, time.Minute, load), model.ErrNotFound)
+		assert.ErrorIs(t, c.GetOrLoad(ctx, "missing", &v, time.Minute, load), model.ErrNotFound)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("other errors are not cached", func(t *testing.T) {
+		c := cache.NewLoading(cache.NewMemory(100, 0), cache.LoadingOptions{})
+		var calls int
+		load := func(context.Context) (interface{}, error) {
+			calls++
+			return nil, errors.New("db down")
+		}
+
+		var v string
+		assert.Error(t, c.GetOrLoad(ctx, "k", &v, time.Minute, load))
+		assert.Error(t, c.GetOrLoad(ctx, "k", &v, time.Minute, load))
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("stale value is served while refreshing", func(t *testing.T) {
+		c := cache.NewLoading(cache.NewMemory(100, 0), cache.LoadingOptions{StaleFor: time.Minute})
+		var version atomic.Int32
+		load := func(context.Context) (interface{}, error) {
+			return version.Add(1), nil
+		}
+
+		var v int32
+		require.NoError(t, c.GetOrLoad(ctx, "k", &v, 10*time.Millisecond, load))
+		assert.Equal(t, int32(1), v)
+
+		time.Sleep(20 * time.Millisecond)
+		require.NoError(t, c.GetOrLoad(ctx, "k", &v, 10*time.Millisecond, load))
+		assert.Equal(t, int32(1), v, "stale value returned immediately")
+
+		assert.Eventually(t, func() bool {
+			var fresh int32
+			return c.GetOrLoad(ctx, "k", &fresh, time.Hour, load) == nil && fresh >= 2
+		}, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("a cancelled leader does not fail the waiters", func(t *testing.T) {
+		c := cache.NewLoading(cache.NewMemory(100, 0), cache.LoadingOptions{})
+		release := make(chan struct{})
+		load := func(loadCtx context.Context) (interface{}, error) {
+			<-release
+			return "value", loadCtx.Err()
+		}
+
+		leaderCtx, cancel := context.WithCancel(ctx)
+		leaderDone := make(chan error)
+		go func() {
+			var v string
+			leaderDone <- c.GetOrLoad(leaderCtx, "k", &v, time.Minute, load)
+		}()
+		time.Sleep(10 * time.Millisecond)
+		waiterDone := make(chan string)
+		go func() {
+			var v string
+			assert.NoError(t, c.GetOrLoad(ctx, "k", &v, time.Minute, load))
+			waiterDone <- v
+		}()
+		time.Sleep(10 * time.Millisecond)
+
+		cancel()
+		assert.ErrorIs(t, <-leaderDone, context.Canceled)
+		close(release)
+		assert.Equal(t, "value", <-waiterDone)
+	})
+
+	t.Run("invalidation during a load keeps its result out", func(t *testing.T) {
+		for name, invalidate := range map[string]func(c model.LoadingCache) error{
+			"delete": func(c model.LoadingCache) error { return c.Delete(ctx, "k") },
+			"tag":    func(c model.LoadingCache) error { return c.InvalidateTag(ctx, "user:1") },
+		} {
+			t.Run(name, func(t *testing.T) {
+				c := cache.NewLoading(cache.NewMemory(100, 0), cache.LoadingOptions{})
+				var version atomic.Int32
+				started, release := make(chan struct{}, 1), make(chan struct{})
+				load := func(context.Context) (interface{}, error) {
+					v := version.Add(1)
+					if v == 1 {
+						started <- struct{}{}
+						<-release
+					}
+					return model.Tagged{Value: v, Tags: []string{"user:1"}}, nil
+				}
+
+				done := make(chan int32)
+				go func() {
+					var v int32
+					assert.NoError(t, c.GetOrLoad(ctx, "k", &v, time.Minute, load))
+					done <- v
+				}()
+				<-started
+				require.NoError(t, invalidate(c))
+				close(release)
+				assert.Equal(t, int32(1), <-done, "the caller still gets what it waited for")
+
+				var v int32
+				require.NoError(t, c.GetOrLoad(ctx, "k", &v, time.Minute, load))
+				assert.Equal(t, int32(2), v, "the stale result was not cached")
+			})
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-gin-project/internal/pkg/cache"
+	"go-gin-project/internal/pkg/model"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockers(t *testing.T) {
+	lockers := map[string]func(t *testing.T) (model.Locker, func(time.Duration)){
+		"memory": func(t *testing.T) (model.Locker, func(time.Duration)) {
+			return cache.NewMemoryLocker(), time.Sleep
+		},
+		"redis": func(t *testing.T) (model.Locker, func(time.Duration)) {
+			srv := miniredis.RunT(t)
+			l, err := cache.NewLocker(cache.RedisOptions{Addrs: []string{srv.Addr()}})
+			require.NoError(t, err)
+			return l, srv.FastForward
+		},
+	}
+
+	for name, newLocker := range lockers {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			locker, advance := newLocker(t)
+
+			first, err := locker.Obtain(ctx, "payment:pi_1", 50*time.Millisecond)
+			require.NoError(t, err)
+
+			// A second caller waits while the lock is held
+			waitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+			defer cancel()
+			_, err = locker.Obtain(waitCtx, "payment:pi_1", time.Second)
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+			// Other keys are independent
+			other, err := locker.Obtain(ctx, "payment:pi_2", time.Second)
+			require.NoError(t, err)
+			require.NoError(t, other.Release(ctx))
+
+			// Once the first lock expires it is taken over with a larger token,
+			// and the expired holder can no longer release it
+			advance(60 * time.Millisecond)
+			second, err := locker.Obtain(ctx, "payment:pi_1", time.Second)
+			require.NoError(t, err)
+			assert.Greater(t, second.Token(), first.Token())
+			assert.ErrorIs(t, first.Release(ctx), model.ErrLockNotHeld)
+
+			require.NoError(t, second.Release(ctx))
+			third, err := locker.Obtain(ctx, "payment:pi_1", time.Second)
+			require.NoError(t, err)
+			assert.Greater(t, third.Token(), second.Token())
+		})
+	}
+}
+
+func TestFallbackLocker(t *testing.T) {
+	ctx := context.Background()
+	srv := miniredis.RunT(t)
+	primary, err := cache.NewLocker(cache.RedisOptions{Addrs: []string{srv.Addr()}})
+	require.NoError(t, err)
+	l := cache.NewFallbackLocker(primary, cache.NewMemoryLocker(), time.Minute)
+
+	srv.Close()
+	lock, err := l.Obtain(ctx, "payment:pi_1", time.Second)
+	require.NoError(t, err)
+
+	// The local lock still excludes callers within this instance
+	waitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	_, err = l.Obtain(waitCtx, "payment:pi_1", time.Second)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	require.NoError(t, lock.Release(ctx))
+}
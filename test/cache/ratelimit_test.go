@@ -0,0 +1,90 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-gin-project/internal/pkg/cache"
+	"go-gin-project/internal/pkg/model"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiters(t *testing.T) {
+	limiters := map[string]func(t *testing.T) model.RateLimiter{
+		"memory": func(t *testing.T) model.RateLimiter { return cache.NewMemoryRateLimiter() },
+		"redis": func(t *testing.T) model.RateLimiter {
+			srv := miniredis.RunT(t)
+			l, err := cache.NewRateLimiter(cache.RedisOptions{Addrs: []string{srv.Addr()}})
+			require.NoError(t, err)
+			return l
+		},
+	}
+	limit := model.RateLimit{Rate: 3, Period: 300 * time.Millisecond, Burst: 3}
+
+	for name, newLimiter := range limiters {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			l := newLimiter(t)
+
+			for want := 2; want >= 0; want-- {
+				res, err := l.Allow(ctx, "login:ip:1.2.3.4", limit)
+				require.NoError(t, err)
+				assert.True(t, res.Allowed)
+				assert.Equal(t, 3, res.Limit)
+				assert.Equal(t, want, res.Remaining)
+			}
+
+			res, err := l.Allow(ctx, "login:ip:1.2.3.4", limit)
+			require.NoError(t, err)
+			assert.False(t, res.Allowed)
+			assert.InDelta(t, 100*time.Millisecond, res.RetryAfter, float64(20*time.Millisecond))
+
+			// Other keys have their own budget
+			res, err = l.Allow(ctx, "login:ip:5.6.7.8", limit)
+			require.NoError(t, err)
+			assert.True(t, res.Allowed)
+
+			// One request is earned back per interval
+			time.Sleep(110 * time.Millisecond)
+			res, err = l.Allow(ctx, "login:ip:1.2.3.4", limit)
+			require.NoError(t, err)
+			assert.True(t, res.Allowed)
+		})
+	}
+}
+
+func TestFallbackRateLimiter(t *testing.T) {
+	ctx := context.Background()
+	srv := miniredis.RunT(t)
+	primary, err := cache.NewRateLimiter(cache.RedisOptions{Addrs: []string{srv.Addr()}})
+	require.NoError(t, err)
+	l := cache.NewFallbackRateLimiter(primary, cache.NewMemoryRateLimiter(), time.Minute)
+
+	srv.Close()
+	limit := model.RateLimit{Rate: 1, Period: time.Minute, Burst: 1}
+	res, err := l.Allow(ctx, "k", limit)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	res, err = l.Allow(ctx, "k", limit)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+}
+
+func TestParseRateLimit(t *testing.T) {
+	l, err := model.ParseRateLimit("100/1s/200")
+	require.NoError(t, err)
+	assert.Equal(t, model.RateLimit{Rate: 100, Period: time.Second, Burst: 200}, l)
+
+	l, err = model.ParseRateLimit("10/1m")
+	require.NoError(t, err)
+	assert.Equal(t, 10, l.Burst)
+
+	for _, bad := range []string{"", "10", "x/1m", "10/soon", "10/1m/0", "10/1ns", "2000/1ms"} {
+		_, err := model.ParseRateLimit(bad)
+		assert.Error(t, err, bad)
+	}
+}
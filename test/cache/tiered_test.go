@@ -0,0 +1,93 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-gin-project/internal/pkg/cache"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTieredCache_InvalidatesOtherInstances(t *testing.T) {
+	ctx := context.Background()
+	srv := miniredis.RunT(t)
+	opts := cache.RedisOptions{Addrs: []string{srv.Addr()}}
+
+	a, err := cache.NewTiered(opts, 100, time.Minute)
+	require.NoError(t, err)
+	b, err := cache.NewTiered(opts, 100, time.Minute)
+	require.NoError(t, err)
+
+	require.NoError(t, a.Set(ctx, "user:1", "v1", time.Minute))
+
+	// b reads through Redis and keeps a local copy
+	var v string
+	require.NoError(t, b.Get(ctx, "user:1", &v))
+	assert.Equal(t, "v1", v)
+
+	// Removing the key behind b's back shows b now serves from its local tier
+	srv.Del("cache:user:1")
+	require.NoError(t, b.Get(ctx, "user:1", &v))
+
+	// A delete on a is broadcast and evicts b's local copy
+	require.NoError(t, a.Delete(ctx, "user:1"))
+	assert.Eventually(t, func() bool {
+		return b.Get(ctx, "user:1", &v) == cache.ErrMiss
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestRedisCache_KeyPrefixDBAndStats(t *testing.T) {
+	ctx := context.Background()
+	srv := miniredis.RunT(t)
+	c, err := cache.New(cache.RedisOptions{Addrs: []string{srv.Addr()}, DB: 2, KeyPrefix: "staging:"})
+	require.NoError(t, err)
+	require.NoError(t, c.Set(ctx, "user:1", "v1", time.Minute))
+
+	srv.Select(2)
+	assert.True(t, srv.Exists("staging:cache:user:1"))
+	assert.False(t, srv.Exists("cache:user:1"))
+
+	stats, ok := cache.Stats(cache.NewLoading(c, cache.LoadingOptions{}))
+	require.True(t, ok)
+	assert.NotZero(t, stats.TotalConns)
+
+	_, ok = cache.Stats(cache.NewMemory(10, 0))
+	assert.False(t, ok)
+}
+
+func TestRedisOptions_Invalid(t *testing.T) {
+	_, err := cache.New(cache.RedisOptions{Mode: cache.RedisCluster, Addrs: []string{"localhost:1"}, DB: 1})
+	assert.ErrorContains(t, err, "cluster mode only supports DB 0")
+}
+
+func TestTieredCache_InvalidateTag(t *testing.T) {
+	ctx := context.Background()
+	srv := miniredis.RunT(t)
+	opts := cache.RedisOptions{Addrs: []string{srv.Addr()}, KeyPrefix: "test:"}
+
+	a, err := cache.NewTiered(opts, 100, time.Minute)
+	require.NoError(t, err)
+	b, err := cache.NewTiered(opts, 100, time.Minute)
+	require.NoError(t, err)
+
+	require.NoError(t, a.Set(ctx, "user:1", "u", time.Minute, "user:1"))
+	require.NoError(t, a.Set(ctx, "payment:pi_1", "p", 2*time.Minute, "user:1"))
+	require.NoError(t, a.Set(ctx, "user:2", "u", time.Minute, "user:2"))
+	assert.Equal(t, 2*time.Minute, srv.TTL("test:cache:tag:user:1"))
+
+	var v string
+	require.NoError(t, b.Get(ctx, "payment:pi_1", &v)) // b now holds a local copy
+
+	require.NoError(t, a.InvalidateTag(ctx, "user:1"))
+	assert.False(t, srv.Exists("test:cache:user:1"))
+	assert.False(t, srv.Exists("test:cache:payment:pi_1"))
+	assert.False(t, srv.Exists("test:cache:tag:user:1"))
+	assert.True(t, srv.Exists("test:cache:user:2"))
+	assert.Eventually(t, func() bool {
+		return b.Get(ctx, "payment:pi_1", &v) == cache.ErrMiss
+	}, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,179 @@
+package config_test
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-gin-project/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func load(args ...string) (*config.Config, error) {
+	return config.Load(flag.NewFlagSet("test", flag.ContinueOnError), args, "db", "auth")
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+db:
+  user: file-user
+  host: db.internal
+  name: app
+  port: 3307
+auth:
+  jwt_secret: from-file
+redis:
+  addrs: [a:26379, b:26379]
+  read_timeout: 500ms
+`)
+	t.Setenv("DB_USER", "env-user")
+	t.Setenv("HTTP_PORT", "9000")
+
+	cfg, err := load("-config", path, "-http-port", "9100", "-redis-tls")
+	require.NoError(t, err)
+
+	assert.Equal(t, "env-user", cfg.DB.User) // env over file
+	assert.Equal(t, 3307, cfg.DB.Port)       // file over default
+	assert.Equal(t, 9100, cfg.HTTP.Port)     // flag over env
+	assert.Equal(t, 50051, cfg.GRPC.Port)    // default
+	assert.True(t, cfg.Redis.TLS)
+	assert.Equal(t, []string{"a:26379", "b:26379"}, cfg.Redis.Addrs)
+	assert.Equal(t, 500*time.Millisecond, cfg.Redis.ReadTimeout)
+	assert.Equal(t, "from-file", cfg.Auth.JWTSecret)
+}
+
+func TestLoad_TOML(t *testing.T) {
+	path := writeFile(t, "config.toml", `
+env = "staging"
+
+[db]
+user = "u"
+host = "h"
+name = "n"
+
+[auth]
+jwt_secret = "s"
+
+[rate_limit]
+auth = "off"
+`)
+	t.Setenv("CONFIG_FILE", path)
+
+	cfg, err := load()
+	require.NoError(t, err)
+	assert.Equal(t, "staging:", cfg.RedisOptions().KeyPrefix)
+	assert.Equal(t, []string{"localhost:6379"}, cfg.RedisOptions().Addrs)
+
+	limit, err := config.ParseRateLimit(cfg.RateLimit.Auth)
+	require.NoError(t, err)
+	assert.Nil(t, limit)
+}
+
+func TestLoad_ReportsEveryProblem(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+db:
+  user: u
+  hots: typo
+redis:
+  mode: sentinel
+`)
+
+	_, err := load("-config", path, "-grpc-port", "lots", "-cache-driver", "memcached", "-events-broker", "kafka", "-http-trusted-proxies", "10.0.0.0/8,lb")
+	require.Error(t, err)
+	for _, want := range []string{
+		"db.host is required; set db.host, $DB_HOST or -db-host",
+		"db.name is required",
+		"auth.jwt_secret is required",
+		"db.hots: unknown key",
+		"grpc.port (from -grpc-port): want an integer",
+		`cache.driver must be one of [redis memory none], got "memcached"`,
+		"redis.master_name is required in sentinel mode",
+		`events.broker must be one of [redis none], got "kafka"`,
+		`http.trusted_proxies: "lb" is not an IP or CIDR`,
+	} {
+		assert.Contains(t, err.Error(), want)
+	}
+	assert.NotContains(t, err.Error(), "stripe")
+}
+
+func TestLoad_SQLite(t *testing.T) {
+	// Only the database file is needed; user and host are not required
+	_, err := load("-db-driver", "sqlite", "-db-name", "app.db", "-auth-jwt-secret", "s")
+	require.NoError(t, err)
+
+	_, err = load("-db-driver", "sqlite", "-db-name", "app.db", "-auth-jwt-secret", "s", "-db-replicas", "a,b")
+	assert.ErrorContains(t, err, "db.replicas is not supported with sqlite")
+
+	_, err = load("-db-driver", "oracle", "-db-name", "app", "-auth-jwt-secret", "s")
+	assert.ErrorContains(t, err, "db.user is required")
+	assert.ErrorContains(t, err, `db.driver must be one of [mysql postgres sqlite], got "oracle"`)
+}
+
+func TestLive_Reload(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+db: {user: u, host: h, name: n}
+auth: {jwt_secret: s}
+rate_limit: {api: 600/1m}
+`)
+	cfg, err := load("-config", path)
+	require.NoError(t, err)
+	live := config.NewLive(cfg)
+
+	require.NoError(t, os.WriteFile(path, []byte(`
+db: {user: u, host: h, name: n}
+auth: {jwt_secret: s}
+http: {port: 9999, cors_origins: [https://app.example.com]}
+rate_limit: {api: 100/1m}
+cache: {user_ttl: 30s}
+`), 0o600))
+	applied, err := live.Reload()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		"http.cors_origins: [] -> [https://app.example.com]",
+		"cache.user_ttl: 5m0s -> 30s",
+		"rate_limit.api: 600/1m -> 100/1m",
+	}, applied)
+
+	cur := live.Config()
+	assert.Equal(t, 30*time.Second, cur.Cache.UserTTL)
+	assert.Equal(t, 8080, cur.HTTP.Port) // needs a restart
+	limit := live.RateLimit(func(c *config.Config) string { return c.RateLimit.API })()
+	require.NotNil(t, limit)
+	assert.Equal(t, 100, limit.Rate)
+	assert.Equal(t, "600/1m", cfg.RateLimit.API) // swapped, not modified in place
+
+	// An invalid file is rejected and the current settings stay
+	require.NoError(t, os.WriteFile(path, []byte("log: {level: loud}\n"), 0o600))
+	_, err = live.Reload()
+	assert.ErrorContains(t, err, "log.level must be one of")
+	assert.Same(t, cur, live.Config())
+}
+
+func TestLive_ReloadLogLevel(t *testing.T) {
+	path := writeFile(t, "config.yaml", "db: {user: u, host: h, name: n}\nauth: {jwt_secret: s}\n")
+	cfg, err := load("-config", path)
+	require.NoError(t, err)
+	live := config.NewLive(cfg)
+	t.Cleanup(func() { slog.SetLogLoggerLevel(slog.LevelInfo) })
+	ctx := context.Background()
+	assert.True(t, slog.Default().Enabled(ctx, slog.LevelInfo))
+
+	require.NoError(t, os.WriteFile(path, []byte("db: {user: u, host: h, name: n}\nauth: {jwt_secret: s}\nlog: {level: warn}\n"), 0o600))
+	_, err = live.Reload()
+	require.NoError(t, err)
+	assert.False(t, slog.Default().Enabled(ctx, slog.LevelInfo), "application info logs are silenced")
+	assert.True(t, slog.Default().Enabled(ctx, slog.LevelWarn))
+}
@@ -0,0 +1,126 @@
+package server_test
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+
+	pb "go-gin-project/api/proto"
+	"go-gin-project/grpc/server"
+	"go-gin-project/internal/app/service"
+	"go-gin-project/internal/pkg/cache"
+	"go-gin-project/internal/pkg/model"
+	"go-gin-project/internal/pkg/repository"
+	"go-gin-project/test/testdb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestUserGrpcService_AuthAndTenancy(t *testing.T) {
+	ctx := context.Background()
+	db := testdb.Open(t)
+	userRepo := repository.NewUserRepository(db)
+	orgRepo := repository.NewOrganizationRepository(db)
+	memory := cache.NewMemory(100, 0)
+	authService := service.NewAuthService(userRepo, orgRepo, func() string { return "test-secret" })
+	userService := service.NewUserService(userRepo, cache.NewLoading(memory, cache.LoadingOptions{}), service.FixedTTL(0))
+	orgService := service.NewOrganizationService(orgRepo, memory)
+
+	seed := func(orgID uint, email string) *model.User {
+		t.Helper()
+		repo := userRepo
+		if orgID != 0 {
+			repo = repo.WithTenant(orgID)
+		}
+		user, err := repo.Create(ctx, &model.User{Name: "User", Email: email, Password: "password123"})
+		require.NoError(t, err)
+		return user
+	}
+	owner := seed(0, "owner@acme.example.com")
+	acme, err := orgRepo.Create(ctx, &model.Organization{Name: "Acme"}, owner.ID)
+	require.NoError(t, err)
+	member := seed(acme.ID, "member@acme.example.com")
+	other := seed(0, "owner@globex.example.com")
+	globex, err := orgRepo.Create(ctx, &model.Organization{Name: "Globex"}, other.ID)
+	require.NoError(t, err)
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(grpc.UnaryInterceptor(server.AuthInterceptor(authService)))
+	pb.RegisterUserServiceServer(srv, server.NewUserGrpcService(userService, orgService))
+	go srv.Serve(lis) //nolint:errcheck
+	t.Cleanup(srv.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() }) //nolint:errcheck
+	client := pb.NewUserServiceClient(conn)
+
+	as := func(email string, orgID uint) context.Context {
+		t.Helper()
+		login, err := authService.Login(ctx, &service.LoginRequest{Email: email, Password: "password123", OrgID: orgID})
+		require.NoError(t, err)
+		return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+login.Token)
+	}
+	id := func(u *model.User) string { return strconv.FormatUint(uint64(u.ID), 10) }
+
+	t.Run("calls without a valid token are refused", func(t *testing.T) {
+		_, err := client.GetUser(ctx, &pb.GetUserRequest{Id: id(member)})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		bad := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer not-a-token")
+		_, err = client.DeleteUser(bad, &pb.DeleteUserRequest{Id: id(member)})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("users of another organization are not visible", func(t *testing.T) {
+		globexOwner := as("owner@globex.example.com", globex.ID)
+		_, err := client.GetUser(globexOwner, &pb.GetUserRequest{Id: id(member)})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+
+		name := "Hijacked"
+		_, err = client.UpdateUser(globexOwner, &pb.UpdateUserRequest{Id: id(member), Name: &name})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+
+		_, err = client.DeleteUser(globexOwner, &pb.DeleteUserRequest{Id: id(member)})
+		assert.Error(t, err)
+		_, err = orgRepo.FindMembership(ctx, acme.ID, member.ID)
+		assert.NoError(t, err, "the membership is untouched")
+	})
+
+	t.Run("members update only themselves", func(t *testing.T) {
+		asMember := as("member@acme.example.com", acme.ID)
+		got, err := client.GetUser(asMember, &pb.GetUserRequest{Id: id(owner)})
+		require.NoError(t, err)
+		assert.Equal(t, owner.Email, got.Email)
+
+		name := "Renamed"
+		_, err = client.UpdateUser(asMember, &pb.UpdateUserRequest{Id: id(owner), Name: &name})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+		updated, err := client.UpdateUser(asMember, &pb.UpdateUserRequest{Id: id(member), Name: &name})
+		require.NoError(t, err)
+		assert.Equal(t, "Renamed", updated.Name)
+
+		_, err = client.DeleteUser(asMember, &pb.DeleteUserRequest{Id: id(owner)})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("admins remove members from their organization only", func(t *testing.T) {
+		_, err := client.DeleteUser(as("owner@acme.example.com", acme.ID), &pb.DeleteUserRequest{Id: id(member)})
+		require.NoError(t, err)
+		_, err = orgRepo.FindMembership(ctx, acme.ID, member.ID)
+		assert.ErrorIs(t, err, model.ErrNotFound)
+		_, err = userRepo.FindByID(ctx, id(member))
+		assert.NoError(t, err, "the account is kept")
+	})
+}
@@ -0,0 +1,62 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-gin-project/internal/app/middleware"
+	"go-gin-project/internal/pkg/cache"
+	"go-gin-project/internal/pkg/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limit := &model.RateLimit{Rate: 2, Period: time.Minute, Burst: 2}
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if c.GetHeader("X-User") != "" {
+			c.Set("userID", uint(42))
+		}
+	})
+	r.Use(middleware.RateLimit(cache.NewMemoryRateLimiter(), "api", func() *model.RateLimit { return limit }, middleware.RateLimitByClient))
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	do := func(header, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "2;w=60;burst=2", w.Header().Get("RateLimit-Policy"))
+
+	assert.Equal(t, http.StatusNoContent, do("", "").Code)
+	w = do("", "")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+
+	// The same address is counted separately per user, but an unverified
+	// API key does not buy a fresh limit
+	assert.Equal(t, http.StatusNoContent, do("X-User", "1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, do("X-API-Key", "secret").Code)
+
+	// Limits are read per request, so turning them off takes effect at once
+	limit = nil
+	w = do("", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+}
@@ -0,0 +1,55 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-gin-project/internal/app/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireSelfOrOrgAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	do := func(userID uint, role, path string) int {
+		r := gin.New()
+		r.Use(func(c *gin.Context) {
+			c.Set("userID", userID)
+			c.Set("orgID", uint(7))
+			c.Set("orgRole", role)
+		})
+		r.PUT("/api/users/:id", middleware.RequireSelfOrOrgAdmin(), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, path, nil))
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusNoContent, do(3, "member", "/api/users/3"))
+	assert.Equal(t, http.StatusForbidden, do(3, "member", "/api/users/4"))
+	assert.Equal(t, http.StatusNoContent, do(3, "admin", "/api/users/4"))
+	assert.Equal(t, http.StatusNoContent, do(3, "owner", "/api/users/4"))
+}
+
+func TestRequireOperator(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	operators := []string{"ops@example.com"}
+
+	do := func(email string) int {
+		r := gin.New()
+		r.Use(func(c *gin.Context) { c.Set("email", email) })
+		r.GET("/debug/outbox", middleware.RequireOperator(func() []string { return operators }), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/outbox", nil))
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusNoContent, do("Ops@Example.com"))
+	assert.Equal(t, http.StatusForbidden, do("admin@example.com"))
+
+	// The list is read per request, so a reload applies immediately
+	operators = nil
+	assert.Equal(t, http.StatusForbidden, do("ops@example.com"))
+}
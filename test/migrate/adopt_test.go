@@ -0,0 +1,92 @@
+package migrate_test
+
+import (
+	"context"
+	"flag"
+	"path/filepath"
+	"testing"
+
+	"go-gin-project/config"
+	"go-gin-project/internal/pkg/migrate"
+	"go-gin-project/internal/pkg/repository/migrations"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func openSQLite(t *testing.T, path string, args ...string) *gorm.DB {
+	t.Helper()
+	cfg, err := config.Load(flag.NewFlagSet("test", flag.ContinueOnError),
+		append([]string{"-db-driver", "sqlite", "-db-name", path}, args...), "db")
+	require.NoError(t, err)
+	db, err := cfg.OpenDB()
+	require.NoError(t, err)
+	t.Cleanup(func() { config.CloseDB(db) }) //nolint:errcheck
+	return db
+}
+
+func sqliteMigrator(t *testing.T, db *gorm.DB) *migrate.Migrator {
+	t.Helper()
+	source, err := migrations.For("sqlite")
+	require.NoError(t, err)
+	migrator, err := migrate.New(db, source)
+	require.NoError(t, err)
+	return migrator
+}
+
+func TestOpenDB_AppliesMigrations(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "app.db")
+
+	db := openSQLite(t, path, "-db-migrate=false")
+	pending, err := sqliteMigrator(t, db).Pending(ctx)
+	require.NoError(t, err)
+	assert.NotEmpty(t, pending, "with db.migrate off the schema is left alone")
+
+	db = openSQLite(t, path)
+	pending, err = sqliteMigrator(t, db).Pending(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+	assert.True(t, db.Migrator().HasTable("users"))
+}
+
+func TestMigrator_Adopt(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t, filepath.Join(t.TempDir(), "legacy.db"), "-db-migrate=false")
+	// A database from an older release, created by AutoMigrate, with a row
+	// that later migrations have to carry along.
+	require.NoError(t, db.Exec(`CREATE TABLE users (id integer PRIMARY KEY AUTOINCREMENT, name text NOT NULL,
+		email text NOT NULL, password text NOT NULL, created_at datetime, updated_at datetime, deleted_at datetime)`).Error)
+	require.NoError(t, db.Exec(`CREATE TABLE payments (id integer PRIMARY KEY AUTOINCREMENT, user_id integer,
+		amount numeric NOT NULL, currency text NOT NULL, stripe_id text NOT NULL, payment_status text NOT NULL,
+		created_at datetime, updated_at datetime, deleted_at datetime)`).Error)
+	require.NoError(t, db.Exec("INSERT INTO users (name, email, password) VALUES ('Ann', 'ann@example.com', 'x')").Error)
+	migrator := sqliteMigrator(t, db)
+
+	adopted, err := migrator.Adopt(ctx)
+	require.NoError(t, err)
+	require.Len(t, adopted, 1, "only the baseline is recorded")
+	assert.Equal(t, "initial_schema", adopted[0].Name)
+	pending, err := migrator.Pending(ctx)
+	require.NoError(t, err)
+	assert.NotEmpty(t, pending, "later migrations are left to Up")
+
+	_, err = migrator.Up(ctx, 0)
+	require.NoError(t, err)
+	assert.True(t, db.Migrator().HasColumn("users", "token_version"), "later migrations add their columns")
+	assert.True(t, db.Migrator().HasIndex("payments", "idx_payments_org_id"))
+	assert.True(t, db.Migrator().HasTable("outbox_events"))
+	var version int
+	require.NoError(t, db.Raw("SELECT token_version FROM users WHERE email = 'ann@example.com'").Scan(&version).Error)
+	assert.Zero(t, version)
+
+	adopted, err = migrator.Adopt(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, adopted, "a versioned database is not adopted again")
+
+	fresh := sqliteMigrator(t, openSQLite(t, filepath.Join(t.TempDir(), "fresh.db"), "-db-migrate=false"))
+	adopted, err = fresh.Adopt(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, adopted, "an empty database is left to Up")
+}
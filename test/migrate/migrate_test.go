@@ -0,0 +1,84 @@
+package migrate_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"go-gin-project/internal/pkg/migrate"
+	"go-gin-project/internal/pkg/repository/migrations"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	got, err := migrate.Load(fstest.MapFS{
+		"2_add_notes.up.sql":   {Data: []byte("ALTER TABLE payments ADD notes TEXT;\n")},
+		"2_add_notes.down.sql": {Data: []byte("ALTER TABLE payments DROP notes;\n")},
+		"10_later.up.sql":      {Data: []byte("SELECT 1;\n")},
+		"1_initial.up.sql":     {Data: []byte("CREATE TABLE users (id INT);\n")},
+		"README.md":            {Data: []byte("ignored")},
+	})
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+	assert.Equal(t, []int64{1, 2, 10}, []int64{got[0].Version, got[1].Version, got[2].Version})
+	assert.Equal(t, "add_notes", got[1].Name)
+	assert.Contains(t, got[1].Down, "DROP notes")
+	assert.Empty(t, got[0].Down)
+
+	_, err = migrate.Load(fstest.MapFS{"3_no_up.down.sql": {Data: []byte("SELECT 1;\n")}})
+	assert.ErrorContains(t, err, "3_no_up has no up migration")
+	_, err = migrate.Load(fstest.MapFS{
+		"1_a.up.sql": {Data: []byte("SELECT 1;\n")},
+		"1_b.up.sql": {Data: []byte("SELECT 1;\n")},
+	})
+	assert.ErrorContains(t, err, "version 1 is used by")
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	// Every driver has the same versions, each with a down migration
+	var want []int64
+	for _, dialect := range migrations.Dialects {
+		source, err := migrations.For(dialect)
+		require.NoError(t, err)
+		got, err := migrate.Load(source)
+		require.NoError(t, err)
+		require.NotEmpty(t, got, dialect)
+
+		var versions []int64
+		for _, m := range got {
+			versions = append(versions, m.Version)
+			assert.NotEmpty(t, m.Down, "%s: %d_%s has no down migration", dialect, m.Version, m.Name)
+		}
+		if want == nil {
+			want = versions
+		}
+		assert.Equal(t, want, versions, dialect)
+	}
+
+	_, err := migrations.For("oracle")
+	assert.Error(t, err)
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 10, 19, 12, 30, 0, 0, time.UTC)
+
+	up, down, err := migrate.Create(dir, "Add payment-notes", now)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "20261019123000_add_payment_notes.up.sql"), up)
+	assert.Equal(t, filepath.Join(dir, "20261019123000_add_payment_notes.down.sql"), down)
+
+	got, err := migrate.Load(os.DirFS(dir))
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, int64(20261019123000), got[0].Version)
+
+	_, _, err = migrate.Create(dir, "add_payment_notes", now)
+	assert.Error(t, err, "existing files are not overwritten")
+	_, _, err = migrate.Create(dir, "drop users!", now)
+	assert.ErrorContains(t, err, "must use letters, digits and underscores")
+}
@@ -1,27 +1,65 @@
 package mocks
 
 import (
+	"context"
+	"encoding/json"
 	"time"
 
+	"go-gin-project/internal/pkg/model"
+
 	"github.com/stretchr/testify/mock"
 )
 
-// MockCache implements model.CacheService for testing.
+// MockCache implements model.LoadingCache for testing. The context argument is
+// not recorded, so expectations are set on the remaining arguments only.
 type MockCache struct {
 	mock.Mock
 }
 
-func (m *MockCache) Get(key string, dest interface{}) error {
+func (m *MockCache) Get(_ context.Context, key string, dest interface{}) error {
 	args := m.Called(key, dest)
 	return args.Error(0)
 }
 
-func (m *MockCache) Set(key string, value interface{}, expiration time.Duration) error {
-	args := m.Called(key, value, expiration)
+// Set records tags as trailing arguments, so untagged calls match
+// three-argument expectations.
+func (m *MockCache) Set(_ context.Context, key string, value interface{}, expiration time.Duration, tags ...string) error {
+	called := []interface{}{key, value, expiration}
+	for _, tag := range tags {
+		called = append(called, tag)
+	}
+	args := m.Called(called...)
 	return args.Error(0)
 }
 
-func (m *MockCache) Delete(key string) error {
+func (m *MockCache) Delete(_ context.Context, key string) error {
 	args := m.Called(key)
 	return args.Error(0)
 }
+
+func (m *MockCache) InvalidateTag(_ context.Context, tag string) error {
+	args := m.Called(tag)
+	return args.Error(0)
+}
+
+// GetOrLoad is a plain read-through built on Get and Set, so tests can keep
+// setting expectations on those calls.
+func (m *MockCache) GetOrLoad(ctx context.Context, key string, dest interface{}, ttl time.Duration, load func(ctx context.Context) (interface{}, error)) error {
+	if err := m.Get(ctx, key, dest); err == nil {
+		return nil
+	}
+	value, err := load(ctx)
+	if err != nil {
+		return err
+	}
+	var tags []string
+	if t, ok := value.(model.Tagged); ok {
+		value, tags = t.Value, t.Tags
+	}
+	m.Set(ctx, key, value, ttl, tags...) //nolint:errcheck
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dest)
+}
@@ -0,0 +1,53 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"go-gin-project/config"
+	"go-gin-project/internal/pkg/model"
+	"go-gin-project/internal/pkg/repository"
+	"go-gin-project/test/testdb"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+func TestReadReplicas(t *testing.T) {
+	ctx := context.Background()
+	primary := testdb.Open(t)
+	// A second, empty database stands in for a replica that has not caught up
+	replicaDB, err := testdb.Open(t).DB()
+	require.NoError(t, err)
+	require.NoError(t, primary.Use(dbresolver.Register(dbresolver.Config{
+		Replicas: []gorm.Dialector{sqlite.Dialector{Conn: replicaDB}},
+	})))
+
+	users := repository.NewUserRepository(primary)
+	created, err := users.Create(ctx, &model.User{Name: "Ann", Email: "ann@example.com", Password: "password123"})
+	require.NoError(t, err)
+
+	_, err = users.FindByEmail(ctx, created.Email)
+	assert.ErrorIs(t, err, model.ErrNotFound, "plain reads go to the replica")
+	found, err := users.FindByEmail(model.ReadPrimary(ctx), created.Email)
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, found.ID)
+
+	// Read-modify-write paths use the primary without being told
+	payments := repository.NewPaymentRepository(primary)
+	_, err = payments.Create(ctx, &model.Payment{UserID: created.ID, Amount: 10, Currency: "usd", StripeID: "pi_1", PaymentStatus: "processing"})
+	require.NoError(t, err)
+	updated, err := payments.UpdateStatus(ctx, &model.Payment{StripeID: "pi_1", PaymentStatus: "succeeded", FenceToken: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "succeeded", updated.PaymentStatus)
+
+	stats, err := config.DBStats(primary)
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+	assert.Equal(t, "primary", stats[0].Name)
+	assert.Equal(t, "replica-1", stats[1].Name)
+	assert.Equal(t, 1, stats[0].MaxOpen) // SQLite uses a single connection
+}
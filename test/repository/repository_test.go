@@ -0,0 +1,108 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"go-gin-project/internal/pkg/migrate"
+	"go-gin-project/internal/pkg/model"
+	"go-gin-project/internal/pkg/repository"
+	"go-gin-project/internal/pkg/repository/migrations"
+	"go-gin-project/test/testdb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserRepository_UniqueEmail(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewUserRepository(testdb.Open(t))
+
+	first, err := repo.Create(ctx, &model.User{Name: "Ann", Email: "ann@example.com", Password: "password123"})
+	require.NoError(t, err)
+	require.NoError(t, repo.Delete(ctx, "1"))
+
+	// The soft-deleted user still holds the address in the unique index
+	_, err = repo.Create(ctx, &model.User{Name: "Ann", Email: first.Email, Password: "password123"})
+	assert.ErrorIs(t, err, model.ErrDuplicate)
+
+	_, err = repo.CreateBatch(ctx, []*model.User{
+		{Name: "Bo", Email: "bo@example.com", Password: "password123"},
+		{Name: "Bo", Email: "bo@example.com", Password: "password123"},
+	})
+	assert.ErrorIs(t, err, model.ErrDuplicate)
+	_, err = repo.FindByEmail(ctx, "bo@example.com")
+	assert.ErrorIs(t, err, model.ErrNotFound, "the batch is rolled back")
+}
+
+func TestPaymentRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewPaymentRepository(testdb.Open(t))
+
+	created, err := repo.Create(ctx, &model.Payment{UserID: 1, Amount: 19.99, Currency: "usd", StripeID: "pi_1", PaymentStatus: "requires_payment_method"})
+	require.NoError(t, err)
+	_, err = repo.WithTenant(3).Create(ctx, &model.Payment{UserID: 1, Amount: 5, Currency: "usd", StripeID: "pi_2", PaymentStatus: "succeeded"})
+	require.NoError(t, err)
+
+	found, err := repo.FindByStripeID(ctx, "pi_1")
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, found.ID)
+	assert.Equal(t, 19.99, found.Amount)
+
+	_, err = repo.WithTenant(3).FindByStripeID(ctx, "pi_1")
+	assert.ErrorIs(t, err, model.ErrNotFound)
+	payments, err := repo.FindByUserID(ctx, 1)
+	require.NoError(t, err)
+	assert.Len(t, payments, 2)
+
+	// Fenced writes: a newer token wins, the same token may repeat, an older one fails
+	_, err = repo.UpdateStatus(ctx, &model.Payment{StripeID: "pi_1", PaymentStatus: "processing", FenceToken: 2})
+	require.NoError(t, err)
+	_, err = repo.UpdateStatus(ctx, &model.Payment{StripeID: "pi_1", PaymentStatus: "processing", FenceToken: 2})
+	require.NoError(t, err)
+	_, err = repo.UpdateStatus(ctx, &model.Payment{StripeID: "pi_1", PaymentStatus: "canceled", FenceToken: 1})
+	assert.ErrorIs(t, err, model.ErrStaleToken)
+
+	found, err = repo.FindByStripeID(ctx, "pi_1")
+	require.NoError(t, err)
+	assert.Equal(t, "processing", found.PaymentStatus)
+	assert.Equal(t, int64(2), found.FenceToken)
+}
+
+func TestPreferenceRepository_SetUpserts(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewPreferenceRepository(testdb.Open(t))
+
+	require.NoError(t, repo.Set(ctx, 1, map[string]string{"theme": "dark", "digest": "weekly"}))
+	require.NoError(t, repo.Set(ctx, 1, map[string]string{"theme": "light"}))
+	require.NoError(t, repo.Set(ctx, 2, map[string]string{"theme": "dark"}))
+
+	prefs, err := repo.List(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"theme": "light", "digest": "weekly"}, prefs)
+}
+
+func TestMigrations_DownAndUp(t *testing.T) {
+	ctx := context.Background()
+	db := testdb.Open(t)
+	source, err := migrations.For("sqlite")
+	require.NoError(t, err)
+	migrator, err := migrate.New(db, source)
+	require.NoError(t, err)
+
+	all, err := migrate.Load(source)
+	require.NoError(t, err)
+	reverted, err := migrator.Down(ctx, len(all))
+	require.NoError(t, err)
+	assert.Len(t, reverted, len(all))
+	assert.False(t, db.Migrator().HasTable("users"))
+
+	pending, err := migrator.Pending(ctx)
+	require.NoError(t, err)
+	assert.Len(t, pending, len(all))
+
+	applied, err := migrator.Up(ctx, 0)
+	require.NoError(t, err)
+	assert.Len(t, applied, len(all))
+	assert.True(t, db.Migrator().HasTable("users"))
+}
@@ -0,0 +1,64 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"go-gin-project/internal/pkg/model"
+	"go-gin-project/internal/pkg/repository"
+	"go-gin-project/test/testdb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxManager(t *testing.T) {
+	ctx := context.Background()
+	db := testdb.Open(t)
+	tx := repository.NewTxManager(db)
+	users := repository.NewUserRepository(db)
+	payments := repository.NewPaymentRepository(db)
+	failed := errors.New("failed")
+
+	// A failing unit of work undoes all of its repository calls
+	err := tx.WithinTx(ctx, func(ctx context.Context) error {
+		_, err := users.Create(ctx, &model.User{Name: "Ann", Email: "ann@example.com", Password: "password123"})
+		require.NoError(t, err)
+		_, err = payments.Create(ctx, &model.Payment{UserID: 1, Amount: 5, Currency: "usd", StripeID: "pi_1"})
+		require.NoError(t, err)
+		return failed
+	})
+	assert.ErrorIs(t, err, failed)
+	_, err = users.FindByEmail(ctx, "ann@example.com")
+	assert.ErrorIs(t, err, model.ErrNotFound)
+	_, err = payments.FindByStripeID(ctx, "pi_1")
+	assert.ErrorIs(t, err, model.ErrNotFound)
+
+	// A failing nested unit only rolls back to its savepoint
+	err = tx.WithinTx(ctx, func(ctx context.Context) error {
+		user, err := users.Create(ctx, &model.User{Name: "Bo", Email: "bo@example.com", Password: "password123"})
+		require.NoError(t, err)
+		_, err = users.LockByID(ctx, fmt.Sprint(user.ID))
+		require.NoError(t, err)
+		nested := tx.WithinTx(ctx, func(ctx context.Context) error {
+			_, err := payments.Create(ctx, &model.Payment{UserID: user.ID, Amount: 5, Currency: "usd", StripeID: "pi_2"})
+			require.NoError(t, err)
+			return failed
+		})
+		assert.ErrorIs(t, nested, failed)
+		_, err = payments.Create(ctx, &model.Payment{UserID: user.ID, Amount: 7, Currency: "usd", StripeID: "pi_3"})
+		return err
+	})
+	require.NoError(t, err)
+	_, err = users.FindByEmail(ctx, "bo@example.com")
+	assert.NoError(t, err)
+	_, err = payments.FindByStripeID(ctx, "pi_2")
+	assert.ErrorIs(t, err, model.ErrNotFound)
+	_, err = payments.FindByStripeID(ctx, "pi_3")
+	assert.NoError(t, err)
+
+	_, err = users.LockByID(ctx, "99")
+	assert.ErrorIs(t, err, model.ErrNotFound)
+}
@@ -0,0 +1,156 @@
+package secrets_test
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"go-gin-project/config"
+	"go-gin-project/internal/pkg/model"
+	"go-gin-project/internal/pkg/secrets"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// vaultStub serves KV v2 reads of in-memory secrets for one token.
+type vaultStub struct {
+	mu      sync.Mutex
+	secrets map[string]map[string]string // path under the mount -> data
+}
+
+func (v *vaultStub) set(path, key, value string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.secrets[path] == nil {
+		v.secrets[path] = map[string]string{}
+	}
+	v.secrets[path][key] = value
+}
+
+func newVaultStub(t *testing.T) (*vaultStub, *httptest.Server) {
+	stub := &vaultStub{secrets: map[string]map[string]string{}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		path, _ := strings.CutPrefix(r.URL.Path, "/v1/secret/data/")
+		stub.mu.Lock()
+		data, ok := stub.secrets[path]
+		stub.mu.Unlock()
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{ //nolint:errcheck
+			"data": map[string]interface{}{"data": data, "metadata": map[string]int{"version": 1}},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return stub, srv
+}
+
+func TestVault(t *testing.T) {
+	ctx := context.Background()
+	stub, srv := newVaultStub(t)
+	stub.set("app/stripe", "secret_key", "sk_test_1")
+
+	v, err := secrets.NewVault(secrets.VaultOptions{Addr: srv.URL, Token: "root"})
+	require.NoError(t, err)
+
+	got, err := v.Secret(ctx, "app/stripe", "secret_key")
+	require.NoError(t, err)
+	assert.Equal(t, "sk_test_1", got)
+
+	_, err = v.Secret(ctx, "app/stripe", "missing")
+	assert.ErrorContains(t, err, `no string key "missing"`)
+	_, err = v.Secret(ctx, "app/other", "secret_key")
+	assert.ErrorContains(t, err, "status 404")
+
+	denied, err := secrets.NewVault(secrets.VaultOptions{Addr: srv.URL, Token: "wrong"})
+	require.NoError(t, err)
+	_, err = denied.Secret(ctx, "app/stripe", "secret_key")
+	assert.ErrorContains(t, err, "status 403")
+}
+
+func TestManager_Refresh(t *testing.T) {
+	ctx := context.Background()
+	stub, srv := newVaultStub(t)
+	stub.set("app/auth", "jwt", "v1")
+	vault, err := secrets.NewVault(secrets.VaultOptions{Addr: srv.URL, Token: "root"})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "db_password")
+	require.NoError(t, os.WriteFile(path, []byte("pw1\n"), 0o600))
+
+	m := secrets.NewManager(map[string]model.SecretProvider{"vault": vault})
+	jwt, err := m.Resolve(ctx, "auth.jwt_secret", "vault:app/auth#jwt")
+	require.NoError(t, err)
+	dbPassword, err := m.Resolve(ctx, "db.password", "file:"+path)
+	require.NoError(t, err)
+	literal, err := m.Resolve(ctx, "redis.password", "p@ss:word")
+	require.NoError(t, err)
+	assert.Equal(t, "v1", jwt.Get())
+	assert.Equal(t, "pw1", dbPassword.Get())
+	assert.Equal(t, "p@ss:word", literal.Get())
+
+	stub.set("app/auth", "jwt", "v2")
+	require.NoError(t, os.WriteFile(path, []byte("pw2\n"), 0o600))
+	require.NoError(t, m.Refresh(ctx))
+	assert.Equal(t, "v2", jwt.Get())
+	assert.Equal(t, "pw2", dbPassword.Get())
+
+	// A failed read keeps the last good value.
+	require.NoError(t, os.Remove(path))
+	assert.ErrorContains(t, m.Refresh(ctx), "secret db.password")
+	assert.Equal(t, "pw2", dbPassword.Get())
+
+	_, err = m.Resolve(ctx, "stripe.secret_key", "vault:app/stripe")
+	assert.ErrorContains(t, err, "want vault:<path>#<key>")
+}
+
+func TestConfig_ResolveSecrets(t *testing.T) {
+	ctx := context.Background()
+	stub, srv := newVaultStub(t)
+	stub.set("app/stripe", "secret_key", "sk_test_1")
+	dir := t.TempDir()
+	jwtFile := filepath.Join(dir, "jwt")
+	tokenFile := filepath.Join(dir, "vault_token")
+	require.NoError(t, os.WriteFile(jwtFile, []byte("from-file\n"), 0o600))
+	require.NoError(t, os.WriteFile(tokenFile, []byte("root"), 0o600))
+
+	t.Setenv("DB_USER", "u")
+	t.Setenv("DB_HOST", "h")
+	t.Setenv("DB_NAME", "n")
+	t.Setenv("JWT_SECRET_FILE", jwtFile)
+	t.Setenv("STRIPE_SECRET_KEY", "vault:app/stripe#secret_key")
+	t.Setenv("VAULT_ADDR", srv.URL)
+	t.Setenv("VAULT_TOKEN_FILE", tokenFile)
+
+	cfg, err := config.Load(flag.NewFlagSet("test", flag.ContinueOnError), nil, "db", "auth")
+	require.NoError(t, err)
+	_, err = cfg.ResolveSecrets(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, "from-file", cfg.Auth.JWTSecret)
+	assert.Equal(t, "sk_test_1", cfg.Stripe.SecretKey)
+	assert.Equal(t, "sk_test_1", cfg.Secret("stripe.secret_key").Get())
+}
+
+func TestConfig_SecretFileConflicts(t *testing.T) {
+	t.Setenv("JWT_SECRET", "a")
+	t.Setenv("JWT_SECRET_FILE", "/run/secrets/jwt")
+	t.Setenv("STRIPE_SECRET_KEY", "vault:app/stripe#secret_key")
+
+	_, err := config.Load(flag.NewFlagSet("test", flag.ContinueOnError), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "set either $JWT_SECRET or $JWT_SECRET_FILE, not both")
+	assert.Contains(t, err.Error(), "stripe.secret_key refers to Vault but secrets.vault.addr is not set")
+}
@@ -0,0 +1,83 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"go-gin-project/internal/app/service"
+	"go-gin-project/internal/pkg/repository"
+	"go-gin-project/test/mocks"
+	"go-gin-project/test/testdb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// confirmationToken picks the token out of the confirmation email sent to.
+func confirmationToken(t *testing.T, m *recordingMailer, to string) string {
+	t.Helper()
+	parts := strings.Split(m.bodies[to], "\n\n")
+	require.Len(t, parts, 3, "confirmation email to %s", to)
+	return parts[1]
+}
+
+func TestAccountService_EmailChange(t *testing.T) {
+	ctx := context.Background()
+	db := testdb.Open(t)
+	userRepo := repository.NewUserRepository(db)
+	mockCache := new(mocks.MockCache)
+	mailer := &recordingMailer{bodies: map[string]string{}}
+	accounts := service.NewAccountService(userRepo, repository.NewEmailChangeRepository(db), mockCache, mailer)
+
+	user := seedUser(t, db, 0, "Ann", "ann@example.com", "password123")
+	seedUser(t, db, 0, "Bob", "bob@example.com", "password123")
+	mockCache.On("InvalidateTag", fmt.Sprintf("user:%d", user.ID)).Return(nil)
+
+	t.Run("request checks the password and the new address", func(t *testing.T) {
+		_, err := accounts.RequestEmailChange(ctx, user.ID, &service.EmailChangeRequest{NewEmail: "ann@new.example.com", Password: "wrong-password"})
+		assert.ErrorContains(t, err, "invalid password")
+
+		_, err = accounts.RequestEmailChange(ctx, user.ID, &service.EmailChangeRequest{NewEmail: "bob@example.com", Password: "password123"})
+		assert.ErrorContains(t, err, "already in use")
+	})
+
+	t.Run("confirm changes the email once and revokes tokens", func(t *testing.T) {
+		_, err := accounts.RequestEmailChange(ctx, user.ID, &service.EmailChangeRequest{NewEmail: "ann@new.example.com", Password: "password123"})
+		require.NoError(t, err)
+		assert.Contains(t, mailer.bodies["ann@example.com"], "ann@new.example.com", "the current address is warned")
+		token := confirmationToken(t, mailer, "ann@new.example.com")
+
+		updated, err := accounts.ConfirmEmailChange(ctx, &service.ConfirmEmailChangeRequest{Token: token})
+		require.NoError(t, err)
+		assert.Equal(t, "ann@new.example.com", updated.Email)
+		assert.Equal(t, uint(1), updated.TokenVersion)
+		mockCache.AssertCalled(t, "InvalidateTag", fmt.Sprintf("user:%d", user.ID))
+
+		_, err = accounts.ConfirmEmailChange(ctx, &service.ConfirmEmailChangeRequest{Token: token})
+		assert.ErrorContains(t, err, "invalid or expired token")
+	})
+
+	t.Run("expired tokens are rejected", func(t *testing.T) {
+		change, err := accounts.RequestEmailChange(ctx, user.ID, &service.EmailChangeRequest{NewEmail: "ann@late.example.com", Password: "password123"})
+		require.NoError(t, err)
+		require.NoError(t, db.Exec("UPDATE email_changes SET expires_at = ? WHERE id = ?", time.Now().Add(-time.Minute), change.ID).Error)
+
+		_, err = accounts.ConfirmEmailChange(ctx, &service.ConfirmEmailChangeRequest{Token: confirmationToken(t, mailer, "ann@late.example.com")})
+		assert.ErrorContains(t, err, "invalid or expired token")
+	})
+
+	t.Run("an address taken after the request is not claimed", func(t *testing.T) {
+		_, err := accounts.RequestEmailChange(ctx, user.ID, &service.EmailChangeRequest{NewEmail: "cat@example.com", Password: "password123"})
+		require.NoError(t, err)
+		seedUser(t, db, 0, "Cat", "cat@example.com", "password123")
+
+		_, err = accounts.ConfirmEmailChange(ctx, &service.ConfirmEmailChangeRequest{Token: confirmationToken(t, mailer, "cat@example.com")})
+		assert.ErrorContains(t, err, "already in use")
+		current, err := userRepo.FindByID(ctx, fmt.Sprint(user.ID))
+		require.NoError(t, err)
+		assert.Equal(t, "ann@new.example.com", current.Email)
+	})
+}
@@ -0,0 +1,47 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"go-gin-project/internal/app/service"
+	"go-gin-project/internal/pkg/model"
+	"go-gin-project/internal/pkg/repository"
+	"go-gin-project/test/testdb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthService_ValidateClaimsUsesCurrentMembership(t *testing.T) {
+	ctx := context.Background()
+	db := testdb.Open(t)
+	orgRepo := repository.NewOrganizationRepository(db)
+	authService := service.NewAuthService(repository.NewUserRepository(db), orgRepo, func() string { return "secret" })
+
+	owner := seedUser(t, db, 0, "Owner", "owner@example.com", "password123")
+	org, err := orgRepo.Create(ctx, &model.Organization{Name: "Acme"}, owner.ID)
+	require.NoError(t, err)
+	admin := seedUser(t, db, org.ID, "Admin", "admin@example.com", "password123")
+	_, err = orgRepo.UpdateMemberRole(ctx, org.ID, admin.ID, model.RoleAdmin)
+	require.NoError(t, err)
+
+	claims := &service.Claims{UserID: admin.ID, OrgID: org.ID, Role: model.RoleAdmin}
+	require.NoError(t, authService.ValidateClaims(ctx, claims))
+	assert.Equal(t, model.RoleAdmin, claims.Role)
+
+	// A demotion applies to a token issued while the user was an admin
+	_, err = orgRepo.UpdateMemberRole(ctx, org.ID, admin.ID, model.RoleMember)
+	require.NoError(t, err)
+	claims = &service.Claims{UserID: admin.ID, OrgID: org.ID, Role: model.RoleAdmin}
+	require.NoError(t, authService.ValidateClaims(ctx, claims))
+	assert.Equal(t, model.RoleMember, claims.Role)
+
+	// So does a removal
+	require.NoError(t, orgRepo.RemoveMember(ctx, org.ID, admin.ID))
+	err = authService.ValidateClaims(ctx, &service.Claims{UserID: admin.ID, OrgID: org.ID, Role: model.RoleAdmin})
+	assert.ErrorContains(t, err, "no longer a member")
+
+	// Tokens without an organization need no membership
+	assert.NoError(t, authService.ValidateClaims(ctx, &service.Claims{UserID: admin.ID}))
+}
@@ -0,0 +1,83 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go-gin-project/internal/app/service"
+	"go-gin-project/internal/pkg/blobstore"
+	"go-gin-project/internal/pkg/model"
+	"go-gin-project/internal/pkg/repository"
+	"go-gin-project/test/mocks"
+	"go-gin-project/test/testdb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pngImage(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))))
+	return buf.Bytes()
+}
+
+func TestAvatarService_Upload(t *testing.T) {
+	ctx := context.Background()
+	db := testdb.Open(t)
+	dir := t.TempDir()
+	store, err := blobstore.NewLocal(dir, "http://localhost/blobs", []byte("key"))
+	require.NoError(t, err)
+	mockCache := new(mocks.MockCache)
+	avatars := service.NewAvatarService(repository.NewUserRepository(db), store, mockCache)
+
+	user := seedUser(t, db, 7, "Ann", "ann@example.com", "password123")
+	id := fmt.Sprint(user.ID)
+	mockCache.On("InvalidateTag", "user:"+id).Return(nil)
+
+	t.Run("stores a thumbnail per size", func(t *testing.T) {
+		updated, err := avatars.ForTenant(7).Upload(ctx, id, bytes.NewReader(pngImage(t, 300, 200)))
+
+		require.NoError(t, err)
+		assert.Empty(t, updated.Password)
+		require.NotEmpty(t, updated.AvatarKey)
+		assert.Len(t, updated.AvatarURLs, len(service.AvatarSizes))
+		for size, edge := range service.AvatarSizes {
+			f, err := os.Open(filepath.Join(dir, updated.AvatarKey, size+".png"))
+			require.NoError(t, err)
+			cfg, err := png.DecodeConfig(f)
+			f.Close()
+			require.NoError(t, err)
+			assert.Equal(t, edge, cfg.Width)
+			assert.Equal(t, edge, cfg.Height)
+		}
+	})
+
+	t.Run("a new upload deletes the previous thumbnails", func(t *testing.T) {
+		first, err := avatars.Upload(ctx, id, bytes.NewReader(pngImage(t, 64, 64)))
+		require.NoError(t, err)
+		second, err := avatars.Upload(ctx, id, bytes.NewReader(pngImage(t, 64, 64)))
+		require.NoError(t, err)
+
+		assert.NoFileExists(t, filepath.Join(dir, first.AvatarKey, "small.png"))
+		assert.FileExists(t, filepath.Join(dir, second.AvatarKey, "small.png"))
+	})
+
+	t.Run("rejected uploads", func(t *testing.T) {
+		_, err := avatars.Upload(ctx, id, strings.NewReader("not an image"))
+		assert.ErrorContains(t, err, "unsupported content type")
+
+		_, err = avatars.Upload(ctx, id, bytes.NewReader(make([]byte, service.MaxAvatarBytes+1)))
+		assert.ErrorIs(t, err, service.ErrAvatarTooLarge)
+
+		_, err = avatars.ForTenant(8).Upload(ctx, id, bytes.NewReader(pngImage(t, 64, 64)))
+		assert.ErrorIs(t, err, model.ErrNotFound)
+	})
+}
@@ -0,0 +1,136 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go-gin-project/internal/app/service"
+	"go-gin-project/internal/pkg/model"
+	"go-gin-project/internal/pkg/repository"
+	"go-gin-project/test/testdb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportService_DryRun(t *testing.T) {
+	db := testdb.Open(t)
+	userRepo := repository.NewUserRepository(db)
+	importService := service.NewImportService(userRepo)
+
+	t.Run("csv rows are validated without inserting", func(t *testing.T) {
+		input := strings.Join([]string{
+			"name,email,password",
+			"Alice,alice@example.com,password123",
+			"Bob,not-an-email,password123",
+			"Alice Again,alice@example.com,password123",
+			",carol@example.com,short",
+		}, "\n")
+
+		report, err := importService.Import(context.Background(), strings.NewReader(input), service.ImportOptions{
+			Format: service.ImportFormatCSV,
+			DryRun: true,
+		})
+
+		assert.NoError(t, err)
+		assert.True(t, report.DryRun)
+		assert.Equal(t, 4, report.Total)
+		assert.Equal(t, 1, report.Succeeded)
+		assert.Equal(t, 3, report.Failed)
+
+		assert.Equal(t, service.ImportStatusValid, report.Results[0].Status)
+		assert.Equal(t, 2, report.Results[0].Line)
+		assert.Contains(t, report.Results[1].Error, "email is invalid")
+		assert.Contains(t, report.Results[2].Error, "duplicate email")
+		assert.Contains(t, report.Results[3].Error, "name is required")
+		assert.Contains(t, report.Results[3].Error, "password must be at least 8 characters")
+
+		// Nothing is written on a dry run
+		_, err = userRepo.FindByEmail(context.Background(), "alice@example.com")
+		require.ErrorIs(t, err, model.ErrNotFound)
+	})
+
+	t.Run("registered emails are reported in every batch", func(t *testing.T) {
+		seedUser(t, db, 0, "Erin", "erin@example.com", "password123")
+		seedUser(t, db, 0, "Gus", "gus@example.com", "password123")
+		input := strings.Join([]string{
+			"name,email,password",
+			"Erin,erin@example.com,password123",
+			"Fay,fay@example.com,password123",
+			"Gus,gus@example.com,password123",
+		}, "\n")
+
+		report, err := importService.Import(context.Background(), strings.NewReader(input), service.ImportOptions{
+			Format:    service.ImportFormatCSV,
+			DryRun:    true,
+			BatchSize: 2,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Succeeded)
+		assert.Equal(t, "user already exists", report.Results[0].Error)
+		assert.Equal(t, service.ImportStatusValid, report.Results[1].Status)
+		assert.Equal(t, "user already exists", report.Results[2].Error)
+	})
+
+	t.Run("malformed jsonl lines are reported", func(t *testing.T) {
+		input := "{\"name\":\"Dan\",\"email\":\"dan@example.com\",\"password\":\"password123\"}\n{not json}\n"
+
+		report, err := importService.Import(context.Background(), strings.NewReader(input), service.ImportOptions{
+			Format: service.ImportFormatJSONL,
+			DryRun: true,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, report.Total)
+		assert.Equal(t, 1, report.Failed)
+		assert.Equal(t, 2, report.Results[1].Line)
+		assert.Equal(t, service.ImportStatusFailed, report.Results[1].Status)
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		_, err := importService.Import(context.Background(), strings.NewReader(""), service.ImportOptions{Format: "xml"})
+		assert.Error(t, err)
+	})
+}
+
+// staleEmailRepo misses every email lookup, like a replica that has not yet
+// seen the users it is asked about.
+type staleEmailRepo struct {
+	model.UserRepository
+}
+
+func (r staleEmailRepo) WithTenant(orgID uint) model.UserRepository {
+	return staleEmailRepo{r.UserRepository.WithTenant(orgID)}
+}
+
+func (staleEmailRepo) ExistingEmails(context.Context, []string) ([]string, error) {
+	return nil, nil
+}
+
+func TestImportService_DuplicateAtInsert(t *testing.T) {
+	ctx := context.Background()
+	db := testdb.Open(t)
+	userRepo := repository.NewUserRepository(db)
+	seedUser(t, db, 0, "Bob", "bob@example.com", "password123")
+	importService := service.NewImportService(staleEmailRepo{userRepo})
+
+	input := strings.Join([]string{
+		"name,email,password",
+		"Alice,alice@example.com,password123",
+		"Bob,bob@example.com,password123",
+		"Carol,carol@example.com,password123",
+	}, "\n")
+	report, err := importService.Import(ctx, strings.NewReader(input), service.ImportOptions{Format: service.ImportFormatCSV})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Succeeded)
+	require.Equal(t, 1, report.Failed)
+	assert.Equal(t, service.ImportStatusCreated, report.Results[0].Status)
+	assert.Equal(t, service.ImportStatusFailed, report.Results[1].Status)
+	assert.Equal(t, "user already exists", report.Results[1].Error)
+	assert.Equal(t, service.ImportStatusCreated, report.Results[2].Status)
+	_, err = userRepo.FindByEmail(ctx, "carol@example.com")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,132 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go-gin-project/internal/app/service"
+	"go-gin-project/internal/pkg/model"
+	"go-gin-project/internal/pkg/repository"
+	"go-gin-project/test/testdb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingMailer keeps the last message sent to each address.
+type recordingMailer struct {
+	bodies map[string]string
+}
+
+func (m *recordingMailer) Send(to, subject, body string) error {
+	m.bodies[to] = body
+	return nil
+}
+
+// failingMailer refuses every message.
+type failingMailer struct{}
+
+func (failingMailer) Send(to, subject, body string) error {
+	return errors.New("smtp unavailable")
+}
+
+// invitationToken picks the token out of the invitation email sent to.
+func invitationToken(t *testing.T, m *recordingMailer, to string) string {
+	t.Helper()
+	parts := strings.Split(m.bodies[to], "\n\n")
+	require.Len(t, parts, 4, "invitation email to %s", to)
+	return parts[2]
+}
+
+func TestInvitationService(t *testing.T) {
+	ctx := context.Background()
+	db := testdb.Open(t)
+	orgRepo := repository.NewOrganizationRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	mailer := &recordingMailer{bodies: map[string]string{}}
+	invitations := service.NewInvitationService(repository.NewInvitationRepository(db), orgRepo, userRepo, mailer)
+
+	owner := seedUser(t, db, 0, "Owner", "owner@example.com", "password123")
+	org, err := orgRepo.Create(ctx, &model.Organization{Name: "Acme"}, owner.ID)
+	require.NoError(t, err)
+	member := seedUser(t, db, org.ID, "Member", "member@example.com", "password123")
+
+	t.Run("create is for admins with a valid email", func(t *testing.T) {
+		_, err := invitations.Create(ctx, org.ID, member.ID, "new@example.com", model.RoleMember)
+		assert.ErrorIs(t, err, service.ErrForbidden)
+
+		_, err = invitations.Create(ctx, org.ID, owner.ID, "not an email", model.RoleMember)
+		assert.ErrorContains(t, err, "email is invalid")
+	})
+
+	t.Run("a new user accepts once", func(t *testing.T) {
+		_, err := invitations.Create(ctx, org.ID, owner.ID, "new@example.com", model.RoleAdmin)
+		require.NoError(t, err)
+		token := invitationToken(t, mailer, "new@example.com")
+
+		user, err := invitations.Accept(ctx, &service.AcceptInvitationRequest{Token: token, Name: "New", Password: "password123"})
+		require.NoError(t, err)
+		assert.Equal(t, "new@example.com", user.Email)
+		assert.Empty(t, user.Password)
+		membership, err := orgRepo.FindMembership(ctx, org.ID, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, model.RoleAdmin, membership.Role)
+
+		_, err = invitations.Accept(ctx, &service.AcceptInvitationRequest{Token: token, Name: "Again", Password: "password123"})
+		assert.ErrorContains(t, err, "invalid or expired invitation")
+	})
+
+	t.Run("an existing user joins with their own account", func(t *testing.T) {
+		existing := seedUser(t, db, 0, "Existing", "existing@example.com", "password123")
+		_, err := invitations.Create(ctx, org.ID, owner.ID, "existing@example.com", model.RoleMember)
+		require.NoError(t, err)
+		token := invitationToken(t, mailer, "existing@example.com")
+
+		_, err = invitations.Accept(ctx, &service.AcceptInvitationRequest{Token: token, Name: "Takeover", Password: "password123"})
+		assert.ErrorContains(t, err, "sign in and accept")
+		_, err = invitations.Join(ctx, member.ID, token)
+		assert.ErrorContains(t, err, "sent to another email address")
+
+		membership, err := invitations.Join(ctx, existing.ID, token)
+		require.NoError(t, err)
+		assert.Equal(t, org.ID, membership.OrgID)
+		assert.Equal(t, model.RoleMember, membership.Role)
+
+		_, err = invitations.Join(ctx, existing.ID, token)
+		assert.ErrorContains(t, err, "invalid or expired invitation")
+	})
+
+	t.Run("members cannot join again", func(t *testing.T) {
+		_, err := invitations.Create(ctx, org.ID, owner.ID, "member@example.com", model.RoleAdmin)
+		require.NoError(t, err)
+
+		_, err = invitations.Join(ctx, member.ID, invitationToken(t, mailer, "member@example.com"))
+		assert.ErrorIs(t, err, model.ErrDuplicate)
+	})
+
+	t.Run("expired invitations cannot be accepted", func(t *testing.T) {
+		inv, err := invitations.Create(ctx, org.ID, owner.ID, "late@example.com", model.RoleMember)
+		require.NoError(t, err)
+		require.NoError(t, db.Exec("UPDATE invitations SET expires_at = ? WHERE id = ?", time.Now().Add(-time.Minute), inv.ID).Error)
+
+		_, err = invitations.Accept(ctx, &service.AcceptInvitationRequest{Token: invitationToken(t, mailer, "late@example.com"), Name: "Late", Password: "password123"})
+		assert.ErrorContains(t, err, "invalid or expired invitation")
+		_, err = userRepo.FindByEmail(ctx, "late@example.com")
+		assert.ErrorIs(t, err, model.ErrNotFound)
+	})
+
+	t.Run("an invitation whose email fails is not kept", func(t *testing.T) {
+		unsent := service.NewInvitationService(repository.NewInvitationRepository(db), orgRepo, userRepo, failingMailer{})
+		_, err := unsent.Create(ctx, org.ID, owner.ID, "unsent@example.com", model.RoleMember)
+		assert.ErrorContains(t, err, "smtp unavailable")
+
+		pending, err := invitations.List(ctx, org.ID, owner.ID)
+		require.NoError(t, err)
+		for _, inv := range pending {
+			assert.NotEqual(t, "unsent@example.com", inv.Email)
+		}
+	})
+}
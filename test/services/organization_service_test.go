@@ -0,0 +1,43 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"go-gin-project/internal/app/service"
+	"go-gin-project/internal/pkg/cache"
+	"go-gin-project/internal/pkg/model"
+	"go-gin-project/internal/pkg/repository"
+	"go-gin-project/test/testdb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrganizationService_KeepsAnOwner(t *testing.T) {
+	ctx := context.Background()
+	db := testdb.Open(t)
+	orgRepo := repository.NewOrganizationRepository(db)
+	orgs := service.NewOrganizationService(orgRepo, cache.NewMemory(10, 0))
+
+	owner := seedUser(t, db, 0, "Owner", "owner@example.com", "password123")
+	org, err := orgs.Create(ctx, "Acme", owner.ID)
+	require.NoError(t, err)
+	member := seedUser(t, db, org.ID, "Member", "member@example.com", "password123")
+
+	_, err = orgs.UpdateMemberRole(ctx, org.ID, owner.ID, owner.ID, model.RoleAdmin)
+	assert.ErrorIs(t, err, model.ErrLastOwner)
+	err = orgs.RemoveMember(ctx, org.ID, owner.ID, owner.ID)
+	assert.ErrorIs(t, err, model.ErrLastOwner)
+	membership, err := orgRepo.FindMembership(ctx, org.ID, owner.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.RoleOwner, membership.Role)
+
+	// With a second owner, either may step down.
+	_, err = orgs.UpdateMemberRole(ctx, org.ID, owner.ID, member.ID, model.RoleOwner)
+	require.NoError(t, err)
+	require.NoError(t, orgs.RemoveMember(ctx, org.ID, owner.ID, owner.ID))
+
+	_, err = orgs.UpdateMemberRole(ctx, org.ID, member.ID, member.ID, model.RoleMember)
+	assert.ErrorIs(t, err, model.ErrLastOwner)
+}
@@ -0,0 +1,122 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go-gin-project/internal/app/service"
+	"go-gin-project/internal/pkg/broker"
+	"go-gin-project/internal/pkg/cache"
+	"go-gin-project/internal/pkg/model"
+	"go-gin-project/internal/pkg/repository"
+	"go-gin-project/test/testdb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyBroker fails to publish events for which fail returns true.
+type flakyBroker struct {
+	*broker.Memory
+	fail func(e *model.Event) bool
+}
+
+func (b *flakyBroker) Publish(ctx context.Context, e *model.Event) error {
+	if b.fail(e) {
+		return errors.New("broker unavailable")
+	}
+	return b.Memory.Publish(ctx, e)
+}
+
+// drain relays batches until no event is due.
+func drain(t *testing.T, relay *service.OutboxRelay) {
+	t.Helper()
+	for {
+		n, err := relay.RelayBatch(context.Background())
+		require.NoError(t, err)
+		if n == 0 {
+			return
+		}
+	}
+}
+
+func eventTypes(events []model.Event, aggregateType string) []string {
+	var types []string
+	for _, e := range events {
+		if e.AggregateType == aggregateType {
+			types = append(types, e.Type)
+		}
+	}
+	return types
+}
+
+func TestOutboxRelay(t *testing.T) {
+	ctx := context.Background()
+	db := testdb.Open(t)
+	users := repository.NewUserRepository(db).WithTenant(7)
+	payments := repository.NewPaymentRepository(db)
+	outbox := repository.NewOutboxRepository(db)
+
+	user, err := users.Create(ctx, &model.User{Name: "Ann", Email: "ann@example.com", Password: "password123"})
+	require.NoError(t, err)
+	_, err = payments.Create(ctx, &model.Payment{UserID: user.ID, Amount: 20, Currency: "usd", StripeID: "pi_1", PaymentStatus: "requires_payment_method"})
+	require.NoError(t, err)
+	_, err = payments.UpdateStatus(ctx, &model.Payment{StripeID: "pi_1", PaymentStatus: "succeeded"})
+	require.NoError(t, err)
+	_, err = payments.UpdateStatus(ctx, &model.Payment{StripeID: "pi_1", PaymentStatus: "succeeded"})
+	require.NoError(t, err, "an unchanged status records no event")
+
+	t.Run("events are published in order per aggregate", func(t *testing.T) {
+		published := broker.NewMemory()
+		drain(t, service.NewOutboxRelay(outbox, published, cache.NewMemoryLocker(), service.OutboxRelayOptions{}))
+
+		events := published.Events()
+		assert.Equal(t, []string{model.EventUserCreated}, eventTypes(events, model.AggregateUser))
+		assert.Equal(t, []string{model.EventPaymentStatusChanged}, eventTypes(events, model.AggregatePayment))
+		assert.JSONEq(t, `{"user_id":1,"org_id":7}`, string(events[0].Payload))
+		for _, e := range events {
+			if e.Type == model.EventPaymentStatusChanged {
+				assert.Equal(t, "1", e.AggregateID)
+				assert.JSONEq(t, `{"payment_id":1,"stripe_id":"pi_1","user_id":1,"from":"requires_payment_method","to":"succeeded"}`, string(e.Payload))
+			}
+		}
+
+		stats, err := outbox.Stats(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, model.OutboxStats{}, stats, "published events leave the outbox")
+	})
+
+	t.Run("failed events are retried, then dead-lettered", func(t *testing.T) {
+		_, err := payments.UpdateStatus(ctx, &model.Payment{StripeID: "pi_1", PaymentStatus: "disputed"})
+		require.NoError(t, err)
+		_, err = payments.UpdateStatus(ctx, &model.Payment{StripeID: "pi_1", PaymentStatus: "canceled"})
+		require.NoError(t, err)
+
+		published := broker.NewMemory()
+		b := &flakyBroker{Memory: published, fail: func(e *model.Event) bool { return strings.Contains(string(e.Payload), `"to":"disputed"`) }}
+		relay := service.NewOutboxRelay(outbox, b, cache.NewMemoryLocker(), service.OutboxRelayOptions{
+			MaxAttempts: 2, RetryBackoff: 50 * time.Millisecond,
+		})
+
+		drain(t, relay)
+		assert.Empty(t, published.Events(), "the second status change waits for the first")
+
+		time.Sleep(60 * time.Millisecond)
+		drain(t, relay)
+		dead, err := outbox.DeadLetters(ctx, 10)
+		require.NoError(t, err)
+		require.Len(t, dead, 1)
+		assert.Contains(t, string(dead[0].Payload), `"to":"disputed"`)
+		assert.Equal(t, 2, dead[0].Attempts)
+		assert.Equal(t, "broker unavailable", dead[0].LastError)
+
+		require.Len(t, published.Events(), 1, "later events go ahead once the failing one is dead-lettered")
+		assert.Contains(t, string(published.Events()[0].Payload), `"to":"canceled"`)
+		stats, err := outbox.Stats(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, model.OutboxStats{Pending: 0, Dead: 1}, stats)
+	})
+}
@@ -0,0 +1,50 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"go-gin-project/internal/app/service"
+	"go-gin-project/internal/pkg/repository"
+	"go-gin-project/test/testdb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreferenceService(t *testing.T) {
+	ctx := context.Background()
+	db := testdb.Open(t)
+	prefs := service.NewPreferenceService(repository.NewPreferenceRepository(db))
+	user := seedUser(t, db, 0, "Ann", "ann@example.com", "password123")
+
+	t.Run("unset keys take their defaults", func(t *testing.T) {
+		got, err := prefs.Get(ctx, user.ID)
+
+		require.NoError(t, err)
+		assert.Equal(t, "system", got["theme"])
+		assert.Equal(t, "usd", got["currency"])
+	})
+
+	t.Run("update stores valid values and returns the full set", func(t *testing.T) {
+		got, err := prefs.Update(ctx, user.ID, map[string]string{"theme": "dark"})
+		require.NoError(t, err)
+		assert.Equal(t, "dark", got["theme"])
+		assert.Equal(t, "true", got["email_notifications"])
+
+		got, err = prefs.Update(ctx, user.ID, map[string]string{"theme": "light", "currency": "eur"})
+		require.NoError(t, err)
+		assert.Equal(t, "light", got["theme"], "an existing key is overwritten")
+		assert.Equal(t, "eur", got["currency"])
+	})
+
+	t.Run("update rejects unknown keys and values, storing nothing", func(t *testing.T) {
+		_, err := prefs.Update(ctx, user.ID, map[string]string{"theme": "neon", "font": "serif", "currency": "gbp"})
+
+		assert.ErrorContains(t, err, `unknown preference "font"`)
+		assert.ErrorContains(t, err, "theme must be one of light, dark, system")
+		got, err := prefs.Get(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "eur", got["currency"])
+	})
+}
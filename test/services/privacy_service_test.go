@@ -0,0 +1,120 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"go-gin-project/internal/app/service"
+	"go-gin-project/internal/pkg/model"
+	"go-gin-project/internal/pkg/repository"
+	"go-gin-project/test/mocks"
+	"go-gin-project/test/testdb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrivacyService_ExportAndErase(t *testing.T) {
+	ctx := context.Background()
+	db := testdb.Open(t)
+	orgRepo := repository.NewOrganizationRepository(db)
+	prefRepo := repository.NewPreferenceRepository(db)
+	emailChangeRepo := repository.NewEmailChangeRepository(db)
+	invitationRepo := repository.NewInvitationRepository(db)
+	paymentRepo := repository.NewPaymentRepository(db)
+	mockCache := new(mocks.MockCache)
+	privacy := service.NewPrivacyService(repository.NewUserRepository(db), paymentRepo,
+		repository.NewPrivacyRepository(db), prefRepo, nil, mockCache)
+
+	owner := seedUser(t, db, 0, "Owner", "owner@example.com", "password123")
+	org, err := orgRepo.Create(ctx, &model.Organization{Name: "Acme"}, owner.ID)
+	require.NoError(t, err)
+	user := seedUser(t, db, org.ID, "Ann", "ann@example.com", "password123")
+	id := fmt.Sprint(user.ID)
+
+	require.NoError(t, prefRepo.Set(ctx, user.ID, map[string]string{"theme": "dark"}))
+	_, err = emailChangeRepo.Create(ctx, &model.EmailChange{UserID: user.ID, NewEmail: "ann@new.example.com", TokenHash: "change", ExpiresAt: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	_, err = invitationRepo.Create(ctx, &model.Invitation{OrgID: org.ID, Email: user.Email, Role: model.RoleAdmin, TokenHash: "invite", InvitedBy: owner.ID, ExpiresAt: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	_, err = paymentRepo.WithTenant(org.ID).Create(ctx, &model.Payment{UserID: user.ID, Amount: 10, Currency: "usd", StripeID: "pi_1", PaymentStatus: "succeeded"})
+	require.NoError(t, err)
+
+	t.Run("export", func(t *testing.T) {
+		export, err := privacy.ForTenant(org.ID).Export(ctx, id)
+
+		require.NoError(t, err)
+		assert.Equal(t, "ann@example.com", export.User.Email)
+		assert.Empty(t, export.User.Password)
+		assert.Equal(t, "dark", export.Preferences["theme"])
+		assert.Len(t, export.Payments, 1)
+		require.Len(t, export.Sessions.Memberships, 1)
+		assert.Equal(t, org.ID, export.Sessions.Memberships[0].OrgID)
+		var actions []string
+		for _, e := range export.AuditLog {
+			actions = append(actions, e.Action)
+		}
+		assert.ElementsMatch(t, []string{"membership.created", "invitation.received", "email_change.requested"}, actions)
+	})
+
+	t.Run("other tenants cannot export the user", func(t *testing.T) {
+		_, err := privacy.ForTenant(org.ID+1).Export(ctx, id)
+
+		assert.ErrorIs(t, err, model.ErrNotFound)
+	})
+
+	t.Run("erase", func(t *testing.T) {
+		mockCache.On("InvalidateTag", "user:"+id).Return(nil)
+
+		receipt, err := privacy.ForTenant(org.ID).Erase(ctx, id, owner.ID)
+
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, receipt.UserID)
+		assert.Equal(t, owner.ID, receipt.RequestedBy)
+
+		prefs, err := prefRepo.List(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Empty(t, prefs)
+		_, err = emailChangeRepo.FindByTokenHash(ctx, "change")
+		assert.ErrorIs(t, err, model.ErrNotFound)
+		_, err = invitationRepo.FindByTokenHash(ctx, "invite")
+		assert.ErrorIs(t, err, model.ErrNotFound)
+		payment, err := paymentRepo.FindByStripeID(ctx, "pi_1")
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, payment.UserID)
+
+		_, err = privacy.ForTenant(org.ID).Export(ctx, id)
+		assert.ErrorIs(t, err, model.ErrNotFound)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("users of several organizations", func(t *testing.T) {
+		other, err := orgRepo.Create(ctx, &model.Organization{Name: "Globex"}, owner.ID)
+		require.NoError(t, err)
+		bob := seedUser(t, db, org.ID, "Bob", "bob@example.com", "password123")
+		bobID := fmt.Sprint(bob.ID)
+		require.NoError(t, db.Exec("INSERT INTO memberships (org_id, user_id, role, created_at) VALUES (?, ?, ?, ?)",
+			other.ID, bob.ID, model.RoleMember, time.Now()).Error)
+		_, err = paymentRepo.WithTenant(org.ID).Create(ctx, &model.Payment{UserID: bob.ID, Amount: 1, Currency: "usd", StripeID: "pi_acme", PaymentStatus: "succeeded"})
+		require.NoError(t, err)
+		_, err = paymentRepo.WithTenant(other.ID).Create(ctx, &model.Payment{UserID: bob.ID, Amount: 2, Currency: "usd", StripeID: "pi_globex", PaymentStatus: "succeeded"})
+		require.NoError(t, err)
+
+		scoped, err := privacy.ForTenant(org.ID).Export(ctx, bobID)
+		require.NoError(t, err)
+		assert.Len(t, scoped.Payments, 1, "an admin only sees their organization's payments")
+		own, err := privacy.Export(ctx, bobID)
+		require.NoError(t, err)
+		assert.Len(t, own.Payments, 2, "the user's own export covers every organization")
+
+		_, err = privacy.ForTenant(org.ID).Erase(ctx, bobID, owner.ID)
+		assert.ErrorIs(t, err, service.ErrForbidden)
+
+		mockCache.On("InvalidateTag", "user:"+bobID).Return(nil)
+		receipt, err := privacy.Erase(ctx, bobID, bob.ID)
+		require.NoError(t, err)
+		assert.Equal(t, bob.ID, receipt.RequestedBy)
+	})
+}
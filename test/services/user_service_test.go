@@ -1,9 +1,8 @@
 package service_test
 
 import (
+	"context"
 	"database/sql"
-	"fmt"
-	"regexp"
 	"testing"
 	"time"
 
@@ -11,70 +10,44 @@ import (
 	"go-gin-project/internal/pkg/model"
 	"go-gin-project/internal/pkg/repository"
 	"go-gin-project/test/mocks"
+	"go-gin-project/test/testdb"
 
-	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
-	"gorm.io/driver/mysql"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
-func setupTestDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock, error) {
-	// Create new mock database
-	sqlDB, sqlMockObj, err := sqlmock.New()
-	if err != nil {
-		return nil, nil, err
+// seedUser inserts a user through the repository, in orgID's tenant if set.
+func seedUser(t *testing.T, db *gorm.DB, orgID uint, name, email, password string) *model.User {
+	t.Helper()
+	repo := repository.NewUserRepository(db)
+	if orgID != 0 {
+		repo = repo.WithTenant(orgID)
 	}
-
-	// Connect to mock database using GORM
-	dialector := mysql.New(mysql.Config{
-		Conn:                      sqlDB,
-		SkipInitializeWithVersion: true,
-	})
-
-	db, err := gorm.Open(dialector, &gorm.Config{})
-	if err != nil {
-		return nil, nil, err
-	}
-
-	return db, sqlMockObj, nil
+	user, err := repo.Create(context.Background(), &model.User{Name: name, Email: email, Password: password})
+	require.NoError(t, err)
+	return user
 }
 
 func TestUserService_Create(t *testing.T) {
-	db, sqlMock, err := setupTestDB(t)
-	assert.NoError(t, err)
-
+	db := testdb.Open(t)
 	userRepo := repository.NewUserRepository(db)
 	mockCache := new(mocks.MockCache)
-	userService := service.NewUserService(userRepo, mockCache)
-
-	t.Run("duplicate email", func(t *testing.T) {
-		user := &model.User{
-			Email: "existing@example.com",
-		}
-
-		// Expect begin transaction
-		sqlMock.ExpectBegin()
-
-		// Expect check for existing user with soft delete
-		sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE email = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT ?")).
-			WithArgs(user.Email, 1).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "email", "name"}).
-				AddRow(1, user.Email, "Existing User"))
+	userService := service.NewUserService(userRepo, mockCache, service.FixedTTL(5*time.Minute))
 
-		// Expect rollback since user exists
-		sqlMock.ExpectRollback()
+	seedUser(t, db, 0, "Existing User", "existing@example.com", "password123")
 
-		// Execute test
-		createdUser, err := userService.Create(user)
+	t.Run("duplicate email", func(t *testing.T) {
+		createdUser, err := userService.Create(context.Background(), &model.User{
+			Name:     "Someone Else",
+			Email:    "existing@example.com",
+			Password: "password123",
+		})
 
-		// Assert results
-		assert.Error(t, err)
 		assert.Nil(t, createdUser)
-		assert.Contains(t, err.Error(), "user already exists")
-
-		// Verify all expectations were met
-		assert.NoError(t, sqlMock.ExpectationsWereMet())
+		assert.ErrorContains(t, err, "user already exists")
 	})
 
 	t.Run("successful user creation", func(t *testing.T) {
@@ -84,214 +57,204 @@ func TestUserService_Create(t *testing.T) {
 			Password: "password123",
 		}
 
-		// Expect user creation
-		sqlMock.ExpectBegin()
-
-		// Expect check for existing user with soft delete
-		sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE email = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT ?")).
-			WithArgs(user.Email, 1).
-			WillReturnError(gorm.ErrRecordNotFound)
-
-		sqlMock.ExpectExec(regexp.QuoteMeta("INSERT INTO `users`")).
-			WithArgs(
-				user.Name,        // Name
-				user.Email,       // Email
-				sqlmock.AnyArg(), // Password (hashed)
-				sqlmock.AnyArg(), // CreatedAt
-				sqlmock.AnyArg(), // UpdatedAt
-				nil,              // DeletedAt
-			).WillReturnResult(sqlmock.NewResult(1, 1))
-		sqlMock.ExpectCommit()
-
-		// Execute test
-		createdUser, err := userService.Create(user)
-
-		// Assert results
-		assert.NoError(t, err)
-		assert.NotNil(t, createdUser)
+		// A cached "not found" for the new ID is dropped
+		mockCache.On("Delete", "user:2").Return(nil)
+
+		createdUser, err := userService.Create(context.Background(), user)
+
+		require.NoError(t, err)
+		assert.Equal(t, uint(2), createdUser.ID)
 		assert.Equal(t, user.Name, createdUser.Name)
 		assert.Equal(t, user.Email, createdUser.Email)
 		assert.Empty(t, createdUser.Password)
-
-		// Verify all expectations were met
-		assert.NoError(t, sqlMock.ExpectationsWereMet())
+		mockCache.AssertExpectations(t)
 	})
 }
 
 func TestUserService_Get(t *testing.T) {
-	db, sqlMock, err := setupTestDB(t)
-	assert.NoError(t, err)
-
+	db := testdb.Open(t)
 	userRepo := repository.NewUserRepository(db)
 	mockCache := new(mocks.MockCache)
-	userService := service.NewUserService(userRepo, mockCache)
-
-	t.Run("get user successfully", func(t *testing.T) {
-		userID := "1"
-		expectedUser := &model.User{
-			ID:    1,
-			Name:  "Test User",
-			Email: "test@example.com",
-		}
-
-		// Mock cache miss
-		mockCache.On("Get", "user:"+userID, mock.AnythingOfType("*model.User")).Return(sql.ErrNoRows)
+	userService := service.NewUserService(userRepo, mockCache, service.FixedTTL(5*time.Minute))
 
-		// Expect database query with exact SQL pattern
-		sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE `users`.`id` = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT ?")).
-			WithArgs("1", 1).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}).
-				AddRow(expectedUser.ID, expectedUser.Name, expectedUser.Email))
+	seeded := seedUser(t, db, 0, "Test User", "test@example.com", "password123")
+	tenantUser := seedUser(t, db, 7, "Tenant User", "tenant@example.com", "password123")
 
-		// Mock cache set
-		mockCache.On("Set", "user:"+userID, mock.AnythingOfType("*model.User"), 5*time.Minute).Return(nil)
-
-		// Execute test
-		user, err := userService.Get(userID)
-
-		fmt.Println("**************** get user ****************")
-		fmt.Println(user)
-		fmt.Println(err)
+	t.Run("get user successfully", func(t *testing.T) {
+		mockCache.On("Get", "user:1", mock.AnythingOfType("*service.cachedUser")).Return(sql.ErrNoRows)
+		mockCache.On("Set", "user:1", mock.AnythingOfType("*service.cachedUser"), 5*time.Minute, "user:1").Return(nil)
 
-		// Assert results
-		assert.NoError(t, err)
-		assert.NotNil(t, user)
-		assert.Equal(t, expectedUser.ID, user.ID)
-		assert.Equal(t, expectedUser.Name, user.Name)
+		user, err := userService.Get(context.Background(), "1")
 
-		// Verify all expectations were met
-		assert.NoError(t, sqlMock.ExpectationsWereMet())
+		require.NoError(t, err)
+		assert.Equal(t, seeded.ID, user.ID)
+		assert.Equal(t, seeded.Name, user.Name)
+		assert.Empty(t, user.Password)
 		mockCache.AssertExpectations(t)
 	})
 
 	t.Run("user not found", func(t *testing.T) {
-		userID := "999"
+		mockCache.On("Get", "user:999", mock.AnythingOfType("*service.cachedUser")).Return(sql.ErrNoRows)
+
+		user, err := userService.Get(context.Background(), "999")
 
-		// Mock cache miss
-		mockCache.On("Get", "user:"+userID, mock.AnythingOfType("*model.User")).Return(sql.ErrNoRows)
+		assert.Nil(t, user)
+		assert.ErrorContains(t, err, "record not found")
+		mockCache.AssertExpectations(t)
+	})
 
-		// Expect database query
-		sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE `users`.`id` = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT ?")).
-			WithArgs("999", 1).
-			WillReturnError(gorm.ErrRecordNotFound)
+	t.Run("tenant-scoped get uses org cache key and membership filter", func(t *testing.T) {
+		mockCache.On("Get", "org:7:user:2", mock.AnythingOfType("*service.cachedUser")).Return(sql.ErrNoRows)
+		mockCache.On("Set", "org:7:user:2", mock.AnythingOfType("*service.cachedUser"), 5*time.Minute, "user:2").Return(nil)
 
-		// Execute test
-		user, err := userService.Get(userID)
+		user, err := userService.ForTenant(7).Get(context.Background(), "2")
 
-		// Assert results
-		assert.Error(t, err)
-		assert.Nil(t, user)
-		assert.Contains(t, err.Error(), "record not found")
+		require.NoError(t, err)
+		assert.Equal(t, tenantUser.ID, user.ID)
 
-		// Verify all expectations were met
-		assert.NoError(t, sqlMock.ExpectationsWereMet())
+		// Users outside the organization are not visible in its tenant
+		mockCache.On("Get", "org:7:user:1", mock.AnythingOfType("*service.cachedUser")).Return(sql.ErrNoRows)
+		_, err = userService.ForTenant(7).Get(context.Background(), "1")
+		assert.ErrorIs(t, err, model.ErrNotFound)
 		mockCache.AssertExpectations(t)
 	})
 }
 
 func TestUserService_Update(t *testing.T) {
-	db, sqlMockObj, err := setupTestDB(t)
-	assert.NoError(t, err)
-
+	db := testdb.Open(t)
 	userRepo := repository.NewUserRepository(db)
 	mockCache := new(mocks.MockCache)
-	userService := service.NewUserService(userRepo, mockCache)
-
-	t.Run("successful update", func(t *testing.T) {
-		userID := "1"
-		updateData := &model.User{
-			Name: "Updated Name",
-		}
+	userService := service.NewUserService(userRepo, mockCache, service.FixedTTL(5*time.Minute))
 
-		// Expect update query
-		sqlMockObj.ExpectBegin()
+	seedUser(t, db, 0, "Original Name", "test@example.com", "password123")
 
-		// Expect find user query
-		sqlMockObj.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE `users`.`id` = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT ?")).
-			WithArgs("1", 1).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}).
-				AddRow(1, "Original Name", "test@example.com"))
-
-		sqlMockObj.ExpectExec(regexp.QuoteMeta("UPDATE `users`")).
-			WillReturnResult(sqlmock.NewResult(1, 1))
-		sqlMockObj.ExpectCommit()
-
-		// Mock cache delete
-		mockCache.On("Delete", "user:"+userID).Return(nil)
+	t.Run("successful update", func(t *testing.T) {
+		// Every cached entry for the user, in any tenant, is evicted by tag
+		mockCache.On("InvalidateTag", "user:1").Return(nil)
 
-		// Execute test
-		updatedUser, err := userService.Update(userID, updateData)
+		updatedUser, err := userService.Update(context.Background(), "1", &model.User{Name: "Updated Name"})
 
-		// Assert results
-		assert.NoError(t, err)
-		assert.NotNil(t, updatedUser)
-		assert.Equal(t, updateData.Name, updatedUser.Name)
+		require.NoError(t, err)
+		assert.Equal(t, "Updated Name", updatedUser.Name)
 
-		// Verify all expectations were met
-		assert.NoError(t, sqlMockObj.ExpectationsWereMet())
+		stored, err := userRepo.FindByID(context.Background(), "1")
+		require.NoError(t, err)
+		assert.Equal(t, "Updated Name", stored.Name)
+		assert.Equal(t, "test@example.com", stored.Email)
 		mockCache.AssertExpectations(t)
 	})
 }
 
 func TestUserService_Delete(t *testing.T) {
-	db, sqlMock, err := setupTestDB(t)
-	assert.NoError(t, err)
-
+	db := testdb.Open(t)
 	userRepo := repository.NewUserRepository(db)
 	mockCache := new(mocks.MockCache)
-	userService := service.NewUserService(userRepo, mockCache)
+	userService := service.NewUserService(userRepo, mockCache, service.FixedTTL(5*time.Minute))
+
+	seedUser(t, db, 0, "Test User", "test@example.com", "password123")
 
 	t.Run("successful deletion", func(t *testing.T) {
-		userID := "1"
-
-		sqlMock.ExpectBegin()
-		// Expect find user query
-		sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE `users`.`id` = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT ?")).
-			WithArgs(userID, 1).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}).
-				AddRow(userID, "Test User", "test@example.com"))
-
-		// Expect soft delete query with timestamp matching
-		sqlMock.ExpectExec(regexp.QuoteMeta("UPDATE `users` SET `deleted_at`=? WHERE `users`.`id` = ? AND `users`.`deleted_at` IS NULL")).
-			WithArgs(
-				sqlmock.AnyArg(), // deleted_at timestamp will be set by GORM
-				1,                // user ID
-			).
-			WillReturnResult(sqlmock.NewResult(1, 1))
-		sqlMock.ExpectCommit()
-
-		// Mock cache delete
-		mockCache.On("Delete", "user:"+userID).Return(nil)
-
-		// Execute test
-		err := userService.Delete(userID)
-
-		// Assert results
-		assert.NoError(t, err)
-
-		// Verify all expectations were met
-		assert.NoError(t, sqlMock.ExpectationsWereMet())
+		mockCache.On("InvalidateTag", "user:1").Return(nil)
+
+		err := userService.Delete(context.Background(), "1")
+
+		require.NoError(t, err)
+		_, err = userRepo.FindByID(context.Background(), "1")
+		assert.ErrorIs(t, err, model.ErrNotFound)
 		mockCache.AssertExpectations(t)
 	})
 
 	t.Run("user not found", func(t *testing.T) {
-		userID := "999"
-		sqlMock.ExpectBegin()
-		// Expect find user query that returns no results
-		sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE `users`.`id` = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT ?")).
-			WithArgs(userID, 1).
-			WillReturnError(gorm.ErrRecordNotFound)
+		err := userService.Delete(context.Background(), "999")
+
+		assert.ErrorContains(t, err, "record not found")
+	})
+}
+
+func TestUserService_ChangePassword(t *testing.T) {
+	db := testdb.Open(t)
+	userRepo := repository.NewUserRepository(db)
+	mockCache := new(mocks.MockCache)
+	userService := service.NewUserService(userRepo, mockCache, service.FixedTTL(5*time.Minute))
+
+	seedUser(t, db, 0, "Test User", "test@example.com", "current-password")
+
+	t.Run("wrong current password", func(t *testing.T) {
+		err := userService.ChangePassword(context.Background(), "1", &service.ChangePasswordRequest{
+			CurrentPassword: "not-the-password",
+			NewPassword:     "new-password",
+		})
+
+		assert.ErrorContains(t, err, "current password is incorrect")
+	})
+
+	t.Run("new password too short", func(t *testing.T) {
+		err := userService.ChangePassword(context.Background(), "1", &service.ChangePasswordRequest{
+			CurrentPassword: "current-password",
+			NewPassword:     "short",
+		})
+
+		assert.ErrorContains(t, err, "at least 8 characters")
+	})
 
-		sqlMock.ExpectRollback()
+	t.Run("changes the password and revokes earlier tokens", func(t *testing.T) {
+		mockCache.On("InvalidateTag", "user:1").Return(nil).Once()
 
-		// Execute test
-		err := userService.Delete(userID)
+		err := userService.ChangePassword(context.Background(), "1", &service.ChangePasswordRequest{
+			CurrentPassword: "current-password",
+			NewPassword:     "new-password",
+		})
+
+		require.NoError(t, err)
+		user, err := userRepo.FindByID(context.Background(), "1")
+		require.NoError(t, err)
+		assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("new-password")))
+		assert.Equal(t, uint(1), user.TokenVersion)
+		mockCache.AssertExpectations(t)
+	})
+}
+
+func TestUserService_ProfileValidation(t *testing.T) {
+	db := testdb.Open(t)
+	mockCache := new(mocks.MockCache)
+	mockCache.On("InvalidateTag", "user:1").Return(nil)
+	userService := service.NewUserService(repository.NewUserRepository(db), mockCache, service.FixedTTL(5*time.Minute))
+	seedUser(t, db, 0, "Test User", "test@example.com", "password123")
+	ctx := context.Background()
+	str := func(s string) *string { return &s }
+
+	tests := []struct {
+		name  string
+		patch service.UserPatch
+		err   string
+	}{
+		{"valid fields", service.UserPatch{Locale: str("pt-BR"), Timezone: str("Europe/Berlin"), Phone: str("+14155550123")}, ""},
+		{"script subtag", service.UserPatch{Locale: str("zh-Hant-TW")}, ""},
+		{"empty values clear fields", service.UserPatch{Locale: str(""), Timezone: str(""), Phone: str("")}, ""},
+		{"bad locale", service.UserPatch{Locale: str("english")}, "locale must be a BCP 47 tag"},
+		{"unknown timezone", service.UserPatch{Timezone: str("Mars/Olympus")}, "timezone must be an IANA zone"},
+		{"local timezone", service.UserPatch{Timezone: str("Local")}, "timezone must be an IANA zone"},
+		{"phone without country code", service.UserPatch{Phone: str("4155550123")}, "phone must be in E.164 format"},
+		{"empty name", service.UserPatch{Name: str("")}, "name cannot be empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, err := userService.Patch(ctx, "1", &tt.patch)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			if tt.patch.Timezone != nil {
+				assert.Equal(t, *tt.patch.Timezone, user.Timezone)
+			}
+		})
+	}
 
-		// Assert results
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "record not found")
+	t.Run("every problem is reported", func(t *testing.T) {
+		_, err := userService.Patch(ctx, "1", &service.UserPatch{Locale: str("x"), Phone: str("123")})
 
-		// Verify all expectations were met
-		assert.NoError(t, sqlMock.ExpectationsWereMet())
+		assert.ErrorContains(t, err, "locale")
+		assert.ErrorContains(t, err, "phone")
 	})
 }
@@ -0,0 +1,29 @@
+// Package testdb opens throwaway SQLite databases with the real schema for
+// repository and service tests.
+package testdb
+
+import (
+	"flag"
+	"path/filepath"
+	"testing"
+
+	"go-gin-project/config"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// Open returns a database in a temporary file, opened (and so migrated up)
+// like the app opens it with DB_DRIVER=sqlite. It is closed when t ends.
+func Open(t testing.TB) *gorm.DB {
+	t.Helper()
+	cfg, err := config.Load(flag.NewFlagSet("testdb", flag.ContinueOnError), []string{
+		"-db-driver", "sqlite",
+		"-db-name", filepath.Join(t.TempDir(), "test.db"),
+	}, "db")
+	require.NoError(t, err)
+	db, err := cfg.OpenDB()
+	require.NoError(t, err)
+	t.Cleanup(func() { config.CloseDB(db) }) //nolint:errcheck
+	return db
+}